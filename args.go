@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// expandArgFiles replaces every "@file" argument with the flags and targets
+// listed in that file, so long generated invocations can be committed and rerun
+func expandArgFiles(args []string) ([]string, error) {
+	var expanded []string
+	for _, arg := range args {
+		if len(arg) < 2 || !strings.HasPrefix(arg, "@") {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		content, err := os.ReadFile(arg[1:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read argument file: %w", err)
+		}
+
+		fileArgs, err := splitArgs(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse argument file %s: %w", arg[1:], err)
+		}
+		expanded = append(expanded, fileArgs...)
+	}
+	return expanded, nil
+}
+
+// splitArgs tokenizes argument file content: whitespace separates arguments,
+// single or double quotes group them, and lines starting with # are comments
+func splitArgs(content string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inToken := false
+	var quote rune
+
+	for _, line := range strings.Split(content, "\n") {
+		if quote == 0 && strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		for _, r := range line {
+			switch {
+			case quote != 0:
+				if r == quote {
+					quote = 0
+				} else {
+					current.WriteRune(r)
+				}
+			case r == '"' || r == '\'':
+				quote = r
+				inToken = true
+			case r == ' ' || r == '\t' || r == '\r':
+				if inToken {
+					args = append(args, current.String())
+					current.Reset()
+					inToken = false
+				}
+			default:
+				current.WriteRune(r)
+				inToken = true
+			}
+		}
+
+		if quote != 0 {
+			current.WriteRune('\n')
+		} else if inToken {
+			args = append(args, current.String())
+			current.Reset()
+			inToken = false
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	return args, nil
+}
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// Baseline is the findings of a previous run (-baseline). Secrets are kept
+// as hashes so the file can be committed next to a CI configuration.
+type Baseline struct {
+	Version   int                 `json:"version"`
+	Updated   string              `json:"updated"`
+	JSDumper  *jsdumper.BuildInfo `json:"jsdumper,omitempty"` // Build of the last run that updated it
+	Secrets   []string            `json:"secrets"`            // SHA-256 of type:value
+	Endpoints []string            `json:"endpoints"`
+	URLs      []string            `json:"urls"`
+}
+
+// Version of the baseline file format
+const baselineVersion = 1
+
+// loadBaseline reads a baseline file; a missing one is an empty baseline, so
+// the first run reports everything and creates it
+func loadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Baseline{Version: baselineVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if baseline.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d in %s", baseline.Version, path)
+	}
+	return &baseline, nil
+}
+
+// newBaseline records the findings of a run
+func newBaseline(a *jsdumper.AggregatedResults) *Baseline {
+	info := jsdumper.CurrentBuildInfo()
+	baseline := &Baseline{
+		Version:   baselineVersion,
+		Updated:   time.Now().UTC().Format(time.RFC3339),
+		JSDumper:  &info,
+		Secrets:   []string{},
+		Endpoints: slices.Clone(a.Endpoints),
+		URLs:      slices.Clone(a.URLs),
+	}
+	for _, secret := range a.Secrets {
+		baseline.Secrets = append(baseline.Secrets, secretHash(secret))
+	}
+	slices.Sort(baseline.Secrets)
+	baseline.Secrets = slices.Compact(baseline.Secrets)
+	return baseline
+}
+
+// secretHash identifies a secret by type and value, whatever file it is in:
+// bundle names change with every build (app.3f9c2e.js)
+func secretHash(secret jsdumper.Secret) string {
+	sum := sha256.Sum256([]byte(secret.Type + ":" + secret.Value))
+	return hex.EncodeToString(sum[:])
+}
+
+// filter drops the secrets, endpoints and URLs already in the baseline,
+// returning how many were dropped
+func (b *Baseline) filter(a *jsdumper.AggregatedResults) int {
+	secrets := toSet(b.Secrets)
+	endpoints := toSet(b.Endpoints)
+	urls := toSet(b.URLs)
+
+	before := len(a.Secrets) + len(a.Endpoints) + len(a.URLs)
+	a.Secrets = slices.DeleteFunc(a.Secrets, func(s jsdumper.Secret) bool { return secrets[secretHash(s)] })
+	a.Endpoints = slices.DeleteFunc(a.Endpoints, func(e string) bool { return endpoints[e] })
+	a.ImportantEndpoints = slices.DeleteFunc(a.ImportantEndpoints, func(e string) bool { return endpoints[e] })
+	a.URLs = slices.DeleteFunc(a.URLs, func(u string) bool { return urls[u] })
+	return before - len(a.Secrets) - len(a.Endpoints) - len(a.URLs)
+}
+
+// merge adds the findings of a previous baseline, so a partial run (failed
+// downloads, -only subsets) doesn't drop findings it didn't see
+func (b *Baseline) merge(previous *Baseline) {
+	b.Secrets = union(b.Secrets, previous.Secrets)
+	b.Endpoints = union(b.Endpoints, previous.Endpoints)
+	b.URLs = union(b.URLs, previous.URLs)
+}
+
+func (b *Baseline) write(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// union returns the sorted values of a and b, without duplicates
+func union(a, b []string) []string {
+	values := append(slices.Clone(a), b...)
+	slices.Sort(values)
+	return slices.Compact(values)
+}
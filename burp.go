@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// burpItem is a request of a Burp Suite proxy history export ("Save items"):
+// the request URL and the raw HTTP response, base64-encoded unless
+// base64="false"
+type burpItem struct {
+	URL       string `xml:"url" json:"url"`
+	MimeType  string `xml:"mimetype" json:"mimetype"`
+	Extension string `xml:"extension" json:"extension"`
+	Response  struct {
+		Base64 string `xml:"base64,attr"`
+		Data   string `xml:",chardata"`
+	} `xml:"response" json:"-"`
+}
+
+// burpJSONItem is the JSON form of burpItem: the same fields, with the
+// response always base64-encoded
+type burpJSONItem struct {
+	burpItem
+	Response string `json:"response"`
+}
+
+// readBurpExport returns the JavaScript responses of a Burp export in XML
+// (<items burpVersion="...">) or JSON (an array of items, or {"items": [...]}),
+// once per URL and body, and how many responses could not be read
+func readBurpExport(exportPath string) ([]capturedScript, int, error) {
+	data, err := os.ReadFile(exportPath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read Burp export: %w", err)
+	}
+
+	var items []burpItem
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		var export struct {
+			Items []burpItem `xml:"item"`
+		}
+		if err := xml.Unmarshal(data, &export); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse Burp export %s: %w", exportPath, err)
+		}
+		items = export.Items
+	default:
+		var jsonItems []burpJSONItem
+		if bytes.HasPrefix(trimmed, []byte("{")) {
+			var export struct {
+				Items []burpJSONItem `json:"items"`
+			}
+			err = json.Unmarshal(data, &export)
+			jsonItems = export.Items
+		} else {
+			err = json.Unmarshal(data, &jsonItems)
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to parse Burp export %s: %w", exportPath, err)
+		}
+		for _, item := range jsonItems {
+			item.burpItem.Response.Data = item.Response
+			items = append(items, item.burpItem)
+		}
+	}
+
+	var scripts []capturedScript
+	skipped := 0
+	seen := make(map[capturedScript]bool)
+	for _, item := range items {
+		if item.Response.Data == "" {
+			continue // Requests without a response
+		}
+		raw := []byte(item.Response.Data)
+		if item.Response.Base64 != "false" {
+			if raw, err = base64.StdEncoding.DecodeString(strings.TrimSpace(item.Response.Data)); err != nil {
+				skipped++
+				continue
+			}
+		}
+		body, contentType, err := burpResponseBody(raw)
+		if err != nil {
+			skipped++
+			continue
+		}
+		if body == "" {
+			continue
+		}
+		if !isJavaScriptResponse(item.URL, contentType) && !strings.EqualFold(item.MimeType, "script") && !strings.EqualFold(item.Extension, "js") {
+			continue
+		}
+		script := capturedScript{URL: item.URL, Body: body}
+		if !seen[script] {
+			scripts = append(scripts, script)
+			seen[script] = true
+		}
+	}
+	return scripts, skipped, nil
+}
+
+// burpResponseBody parses a raw HTTP response as Burp recorded it, undoing
+// chunked transfer and content encodings. Burp writes HTTP/2 and HTTP/3
+// responses with an "HTTP/2 200 OK" status line, which net/http doesn't
+// parse: it is read as HTTP/1.1, the header and body being the same.
+func burpResponseBody(raw []byte) (body, contentType string, err error) {
+	for _, version := range []string{"HTTP/2 ", "HTTP/2.0 ", "HTTP/3 ", "HTTP/3.0 "} {
+		if bytes.HasPrefix(raw, []byte(version)) {
+			raw = append([]byte("HTTP/1.1 "), raw[len(version):]...)
+			break
+		}
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	defer resp.Body.Close()
+
+	decoder, err := jsdumper.DecodeBody(resp.Body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return "", "", err
+	}
+	defer decoder.Close()
+	data, err := io.ReadAll(decoder)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return string(data), resp.Header.Get("Content-Type"), nil
+}
+
+// ProcessBurp extracts from every JavaScript response of a Burp proxy
+// history export. Findings are attributed to the request URLs: each response
+// is scanned under its URL as file name.
+func (c *CLI) ProcessBurp(exportPath string) error {
+	c.log(fmt.Sprintf("Reading Burp export: %s", exportPath), colorCyan)
+
+	scripts, skipped, err := readBurpExport(exportPath)
+	if err != nil {
+		return err
+	}
+	if skipped > 0 {
+		c.log(fmt.Sprintf("Warning: %d response(s) of %s could not be parsed and were skipped", skipped, exportPath), colorYellow)
+	}
+	if len(scripts) == 0 {
+		c.log(fmt.Sprintf("No JavaScript responses found in %s", exportPath), colorYellow)
+		return nil
+	}
+	c.log(fmt.Sprintf("Found %d JavaScript response(s)", len(scripts)), colorCyan)
+
+	allResults := c.runPool(len(scripts), func(i int) []*jsdumper.Results {
+		script := scripts[i]
+		c.log(fmt.Sprintf("Processing: %s", script.URL), colorDim)
+		c.recordSource(script.URL, script.URL)
+		return []*jsdumper.Results{c.extract(script.Body, script.URL)}
+	})
+
+	return c.writeResults(allResults)
+}
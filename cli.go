@@ -2,11 +2,20 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
 )
 
 func min(a, b int) int {
@@ -17,40 +26,394 @@ func min(a, b int) int {
 }
 
 type Config struct {
-	OutputDir string
-	Append    bool
-	NoColor   bool
-	JSON      bool
-	Quiet     bool
+	OutputDir     string
+	Append        bool
+	NoColor       bool
+	JSON          bool
+	Quiet         bool
+	Keywords      []string
+	RulesFile     string
+	Plugins       []string // External detector commands (-plugin)
+	Filters       []string // Filter presets secrets must match (-filter)
+	FailOn        string   // Lowest secret severity that fails the run (-fail-on)
+	MaxMemory     int64    // Soft memory limit in bytes (-max-memory, 0 = none)
+	ASCII         bool
+	SRI           bool
+	Threads       int
+	Sinks         []string
+	SinkTemplate  string   // Go template for http(s) sink bodies
+	Target        string   // What is being scanned, for sink messages
+	Tags          []string // Labels of the scan, carried by every output (-tag)
+	NoSourceMaps  bool
+	NoChunks      bool   // Don't download the lazily loaded chunks of webpack bundles
+	Beautify      bool   // Pretty-print minified files before extraction
+	BeautifyDir   string // Where pretty-printed files are saved (-save-beautified, "" = not saved)
+	Documents     bool   // Scan the scripts embedded in PDF and Office documents
+	Variants      bool
+	IncludeAssets bool
+	Feedback      bool     // Write pattern-feedback.json
+	Baseline      string   // Findings of previous runs, only new ones are reported
+	Provenance    bool     // Write provenance.json
+	Args          []string // Command line, recorded in provenance.json
+	Formats       []string // Extra output formats (sarif, postman, csv, json, jsonl)
+	SplitSize     int64    // Split endpoints.txt/urls.txt into parts of this many bytes (0 = never)
+	NormalizeURLs jsdumper.URLNormalization
+	Fast          bool // Only the -fast detectors, in one pass
+
+	// Network
+	Proxy       string
+	Insecure    bool
+	Headers     []string // "Name: value"
+	Cookies     []string
+	ForbidHosts []string // Never contacted, on top of cloud metadata hosts
+
+	// Download retries
+	Retries    int
+	RetryDelay time.Duration
+
+	SessionFile string // Cookie jar persisted across runs
+	Record      string // Fixtures directory all responses are saved to
+	Replay      string // Fixtures directory responses are served from, offline
+	DownloadDir string // Where downloaded files are kept ("" = a directory of the run's own in the user cache directory, removed afterwards)
+
+	PerURLTimeout time.Duration // Budget for download and extraction of each listed URL (0 = none)
+	WaybackLimit  int           // Archived script versions fetched by -wayback (0 = all)
+
+	// Per-pattern budget
+	MaxMatches     int
+	PatternTimeout time.Duration
+
+	// Text output encoding
+	CRLF        bool
+	BOM         bool
+	EnforceUTF8 bool
 }
 
 type CLI struct {
 	config     *Config
-	extractor  *Extractor
-	downloader *Downloader
+	term       *Terminal
+	pipeline   *jsdumper.Pipeline
+	options    jsdumper.Options
+	downloader *jsdumper.Downloader
+	sinks      []Sink
+	runDir     string // Download directory of this run under the default location, removed by Close
+
+	skippedMu sync.Mutex
+	skipped   []string // "input | reason" lines for errors.txt
+
+	started  time.Time
+	inputsMu sync.Mutex
+	inputs   []provenanceDigest // Scanned content, for provenance.json
+
+	sourcesMu sync.Mutex
+	sources   map[string]string // File name -> URL it was downloaded from, for findings.csv
+	paths     map[string]string // File name -> path relative to the working directory, for results.sarif
+
+	warningsMu sync.Mutex
+	warnings   map[string][]jsdumper.Warning // Download warnings of files not scanned yet
+
+	chunksMu sync.Mutex
+	chunks   map[string]bool // Webpack chunk URLs already downloaded
+
+	baseline *Baseline    // Known findings (-baseline)
+	stream   *jsonlStream // findings.jsonl, written during the run (-format jsonl)
+	failing  int          // Reported secrets at or above -fail-on
+	memory   *memoryGuard // Soft memory limit (-max-memory)
 }
 
-func NewCLI(config *Config) *CLI {
-	return &CLI{
+func NewCLI(config *Config) (*CLI, error) {
+	extractor := jsdumper.NewExtractor()
+	if len(config.Keywords) > 0 {
+		extractor.SetKeywords(config.Keywords)
+	}
+	if config.RulesFile != "" {
+		rules, err := jsdumper.LoadRules(config.RulesFile)
+		if err != nil {
+			return nil, err
+		}
+		extractor.SetRules(rules)
+	}
+
+	pipeline := jsdumper.NewPipeline(extractor).
+		Transform(jsdumper.DecompileHermes).
+		Transform(jsdumper.DecodeEscapes).
+		Transform(jsdumper.DecodeStringArrays)
+	for _, command := range config.Plugins {
+		plugin, err := jsdumper.NewPlugin(command)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Detect(plugin.Detect)
+	}
+	if len(config.Filters) > 0 {
+		var defined map[string]jsdumper.Filter
+		if config.RulesFile != "" {
+			var err error
+			if defined, err = jsdumper.LoadFilters(config.RulesFile); err != nil {
+				return nil, err
+			}
+		}
+		filters, err := jsdumper.ResolveFilters(config.Filters, defined)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.Use(jsdumper.FilterStage(filters))
+	}
+
+	var message *template.Template
+	if config.SinkTemplate != "" {
+		tmpl, err := LoadSinkTemplate(config.SinkTemplate)
+		if err != nil {
+			return nil, err
+		}
+		message = tmpl
+	}
+	report, err := filepath.Abs(config.OutputDir)
+	if err != nil {
+		report = config.OutputDir
+	}
+
+	var sinks []Sink
+	for _, spec := range config.Sinks {
+		sink, err := NewSink(spec, message, SinkMessage{Target: config.Target, Report: report})
+		if err != nil {
+			return nil, fmt.Errorf("invalid sink: %w", err)
+		}
+		sinks = append(sinks, sink)
+	}
+
+	downloader, err := jsdumper.NewDownloader(jsdumper.DownloaderConfig{
+		Proxy:       config.Proxy,
+		Insecure:    config.Insecure,
+		Headers:     config.Headers,
+		Cookies:     config.Cookies,
+		ForbidHosts: config.ForbidHosts,
+
+		Retries:    config.Retries,
+		RetryDelay: config.RetryDelay,
+
+		SessionFile: config.SessionFile,
+
+		Record: config.Record,
+		Replay: config.Replay,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	options := jsdumper.DefaultOptions()
+	options.MaxMatches = config.MaxMatches
+	options.PatternTimeout = config.PatternTimeout
+	options.URLs = config.NormalizeURLs
+	options.Fast = config.Fast
+	options.Tags = config.Tags
+	// JWTs are checked for expiry against the start of the run, the same
+	// time for every input
+	started := time.Now()
+	options.Now = func() time.Time { return started }
+
+	var baseline *Baseline
+	if config.Baseline != "" {
+		if baseline, err = loadBaseline(config.Baseline); err != nil {
+			return nil, err
+		}
+	}
+
+	var stream *jsonlStream
+	if slices.Contains(config.Formats, "jsonl") {
+		stream, err = newJSONLStream(filepath.Join(config.OutputDir, "findings.jsonl"), config.Append, baseline)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c := &CLI{
 		config:     config,
-		extractor:  NewExtractor(),
-		downloader: NewDownloader(),
+		term:       NewTerminal(config.NoColor, config.ASCII, config.Quiet),
+		pipeline:   pipeline,
+		options:    options,
+		downloader: downloader,
+		sinks:      sinks,
+		started:    started,
+		baseline:   baseline,
+		stream:     stream,
+		memory:     newMemoryGuard(config.MaxMemory),
 	}
+	if config.Fast {
+		c.log(jsdumper.FastCoverageNote, colorYellow)
+	}
+	return c, nil
 }
 
 func (c *CLI) log(message string, color string) {
-	if c.config.Quiet {
-		return
+	c.term.Println(message, color)
+}
+
+func (c *CLI) extract(content, fileName string) *jsdumper.Results {
+	return c.extractContext(context.Background(), content, fileName)
+}
+
+// extractContext extracts until ctx is done; callers report an expired ctx.
+// Binary content other than Hermes bytecode is not scanned, only warned about.
+func (c *CLI) extractContext(ctx context.Context, content, fileName string) *jsdumper.Results {
+	c.recordInput(content, fileName)
+	warnings := c.takeWarnings(fileName)
+	if jsdumper.IsHermesBytecode(content) {
+		c.log(fmt.Sprintf("Decompiling the Hermes bytecode string table of %s", fileName), colorDim)
+	} else if jsdumper.LooksBinary(content) {
+		c.log(fmt.Sprintf("Warning: Skipping %s: binary content", fileName), colorYellow)
+		warnings = append(warnings, jsdumper.Warning{Kind: jsdumper.WarningBinary, File: fileName})
+		return &jsdumper.Results{File: fileName, Warnings: warnings, Tags: c.options.Tags}
 	}
-	if c.config.NoColor {
-		fmt.Println(message)
-	} else {
-		fmt.Printf("%s%s%s\n", color, message, colorReset)
+	if jsdumper.LooksLikeHTML(content) {
+		c.log(fmt.Sprintf("Warning: File %s appears to be HTML, not JavaScript", fileName), colorYellow)
+		warnings = append(warnings, jsdumper.Warning{Kind: jsdumper.WarningHTML, File: fileName})
+	}
+
+	content = c.beautify(ctx, content, fileName)
+	results, err := c.pipeline.Run(ctx, content, fileName, c.options)
+	if err != nil && ctx.Err() == nil {
+		c.log(fmt.Sprintf("Extraction of %s stopped early: %v", fileName, err), colorYellow)
+	}
+	results.Warnings = append(results.Warnings, warnings...)
+	return results
+}
+
+// beautify pretty-prints minified content before extraction (-beautify), so
+// findings get meaningful line numbers, and keeps a copy to look them up in
+// (-save-beautified). Near -max-memory, files are scanned as they are.
+func (c *CLI) beautify(ctx context.Context, content, fileName string) string {
+	if !c.config.Beautify || !jsdumper.IsMinified(content) || c.skipBeautify() {
+		return content
+	}
+	pretty, err := jsdumper.Beautify(ctx, fileName, content)
+	if err != nil || pretty == content {
+		return content
+	}
+
+	if dir := c.config.BeautifyDir; dir != "" {
+		err := os.MkdirAll(dir, 0755)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, beautifiedName(fileName)), []byte(pretty), 0644)
+		}
+		if err != nil {
+			c.log(fmt.Sprintf("Error saving beautified %s: %v", fileName, err), colorRed)
+		}
+	}
+	return pretty
+}
+
+// download is Downloader.DownloadContext, except that a partial download is
+// kept and scanned: what is missing becomes a warning on its results
+func (c *CLI) download(ctx context.Context, url, localPath, fileName string) error {
+	err := c.downloader.DownloadContext(ctx, url, localPath)
+	var partial *jsdumper.PartialError
+	if !errors.As(err, &partial) {
+		return err
+	}
+	c.log(fmt.Sprintf("Warning: %s: %v", url, err), colorYellow)
+
+	c.warningsMu.Lock()
+	defer c.warningsMu.Unlock()
+	if c.warnings == nil {
+		c.warnings = make(map[string][]jsdumper.Warning)
+	}
+	c.warnings[fileName] = append(c.warnings[fileName], jsdumper.Warning{Kind: partial.Kind, File: fileName, Detail: partial.Err.Error()})
+	return nil
+}
+
+// takeWarnings returns and forgets the download warnings of fileName
+func (c *CLI) takeWarnings(fileName string) []jsdumper.Warning {
+	c.warningsMu.Lock()
+	defer c.warningsMu.Unlock()
+	warnings := c.warnings[fileName]
+	delete(c.warnings, fileName)
+	return warnings
+}
+
+// recordPath remembers the local file a file name was read from, so SARIF
+// results point into the repository being scanned
+func (c *CLI) recordPath(fileName, path string) {
+	if wd, err := os.Getwd(); err == nil {
+		if abs, err := filepath.Abs(path); err == nil {
+			if rel, err := filepath.Rel(wd, abs); err == nil {
+				path = rel
+			}
+		}
+	}
+	c.sourcesMu.Lock()
+	defer c.sourcesMu.Unlock()
+	if c.paths == nil {
+		c.paths = make(map[string]string)
+	}
+	if _, ok := c.paths[fileName]; !ok {
+		c.paths[fileName] = filepath.ToSlash(path)
 	}
 }
 
+// skip records an input that was not scanned, for errors.txt
+func (c *CLI) skip(input string, reason error) {
+	c.skippedMu.Lock()
+	defer c.skippedMu.Unlock()
+	c.skipped = append(c.skipped, fmt.Sprintf("%s | %v", input, reason))
+}
+
+// runPool calls process for inputs 0..n-1 on up to Config.Threads workers.
+// Results keep input order; failed inputs return nil and are dropped, and
+// spilled ones are left in the spool for writeResults to aggregate.
+func (c *CLI) runPool(n int, process func(i int) []*jsdumper.Results) []*jsdumper.Results {
+	threads := c.config.Threads
+	if threads < 1 {
+		threads = 1
+	}
+	if threads > n {
+		threads = n
+	}
+
+	ordered := make([][]*jsdumper.Results, n)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < threads; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				ordered[i] = process(i)
+				c.streamResults(ordered[i])
+				if c.spill(ordered[i]) {
+					ordered[i] = nil
+				}
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var results []*jsdumper.Results
+	for _, inputResults := range ordered {
+		for _, result := range inputResults {
+			if result != nil {
+				results = append(results, result)
+			}
+		}
+	}
+	return results
+}
+
 func (c *CLI) ProcessFile(filePath string) error {
 	c.log(fmt.Sprintf("Processing file: %s", filePath), colorCyan)
+	c.recordPath(filepath.Base(filePath), filePath)
+
+	if c.scanInChunks(filePath) {
+		results, err := c.extractChunks(context.Background(), filePath, filepath.Base(filePath))
+		if err != nil {
+			return err
+		}
+		return c.writeResults(results)
+	}
 
 	content, err := os.ReadFile(filePath)
 	if err != nil {
@@ -75,7 +438,8 @@ func (c *CLI) ProcessDirectory(dirPath string) error {
 			return nil
 		}
 		ext := strings.ToLower(filepath.Ext(path))
-		if ext == ".js" || ext == ".mjs" || ext == ".cjs" {
+		isScript := ext == ".js" || ext == ".mjs" || ext == ".cjs" || bundleExtensions[ext] || jsdumper.IsWranglerFile(info.Name())
+		if isScript || c.config.Documents && isDocument(path) {
 			jsFiles = append(jsFiles, path)
 		}
 		return nil
@@ -87,18 +451,28 @@ func (c *CLI) ProcessDirectory(dirPath string) error {
 
 	c.log(fmt.Sprintf("Found %d JavaScript file(s)", len(jsFiles)), colorCyan)
 
-	var allResults []*Results
-	for _, file := range jsFiles {
+	allResults := c.runPool(len(jsFiles), func(i int) []*jsdumper.Results {
+		file := jsFiles[i]
 		c.log(fmt.Sprintf("Processing: %s", file), colorDim)
+		c.recordPath(filepath.Base(file), file)
+		if c.config.Documents && isDocument(file) {
+			return c.documentResults(file)
+		}
+		if c.scanInChunks(file) {
+			results, err := c.extractChunks(context.Background(), file, filepath.Base(file))
+			if err != nil {
+				c.log(fmt.Sprintf("Error reading %s: %v", file, err), colorRed)
+			}
+			return results
+		}
 		content, err := os.ReadFile(file)
 		if err != nil {
 			c.log(fmt.Sprintf("Error reading %s: %v", file, err), colorRed)
-			continue
+			return nil
 		}
 
-		results := c.extractor.ExtractAll(string(content), filepath.Base(file))
-		allResults = append(allResults, results)
-	}
+		return []*jsdumper.Results{c.extract(string(content), filepath.Base(file))}
+	})
 
 	return c.writeResults(allResults)
 }
@@ -106,30 +480,45 @@ func (c *CLI) ProcessDirectory(dirPath string) error {
 func (c *CLI) ProcessURL(url string) error {
 	c.log(fmt.Sprintf("Downloading: %s", url), colorCyan)
 
-	tempDir := filepath.Join(".", ".jsdumper-downloads")
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
+	tempDir, err := c.downloadDir()
+	if err != nil {
+		return err
 	}
 
-	fileName := filepath.Base(url)
-	if fileName == "" || fileName == "/" {
-		fileName = "downloaded.js"
-	}
-	localPath := filepath.Join(tempDir, fileName)
+	localPath := filepath.Join(tempDir, downloadFileName(url, "downloaded.js"))
 
-	if err := c.downloader.Download(url, localPath); err != nil {
+	ctx, cancel := c.urlContext()
+	defer cancel()
+	if err := c.download(ctx, url, localPath, filepath.Base(localPath)); err != nil {
+		if ctx.Err() != nil {
+			err = c.urlTimeoutError()
+		}
 		return fmt.Errorf("failed to download: %w", err)
 	}
 
+	c.recordSource(filepath.Base(localPath), url)
 	c.log(fmt.Sprintf("Downloaded successfully: %s", localPath), colorGreen)
 	c.log(fmt.Sprintf("Processing: %s", localPath), colorCyan)
 
+	if c.scanInChunks(localPath) {
+		results, err := c.extractChunks(ctx, localPath, filepath.Base(localPath))
+		if err != nil {
+			return fmt.Errorf("failed to read downloaded file: %w", err)
+		}
+		if ctx.Err() != nil {
+			return c.urlTimeoutError()
+		}
+		return c.writeResults(results)
+	}
+
 	content, err := os.ReadFile(localPath)
 	if err != nil {
 		return fmt.Errorf("failed to read downloaded file: %w", err)
 	}
 
-	return c.processContent(string(content), filepath.Base(localPath))
+	extra := c.sourceMapResults(ctx, url, string(content), localPath)
+	extra = append(extra, c.chunkResults(url, string(content), localPath)...)
+	return c.processContentContext(ctx, string(content), filepath.Base(localPath), extra...)
 }
 
 func (c *CLI) ProcessList(listFile string) error {
@@ -142,11 +531,21 @@ func (c *CLI) ProcessList(listFile string) error {
 	defer file.Close()
 
 	var urls []string
+	var urlTags [][]string // Tags of each URL, after the URL on its line
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" {
-			urls = append(urls, line)
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		expanded, err := expandTemplate(fields[0])
+		if err != nil {
+			return fmt.Errorf("invalid list entry: %w", err)
+		}
+		tags := uniqueTags(fields[1:])
+		for _, url := range expanded {
+			urls = append(urls, url)
+			urlTags = append(urlTags, tags)
 		}
 	}
 
@@ -161,38 +560,93 @@ func (c *CLI) ProcessList(listFile string) error {
 
 	c.log(fmt.Sprintf("Downloading %d remote file(s)...", len(urls)), colorCyan)
 
-	tempDir := filepath.Join(".", ".jsdumper-downloads")
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
+	tempDir, err := c.downloadDir()
+	if err != nil {
+		return err
 	}
 
-	var allResults []*Results
-	for i, url := range urls {
-		fileName := filepath.Base(url)
-		if fileName == "" || fileName == "/" {
-			fileName = fmt.Sprintf("downloaded_%d.js", i+1)
-		}
-		localPath := filepath.Join(tempDir, fileName)
+	allResults := c.runPool(len(urls), func(i int) []*jsdumper.Results {
+		url := urls[i]
+		fileName := downloadFileName(url, fmt.Sprintf("downloaded_%d.js", i+1))
+		// Prefix with the list position so parallel downloads of same-named files don't collide
+		results := c.scanRemote(url, fileName, filepath.Join(tempDir, fmt.Sprintf("%d_%s", i+1, fileName)))
+		tagResults(results, urlTags[i])
+		return results
+	})
 
-		c.log(fmt.Sprintf("Downloading: %s", url), colorDim)
-		if err := c.downloader.Download(url, localPath); err != nil {
-			c.log(fmt.Sprintf("Error downloading %s: %v", url, err), colorRed)
-			continue
-		}
+	c.log(fmt.Sprintf("Downloaded %d file(s)", len(allResults)), colorGreen)
+	return c.writeResults(allResults)
+}
 
-		c.log(fmt.Sprintf("Processing: %s", localPath), colorDim)
-		content, err := os.ReadFile(localPath)
-		if err != nil {
-			c.log(fmt.Sprintf("Error reading %s: %v", localPath, err), colorRed)
-			continue
+// tagResults adds the tags of an input to its results, on top of the
+// run-wide -tag values
+func tagResults(results []*jsdumper.Results, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	for _, result := range results {
+		if result != nil {
+			result.Tags = uniqueTags(append(slices.Clone(result.Tags), tags...))
 		}
+	}
+}
 
-		results := c.extractor.ExtractAll(string(content), filepath.Base(localPath))
-		allResults = append(allResults, results)
+// urlContext bounds the download and scan of one URL by -per-url-timeout
+func (c *CLI) urlContext() (context.Context, context.CancelFunc) {
+	if c.config.PerURLTimeout > 0 {
+		return context.WithTimeout(context.Background(), c.config.PerURLTimeout)
 	}
+	return context.WithCancel(context.Background())
+}
 
-	c.log(fmt.Sprintf("Downloaded %d file(s)", len(allResults)), colorGreen)
-	return c.writeResults(allResults)
+func (c *CLI) urlTimeoutError() error {
+	return fmt.Errorf("exceeded -per-url-timeout %s", c.config.PerURLTimeout)
+}
+
+// scanRemote downloads url to localPath and extracts from it and its source
+// map as fileName, within -per-url-timeout. Failed and timed-out URLs are
+// skipped and listed in errors.txt, and return no results.
+func (c *CLI) scanRemote(url, fileName, localPath string) []*jsdumper.Results {
+	ctx, cancel := c.urlContext()
+	defer cancel()
+
+	c.log(fmt.Sprintf("Downloading: %s", url), colorDim)
+	if err := c.download(ctx, url, localPath, fileName); err != nil {
+		if ctx.Err() != nil {
+			err = fmt.Errorf("skipped: %w", c.urlTimeoutError())
+		}
+		c.log(fmt.Sprintf("Error downloading %s: %v", url, err), colorRed)
+		c.skip(url, err)
+		return nil
+	}
+
+	c.log(fmt.Sprintf("Processing: %s", localPath), colorDim)
+	c.recordSource(fileName, url)
+	var results []*jsdumper.Results
+	var content []byte
+	var err error
+	if c.scanInChunks(localPath) {
+		// The sourceMappingURL comment isn't looked for in chunks
+		results, err = c.extractChunks(ctx, localPath, fileName)
+	} else {
+		if content, err = os.ReadFile(localPath); err == nil {
+			results = []*jsdumper.Results{c.extractContext(ctx, string(content), fileName)}
+			results = append(results, c.sourceMapResults(ctx, url, string(content), localPath)...)
+		}
+	}
+	if err != nil {
+		c.log(fmt.Sprintf("Error reading %s: %v", localPath, err), colorRed)
+		c.skip(url, err)
+		return nil
+	}
+	if ctx.Err() != nil {
+		// Partial results of a timed-out URL would look like a complete scan
+		c.log(fmt.Sprintf("Skipping %s: %v", url, c.urlTimeoutError()), colorYellow)
+		c.skip(url, fmt.Errorf("skipped: %w", c.urlTimeoutError()))
+		return nil
+	}
+	// Chunks have a time budget of their own
+	return append(results, c.chunkResults(url, string(content), localPath)...)
 }
 
 func (c *CLI) ProcessStdin() error {
@@ -243,37 +697,28 @@ func (c *CLI) ProcessStdin() error {
 				}
 			}
 
-			// Download URLs first
+			// Scan URLs first, like those of -l
+			var allResults []*jsdumper.Results
 			if len(urls) > 0 {
-				tempDir := filepath.Join(".", ".jsdumper-downloads")
-				if err := os.MkdirAll(tempDir, 0755); err != nil {
-					return fmt.Errorf("failed to create temp directory: %w", err)
+				tempDir, err := c.downloadDir()
+				if err != nil {
+					return err
 				}
 
 				for i, url := range urls {
-					fileName := filepath.Base(url)
-					if fileName == "" || fileName == "/" {
-						fileName = fmt.Sprintf("downloaded_%d.js", i+1)
-					}
-					localPath := filepath.Join(tempDir, fileName)
-
-					if err := c.downloader.Download(url, localPath); err != nil {
-						c.log(fmt.Sprintf("Error downloading %s: %v", url, err), colorRed)
-						continue
-					}
-					localFiles = append(localFiles, localPath)
+					localPath := filepath.Join(tempDir, downloadFileName(url, fmt.Sprintf("downloaded_%d.js", i+1)))
+					allResults = append(allResults, c.scanRemote(url, filepath.Base(localPath), localPath)...)
 				}
 			}
 
-			// Process all files
-			var allResults []*Results
+			// Then local files
 			for _, filePath := range localFiles {
 				content, err := os.ReadFile(filePath)
 				if err != nil {
 					c.log(fmt.Sprintf("Error reading %s: %v", filePath, err), colorRed)
 					continue
 				}
-				results := c.extractor.ExtractAll(string(content), filepath.Base(filePath))
+				results := c.extract(string(content), filepath.Base(filePath))
 				allResults = append(allResults, results)
 			}
 
@@ -285,66 +730,93 @@ func (c *CLI) ProcessStdin() error {
 	return c.processContent(string(content), "stdin")
 }
 
-func (c *CLI) processContent(content, fileName string) error {
+// processContent extracts from a single input and writes the results together
+// with any extra results derived from it (e.g. source map sources)
+func (c *CLI) processContent(content, fileName string, extra ...*jsdumper.Results) error {
+	return c.processContentContext(context.Background(), content, fileName, extra...)
+}
+
+// processContentContext is processContent within ctx; nothing is written when
+// ctx runs out, since partial results would look like a complete scan
+func (c *CLI) processContentContext(ctx context.Context, content, fileName string, extra ...*jsdumper.Results) error {
 	// Check if file is empty
 	if len(content) == 0 {
 		c.log(fmt.Sprintf("Warning: File %s is empty", fileName), colorYellow)
 		return nil
 	}
 
-	trimmed := strings.TrimSpace(content)
-	
-	// More precise HTML detection - only check the very beginning of the file
-	// JavaScript files can contain "<html" in strings/comments, but real HTML files
-	// will start with HTML tags
-	isHTML := false
-	firstChars := strings.ToLower(trimmed)
-	if len(firstChars) > 0 {
-		// Check for HTML document structure at the start
-		if strings.HasPrefix(firstChars, "<!doctype") ||
-			strings.HasPrefix(firstChars, "<html") ||
-			strings.HasPrefix(firstChars, "<?xml") {
-			isHTML = true
-		}
-		
-		// Additional check: if first 500 chars contain multiple HTML tags, it's likely HTML
-		if !isHTML && len(trimmed) > 500 {
-			first500 := strings.ToLower(trimmed[:500])
-			htmlTagCount := strings.Count(first500, "<html") +
-				strings.Count(first500, "<head") +
-				strings.Count(first500, "<body") +
-				strings.Count(first500, "<div") +
-				strings.Count(first500, "<script")
-			// If we see many HTML tags at the start, it's likely HTML
-			// But also check if it looks like JavaScript (has function, var, const, etc.)
-			jsIndicators := strings.Count(first500, "function") +
-				strings.Count(first500, "var ") +
-				strings.Count(first500, "const ") +
-				strings.Count(first500, "let ") +
-				strings.Count(first500, "=>") +
-				strings.Count(first500, "()")
-			
-			// If HTML tags outnumber JS indicators significantly, it's HTML
-			if htmlTagCount > 3 && htmlTagCount > jsIndicators*2 {
-				isHTML = true
-			}
+	// Some servers return HTML error pages instead of the JS file
+	if jsdumper.LooksLikeHTML(content) {
+		trimmed := strings.TrimSpace(content)
+		if c.config.SRI {
+			return c.writeSRI(trimmed, fileName)
 		}
-	}
-	
-	if isHTML {
 		c.log(fmt.Sprintf("Warning: File %s appears to be HTML, not JavaScript", fileName), colorYellow)
 		c.log(fmt.Sprintf("First 200 chars: %s", trimmed[:min(200, len(trimmed))]), colorDim)
-		// Some servers return HTML error pages instead of the JS file
-		return nil
+		warnings := append(c.takeWarnings(fileName), jsdumper.Warning{Kind: jsdumper.WarningHTML, File: fileName})
+		page := &jsdumper.Results{File: fileName, Warnings: warnings, Tags: c.options.Tags}
+		if len(extra) > 0 {
+			return c.writeResults(append([]*jsdumper.Results{page}, extra...))
+		}
+		// Nothing was scanned: the outputs and baseline of earlier runs stay
+		return c.writeWarnings(page)
 	}
 
-	results := c.extractor.ExtractAll(content, fileName)
-	return c.writeResults([]*Results{results})
+	results := c.extractContext(ctx, content, fileName)
+	if ctx.Err() != nil {
+		return c.urlTimeoutError()
+	}
+	return c.writeResults(append([]*jsdumper.Results{results}, extra...))
 }
 
-func (c *CLI) writeResults(results []*Results) error {
-	// Aggregate results
-	aggregated := aggregateResults(results)
+// writeWarnings writes warnings.txt alone, for inputs that produced no
+// results to write
+func (c *CLI) writeWarnings(results *jsdumper.Results) error {
+	if err := os.MkdirAll(c.config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	aggregated := jsdumper.Aggregate([]*jsdumper.Results{results})
+	return c.writeFile(filepath.Join(c.config.OutputDir, "warnings.txt"), aggregated.FormatWarnings(), c.config.Append)
+}
+
+func (c *CLI) writeResults(results []*jsdumper.Results) error {
+	// Aggregate results, then those spilled to disk one input at a time
+	aggregator := jsdumper.NewAggregator()
+	feedback := newFeedbackCounter()
+	add := func(result *jsdumper.Results) {
+		aggregator.Add(result)
+		feedback.add(result)
+	}
+	for _, result := range results {
+		add(result)
+	}
+	c.streamResults(results)
+	if err := c.unspill(add); err != nil {
+		c.log(fmt.Sprintf("Error loading spilled results: %v", err), colorRed)
+	}
+	aggregated := aggregator.Aggregated()
+	if c.options.Fast {
+		aggregated.Coverage = "fast"
+	}
+
+	// Report only findings missing from the baseline, then add this run's
+	known := 0
+	if c.baseline != nil {
+		current := newBaseline(aggregated)
+		current.merge(c.baseline)
+		known = c.baseline.filter(aggregated)
+		if err := current.write(c.config.Baseline); err != nil {
+			return err
+		}
+	}
+
+	if c.config.FailOn != "" {
+		for _, secret := range aggregated.Secrets {
+			if jsdumper.SeverityWeight(secret.Severity) >= jsdumper.SeverityWeight(c.config.FailOn) {
+				c.failing++
+			}
+		}
+	}
 
 	// Ensure output directory exists
 	if err := os.MkdirAll(c.config.OutputDir, 0755); err != nil {
@@ -352,42 +824,237 @@ func (c *CLI) writeResults(results []*Results) error {
 	}
 
 	// Write secrets
-	if err := c.writeFile(filepath.Join(c.config.OutputDir, "keys.txt"), aggregated.formatSecrets(), c.config.Append); err != nil {
+	if err := c.writeFile(filepath.Join(c.config.OutputDir, "keys.txt"), aggregated.FormatSecrets(), c.config.Append); err != nil {
 		return err
 	}
 
 	// Write all endpoints
-	if err := c.writeFile(filepath.Join(c.config.OutputDir, "endpoints.txt"), aggregated.formatEndpoints(), c.config.Append); err != nil {
+	if c.config.SplitSize > 0 {
+		if err := c.writeSplitFile(filepath.Join(c.config.OutputDir, "endpoints.txt"), aggregated.FormatEndpoints()); err != nil {
+			return err
+		}
+	} else if err := c.writeFile(filepath.Join(c.config.OutputDir, "endpoints.txt"), aggregated.FormatEndpoints(), c.config.Append); err != nil {
 		return err
 	}
 
 	// Write important endpoints
-	if err := c.writeFile(filepath.Join(c.config.OutputDir, "important-endpoints.txt"), aggregated.formatImportantEndpoints(), c.config.Append); err != nil {
+	if err := c.writeFile(filepath.Join(c.config.OutputDir, "important-endpoints.txt"), aggregated.FormatImportantEndpoints(), c.config.Append); err != nil {
+		return err
+	}
+
+	// Write password reset, verification, magic link and 2FA endpoints
+	if err := c.writeFile(filepath.Join(c.config.OutputDir, "account-endpoints.txt"), aggregated.FormatAccountEndpoints(), c.config.Append); err != nil {
 		return err
 	}
 
 	// Write URLs
-	if err := c.writeFile(filepath.Join(c.config.OutputDir, "urls.txt"), aggregated.formatURLs(), c.config.Append); err != nil {
+	if c.config.SplitSize > 0 {
+		if err := c.writeSplitFile(filepath.Join(c.config.OutputDir, "urls.txt"), aggregated.FormatURLs()); err != nil {
+			return err
+		}
+	} else if err := c.writeFile(filepath.Join(c.config.OutputDir, "urls.txt"), aggregated.FormatURLs(), c.config.Append); err != nil {
+		return err
+	}
+
+	// Write WebSocket URLs
+	if err := c.writeFile(filepath.Join(c.config.OutputDir, "websockets.txt"), aggregated.WebSockets, c.config.Append); err != nil {
+		return err
+	}
+
+	// Write endpoint variants if requested
+	if c.config.Variants {
+		if err := c.writeFile(filepath.Join(c.config.OutputDir, "endpoint-variants.txt"), jsdumper.EndpointVariants(aggregated.ImportantEndpoints), c.config.Append); err != nil {
+			return err
+		}
+	}
+
+	// Write interesting strings
+	if err := c.writeFile(filepath.Join(c.config.OutputDir, "interesting.txt"), aggregated.FormatInteresting(), c.config.Append); err != nil {
+		return err
+	}
+
+	// Write cloud storage buckets
+	if err := c.writeFile(filepath.Join(c.config.OutputDir, "buckets.txt"), aggregated.FormatBuckets(), c.config.Append); err != nil {
 		return err
 	}
 
+	// Write source-control, CI and artifact references
+	if err := c.writeFile(filepath.Join(c.config.OutputDir, "infra.txt"), aggregated.FormatInfraReferences(), c.config.Append); err != nil {
+		return err
+	}
+
+	// Write hosts implied by environment and region host families
+	if err := c.writeFile(filepath.Join(c.config.OutputDir, "derived-hosts.txt"), aggregated.FormatDerivedHosts(), c.config.Append); err != nil {
+		return err
+	}
+	if len(aggregated.HostFamilies) > 0 {
+		if err := aggregated.WriteHostFamilies(filepath.Join(c.config.OutputDir, "host-families.json")); err != nil {
+			return err
+		}
+	}
+
+	// Write IP addresses, internal ones first
+	if err := c.writeFile(filepath.Join(c.config.OutputDir, "ips.txt"), aggregated.FormatIPs(), c.config.Append); err != nil {
+		return err
+	}
+
+	// Write DOM XSS sinks
+	if err := c.writeFile(filepath.Join(c.config.OutputDir, "sinks.txt"), aggregated.FormatDOMSinks(), c.config.Append); err != nil {
+		return err
+	}
+
+	// Write GraphQL operations
+	if err := c.writeFile(filepath.Join(c.config.OutputDir, "graphql.txt"), aggregated.FormatGraphQL(), c.config.Append); err != nil {
+		return err
+	}
+
+	// Write lazily loaded chunk and route names
+	if err := c.writeFile(filepath.Join(c.config.OutputDir, "chunks.txt"), aggregated.FormatChunkNames(), c.config.Append); err != nil {
+		return err
+	}
+
+	// Write embedded iframes, sign-in and payment widgets
+	if len(aggregated.Integrations) > 0 {
+		if err := aggregated.WriteIntegrations(filepath.Join(c.config.OutputDir, "integrations.json")); err != nil {
+			return err
+		}
+	}
+
+	// Write CSP and CORS configuration set from code
+	if len(aggregated.ConfigExposures) > 0 {
+		if err := aggregated.WriteConfigExposures(filepath.Join(c.config.OutputDir, "config-exposure.json")); err != nil {
+			return err
+		}
+	}
+
+	// Write Cloudflare Worker bindings
+	if len(aggregated.Bindings) > 0 {
+		if err := aggregated.WriteBindings(filepath.Join(c.config.OutputDir, "worker-bindings.json")); err != nil {
+			return err
+		}
+	}
+
+	// Write axios instances and their defaults
+	if len(aggregated.HTTPClients) > 0 {
+		if err := aggregated.WriteHTTPClients(filepath.Join(c.config.OutputDir, "http-clients.json")); err != nil {
+			return err
+		}
+	}
+
+	// Write GraphQL persisted queries
+	if len(aggregated.PersistedQueries) > 0 {
+		if err := aggregated.WritePersistedQueries(filepath.Join(c.config.OutputDir, "persisted-queries.json")); err != nil {
+			return err
+		}
+	}
+
+	// Write inputs whose findings may be incomplete
+	if len(aggregated.Warnings) > 0 {
+		if err := c.writeFile(filepath.Join(c.config.OutputDir, "warnings.txt"), aggregated.FormatWarnings(), c.config.Append); err != nil {
+			return err
+		}
+	}
+
+	// Write inputs that were skipped (download failures, -per-url-timeout)
+	if len(c.skipped) > 0 {
+		sort.Strings(c.skipped)
+		if err := c.writeFile(filepath.Join(c.config.OutputDir, "errors.txt"), c.skipped, c.config.Append); err != nil {
+			return err
+		}
+	}
+
+	// Deliver findings to configured sinks
+	c.sendToSinks(aggregated)
+
+	// Write extra formats
+	for _, format := range c.config.Formats {
+		switch format {
+		case "sarif":
+			sarifPath := filepath.Join(c.config.OutputDir, "results.sarif")
+			c.sourcesMu.Lock()
+			err := aggregated.WriteSARIF(sarifPath, c.paths)
+			c.sourcesMu.Unlock()
+			if err != nil {
+				return err
+			}
+			c.log(fmt.Sprintf("SARIF written to: %s", sarifPath), colorGreen)
+		case "json":
+			findingsPath := filepath.Join(c.config.OutputDir, "findings.json")
+			if err := aggregated.WriteFindings(findingsPath); err != nil {
+				return err
+			}
+			c.log(fmt.Sprintf("Findings written to: %s", findingsPath), colorGreen)
+		case "jsonl":
+			c.log(fmt.Sprintf("JSON Lines written to: %s", filepath.Join(c.config.OutputDir, "findings.jsonl")), colorGreen)
+		case "csv":
+			csvPath := filepath.Join(c.config.OutputDir, "findings.csv")
+			if err := c.writeCSV(csvPath, aggregated); err != nil {
+				return err
+			}
+			c.log(fmt.Sprintf("CSV written to: %s", csvPath), colorGreen)
+		case "postman":
+			postmanPath := filepath.Join(c.config.OutputDir, "jsdumper.postman_collection.json")
+			if err := aggregated.WritePostman(postmanPath, "jsdumper: "+c.config.Target, targetOrigin(c.config.Target)); err != nil {
+				return err
+			}
+			c.log(fmt.Sprintf("Postman collection written to: %s", postmanPath), colorGreen)
+		}
+	}
+
+	// Write anonymous detector statistics if requested
+	if c.config.Feedback {
+		feedbackPath := filepath.Join(c.config.OutputDir, "pattern-feedback.json")
+		if err := writePatternFeedback(feedback.result(), feedbackPath); err != nil {
+			return err
+		}
+		c.log(fmt.Sprintf("Pattern feedback written to: %s", feedbackPath), colorGreen)
+	}
+
 	// Write JSON summary if requested
 	if c.config.JSON {
-		if err := aggregated.writeJSON(filepath.Join(c.config.OutputDir, "summary.json")); err != nil {
+		if err := aggregated.WriteJSON(filepath.Join(c.config.OutputDir, "summary.json")); err != nil {
 			return err
 		}
 		c.log(fmt.Sprintf("Summary written to: %s", filepath.Join(c.config.OutputDir, "summary.json")), colorGreen)
 	}
 
+	// Record how the outputs were produced, once they are all written
+	if c.config.Provenance {
+		provenancePath := filepath.Join(c.config.OutputDir, "provenance.json")
+		if err := c.writeProvenance(provenancePath); err != nil {
+			return err
+		}
+		c.log(fmt.Sprintf("Provenance written to: %s", provenancePath), colorGreen)
+	}
+
 	// Print summary
 	c.log("", "")
 	c.log("=== Extraction Summary ===", colorGreen)
+	if c.options.Fast {
+		c.log(jsdumper.FastCoverageNote, colorYellow)
+	}
+	if len(aggregated.Tags) > 0 {
+		c.log(fmt.Sprintf("Tags: %s", strings.Join(aggregated.Tags, ", ")), colorCyan)
+		for _, group := range aggregated.TagGroups() {
+			c.log(fmt.Sprintf("  %s: %d file(s), risk %d, %d secret(s), %d endpoint(s), %d URL(s)",
+				group.Tag, group.Files, group.RiskScore, group.Secrets, group.Endpoints, group.URLs), colorDim)
+		}
+	}
+	c.log(fmt.Sprintf("Risk score: %d", aggregated.RiskScore), colorRed)
+	if len(aggregated.Targets) > 1 {
+		for _, target := range aggregated.Targets[:min(5, len(aggregated.Targets))] {
+			c.log(fmt.Sprintf("  %d  %s", target.Score, target.Target), colorDim)
+		}
+	}
 	c.log(fmt.Sprintf("Secrets found: %d", len(aggregated.Secrets)), colorCyan)
+	criticalCount := 0
 	highCount := 0
 	mediumCount := 0
 	lowCount := 0
 	for _, s := range aggregated.Secrets {
 		switch s.Severity {
+		case "CRITICAL":
+			criticalCount++
 		case "HIGH":
 			highCount++
 		case "MEDIUM":
@@ -396,18 +1063,137 @@ func (c *CLI) writeResults(results []*Results) error {
 			lowCount++
 		}
 	}
+	if criticalCount > 0 {
+		c.log(fmt.Sprintf("  CRITICAL: %d", criticalCount), colorRed)
+	}
 	c.log(fmt.Sprintf("  HIGH: %d", highCount), colorRed)
 	c.log(fmt.Sprintf("  MEDIUM: %d", mediumCount), colorYellow)
 	c.log(fmt.Sprintf("  LOW: %d", lowCount), colorDim)
+	if aggregated.Suppressed > 0 {
+		c.log(fmt.Sprintf("  Suppressed: %d", aggregated.Suppressed), colorDim)
+	}
 	c.log(fmt.Sprintf("Endpoints found: %d", len(aggregated.Endpoints)), colorCyan)
 	c.log(fmt.Sprintf("  Important: %d", len(aggregated.ImportantEndpoints)), colorGreen)
+	if account := aggregated.AccountEndpoints(); len(account) > 0 {
+		c.log(fmt.Sprintf("  Account flows (reset, verification, magic link, 2FA): %d", len(account)), colorRed)
+	}
 	c.log(fmt.Sprintf("URLs found: %d", len(aggregated.URLs)), colorCyan)
+	c.log(fmt.Sprintf("WebSocket URLs found: %d", len(aggregated.WebSockets)), colorCyan)
+	c.log(fmt.Sprintf("Interesting strings: %d", len(aggregated.Interesting)), colorCyan)
+	c.log(fmt.Sprintf("Buckets found: %d", len(aggregated.Buckets)), colorCyan)
+	c.log(fmt.Sprintf("Infrastructure references found: %d", len(aggregated.InfraReferences)), colorCyan)
+	if len(aggregated.HostFamilies) > 0 {
+		c.log(fmt.Sprintf("Host families found: %d (derived hosts: %d)", len(aggregated.HostFamilies), len(aggregated.FormatDerivedHosts())), colorCyan)
+	}
+	c.log(fmt.Sprintf("DOM XSS sinks found: %d", len(aggregated.DOMSinks)), colorCyan)
+	c.log(fmt.Sprintf("GraphQL operations found: %d", len(aggregated.GraphQL)), colorCyan)
+	c.log(fmt.Sprintf("Chunk names found: %d", len(aggregated.ChunkNames)), colorCyan)
+	c.log(fmt.Sprintf("IP addresses found: %d (internal: %d)", len(aggregated.IPs), aggregated.InternalIPs()), colorCyan)
+	if len(aggregated.Integrations) > 0 {
+		c.log(fmt.Sprintf("Integrations found: %d", len(aggregated.Integrations)), colorCyan)
+	}
+	if len(aggregated.ConfigExposures) > 0 {
+		c.log(fmt.Sprintf("CSP/CORS configurations found: %d", len(aggregated.ConfigExposures)), colorCyan)
+	}
+	if len(aggregated.Bindings) > 0 {
+		c.log(fmt.Sprintf("Worker bindings found: %d", len(aggregated.Bindings)), colorCyan)
+	}
+	if len(aggregated.PersistedQueries) > 0 {
+		c.log(fmt.Sprintf("Persisted queries found: %d", len(aggregated.PersistedQueries)), colorCyan)
+	}
+	if len(aggregated.HTTPClients) > 0 {
+		c.log(fmt.Sprintf("HTTP clients found: %d", len(aggregated.HTTPClients)), colorCyan)
+	}
+	if c.config.Baseline != "" {
+		c.log(fmt.Sprintf("Known findings hidden (baseline): %d", known), colorDim)
+	}
+	if len(c.skipped) > 0 {
+		c.log(fmt.Sprintf("Inputs skipped: %d (see errors.txt)", len(c.skipped)), colorYellow)
+	}
+	if len(aggregated.Warnings) > 0 {
+		c.log(fmt.Sprintf("Input warnings: %d (see warnings.txt)", len(aggregated.Warnings)), colorYellow)
+	}
+	if len(aggregated.OverBudget) > 0 {
+		c.log(fmt.Sprintf("Patterns over budget: %s", strings.Join(aggregated.OverBudget, ", ")), colorYellow)
+	}
+	if c.failing > 0 {
+		c.log(fmt.Sprintf("Secrets at or above %s (-fail-on): %d", c.config.FailOn, c.failing), colorRed)
+	}
+	c.logMemory()
 	c.log("", "")
 	absOutput, _ := filepath.Abs(c.config.OutputDir)
 	c.log(fmt.Sprintf("Results written to: %s", absOutput), colorGreen)
 	c.log("  - endpoints.txt (all endpoints)", colorDim)
 	c.log("  - important-endpoints.txt (API endpoints only)", colorDim)
+	c.log("  - account-endpoints.txt (password reset, verification, magic link and 2FA endpoints)", colorDim)
+	c.log("  - interesting.txt (keyword hits for manual review)", colorDim)
+	if len(aggregated.Integrations) > 0 {
+		c.log("  - integrations.json (iframes, sign-in and payment widgets)", colorDim)
+	}
+
+	return nil
+}
+
+func (c *CLI) sendToSinks(aggregated *jsdumper.AggregatedResults) {
+	if len(c.sinks) == 0 {
+		return
+	}
+	for _, finding := range aggregated.Findings() {
+		for _, sink := range c.sinks {
+			if err := sink.Send(finding); err != nil {
+				c.log(fmt.Sprintf("Sink error: %v", err), colorRed)
+			}
+		}
+	}
+}
+
+// Failing returns the number of reported secrets at or above the -fail-on
+// severity, 0 without -fail-on
+func (c *CLI) Failing() int {
+	return c.failing
+}
+
+// Close releases output sinks and saves the download session
+func (c *CLI) Close() {
+	for _, sink := range c.sinks {
+		if err := sink.Close(); err != nil {
+			c.log(fmt.Sprintf("Sink error: %v", err), colorRed)
+		}
+	}
+	if c.stream != nil {
+		c.stream.Close()
+	}
+	if err := c.downloader.Close(); err != nil {
+		c.log(fmt.Sprintf("Error saving session: %v", err), colorRed)
+	}
+	if c.runDir != "" {
+		if err := os.RemoveAll(c.runDir); err != nil {
+			c.log(fmt.Sprintf("Error removing downloads: %v", err), colorRed)
+		}
+	}
+}
+
+// Report subresource-integrity coverage of an HTML page's external scripts
+func (c *CLI) writeSRI(html, fileName string) error {
+	scripts := analyzeSRI(html)
+
+	protected := 0
+	for _, script := range scripts {
+		if script.Integrity != "" {
+			protected++
+		}
+	}
+
+	if err := os.MkdirAll(c.config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	sriPath := filepath.Join(c.config.OutputDir, "sri.txt")
+	if err := c.writeFile(sriPath, formatSRI(scripts), c.config.Append); err != nil {
+		return err
+	}
 
+	c.log(fmt.Sprintf("SRI coverage for %s: %d/%d external script(s) protected", fileName, protected, len(scripts)), colorCyan)
+	c.log(fmt.Sprintf("SRI report written to: %s", sriPath), colorGreen)
 	return nil
 }
 
@@ -425,8 +1211,25 @@ func (c *CLI) writeFile(filePath string, lines []string, append bool) error {
 	}
 	defer file.Close()
 
+	// Only a new (or truncated) file gets a byte order mark
+	if c.config.BOM {
+		if info, err := file.Stat(); err == nil && info.Size() == 0 {
+			if _, err := file.WriteString("\uFEFF"); err != nil {
+				return fmt.Errorf("failed to write to file %s: %w", filePath, err)
+			}
+		}
+	}
+
+	newline := "\n"
+	if c.config.CRLF {
+		newline = "\r\n"
+	}
+
 	for _, line := range lines {
-		if _, err := fmt.Fprintln(file, line); err != nil {
+		if c.config.EnforceUTF8 {
+			line = strings.ToValidUTF8(line, "\uFFFD")
+		}
+		if _, err := file.WriteString(line + newline); err != nil {
 			return fmt.Errorf("failed to write to file %s: %w", filePath, err)
 		}
 	}
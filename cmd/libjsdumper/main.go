@@ -0,0 +1,133 @@
+// Command libjsdumper builds the extraction engine as a C shared library,
+// so tools in other languages can scan in-process instead of running the
+// jsdumper command:
+//
+//	go build -buildmode=c-shared -o libjsdumper.so ./cmd/libjsdumper
+//
+// The build also writes libjsdumper.h. Results are returned as JSON in
+// strings the caller releases with jsdumper_free. The functions and the
+// JSON fields below are the stable API; jsdumper_api_version changes when
+// either does incompatibly.
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"unsafe"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// apiVersion is the version of the C API, bumped on incompatible changes
+const apiVersion = 1
+
+// Scans run the transformers of the command: Hermes bytecode is decompiled,
+// escapes in strings and obfuscator string arrays decoded before extraction
+var pipeline = jsdumper.NewPipeline(jsdumper.NewExtractor()).
+	Transform(jsdumper.DecompileHermes).
+	Transform(jsdumper.DecodeEscapes).
+	Transform(jsdumper.DecodeStringArrays)
+
+// scanResult is the JSON document returned by jsdumper_scan
+type scanResult struct {
+	Secrets   []scanSecret       `json:"secrets"`
+	Endpoints []string           `json:"endpoints"`
+	URLs      []string           `json:"urls"`
+	Findings  []jsdumper.Finding `json:"findings"` // Every finding, as delivered to output sinks
+	Warnings  []jsdumper.Warning `json:"warnings"`
+	Error     string             `json:"error,omitempty"` // Set when extraction stopped early
+}
+
+type scanSecret struct {
+	Type     string `json:"type"`
+	Severity string `json:"severity"`
+	Line     int    `json:"line,omitempty"`
+	Value    string `json:"value"`
+	Detail   string `json:"detail,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+func main() {}
+
+// jsdumper_api_version returns the version of this API
+//
+//export jsdumper_api_version
+func jsdumper_api_version() C.int {
+	return apiVersion
+}
+
+// jsdumper_version returns the build information of the library as JSON,
+// the "jsdumper" object of the command's JSON outputs
+//
+//export jsdumper_version
+func jsdumper_version() *C.char {
+	return marshal(jsdumper.CurrentBuildInfo())
+}
+
+// jsdumper_scan runs every detector with the default options over the
+// length bytes of content, which may hold NULs (Hermes bytecode). file_name
+// names the content in findings and may be NULL. The result is never NULL.
+//
+//export jsdumper_scan
+func jsdumper_scan(content *C.char, length C.size_t, file_name *C.char) *C.char {
+	var fileName string
+	if file_name != nil {
+		fileName = C.GoString(file_name)
+	}
+	var data string
+	if content != nil && length > 0 {
+		// Copied, the caller owns content
+		data = strings.Clone(unsafe.String((*byte)(unsafe.Pointer(content)), int(length)))
+	}
+
+	results, err := pipeline.Run(context.Background(), data, fileName, jsdumper.DefaultOptions())
+	result := scanResult{
+		Secrets:   []scanSecret{},
+		Endpoints: []string{},
+		URLs:      []string{},
+		Findings:  []jsdumper.Finding{},
+		Warnings:  []jsdumper.Warning{},
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	if results != nil {
+		for _, secret := range results.Secrets {
+			result.Secrets = append(result.Secrets, scanSecret{
+				Type:     secret.Type,
+				Severity: secret.Severity,
+				Line:     secret.Line,
+				Value:    secret.Value,
+				Detail:   secret.Detail,
+				Encoding: secret.Encoding,
+			})
+		}
+		result.Endpoints = append(result.Endpoints, results.Endpoints...)
+		result.URLs = append(result.URLs, results.URLs...)
+		result.Findings = append(result.Findings, results.Findings()...)
+		result.Warnings = append(result.Warnings, results.Warnings...)
+	}
+	return marshal(result)
+}
+
+// jsdumper_free releases a string returned by the library
+//
+//export jsdumper_free
+func jsdumper_free(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+// marshal returns v as JSON in C memory
+func marshal(v any) *C.char {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(map[string]string{"error": err.Error()})
+	}
+	return C.CString(string(data))
+}
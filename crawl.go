@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	urlpkg "net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// ProcessCrawl fetches an HTML page, extracts from its inline scripts, then
+// downloads and extracts from every external script it references
+func (c *CLI) ProcessCrawl(pageURL string) error {
+	c.log(fmt.Sprintf("Crawling: %s", pageURL), colorCyan)
+
+	tempDir, err := c.downloadDir()
+	if err != nil {
+		return err
+	}
+
+	pagePath := filepath.Join(tempDir, "page_"+downloadFileName(pageURL, "index")+".html")
+	if err := c.downloader.Download(pageURL, pagePath); err != nil {
+		return fmt.Errorf("failed to download page: %w", err)
+	}
+	page, err := os.ReadFile(pagePath)
+	if err != nil {
+		return fmt.Errorf("failed to read downloaded page: %w", err)
+	}
+
+	base, err := urlpkg.Parse(pageURL)
+	if err != nil {
+		return fmt.Errorf("invalid page URL: %w", err)
+	}
+
+	var inline []string
+	var external []string
+	seen := make(map[string]bool)
+	for _, script := range parseScriptTags(string(page)) {
+		if script.Src == "" {
+			if strings.TrimSpace(script.Body) != "" {
+				inline = append(inline, script.Body)
+			}
+			continue
+		}
+		ref, err := urlpkg.Parse(script.Src)
+		if err != nil {
+			continue
+		}
+		resolved := base.ResolveReference(ref).String()
+		if isURL(resolved) && !seen[resolved] {
+			external = append(external, resolved)
+			seen[resolved] = true
+		}
+	}
+
+	c.log(fmt.Sprintf("Found %d external and %d inline script(s)", len(external), len(inline)), colorCyan)
+
+	// The page markup itself embeds iframes and widget scripts
+	pageOptions := c.options
+	pageOptions.Detectors = []string{jsdumper.DetectorIntegrations}
+	c.recordInput(string(page), pageURL)
+	pageResults, _ := c.pipeline.Run(context.Background(), string(page), pageURL, pageOptions)
+	allResults := []*jsdumper.Results{pageResults}
+
+	var scriptBodies []string
+	var bodiesMu sync.Mutex
+	for i, body := range inline {
+		fileName := fmt.Sprintf("%s#inline-%d", pageURL, i+1)
+		c.recordSource(fileName, pageURL)
+		allResults = append(allResults, c.extract(body, fileName))
+		scriptBodies = append(scriptBodies, body)
+	}
+
+	allResults = append(allResults, c.runPool(len(external), func(i int) []*jsdumper.Results {
+		scriptURL := external[i]
+		fileName := downloadFileName(scriptURL, fmt.Sprintf("script_%d.js", i+1))
+		localPath := filepath.Join(tempDir, fmt.Sprintf("%d_%s", i+1, fileName))
+
+		results := c.scanRemote(scriptURL, fileName, localPath)
+		if results != nil && c.config.IncludeAssets {
+			content, err := os.ReadFile(localPath)
+			if err != nil {
+				c.log(fmt.Sprintf("Error reading %s: %v", localPath, err), colorRed)
+				return results
+			}
+			bodiesMu.Lock()
+			scriptBodies = append(scriptBodies, string(content))
+			bodiesMu.Unlock()
+		}
+		return results
+	})...)
+
+	if c.config.IncludeAssets {
+		allResults = append(allResults, c.scanAssets(base, tempDir, pageAssets(base, string(page), scriptBodies))...)
+	}
+
+	return c.writeResults(allResults)
+}
+
+// Levels of stylesheets whose url() references are followed with
+// -include-assets: a page's CSS, the CSS it imports, and so on
+const maxAssetDepth = 3
+
+// scanAssets downloads and extracts from the JSON and CSS assets of a page,
+// then from the same-origin JSON and CSS files their stylesheets reference
+func (c *CLI) scanAssets(base *urlpkg.URL, tempDir string, assets []string) []*jsdumper.Results {
+	c.log(fmt.Sprintf("Found %d JSON/CSS asset(s)", len(assets)), colorCyan)
+	seen := toSet(assets)
+	scanned := 0
+	var allResults []*jsdumper.Results
+	for depth := 0; len(assets) > 0 && depth < maxAssetDepth; depth++ {
+		var linked []string
+		var linkedMu sync.Mutex
+		allResults = append(allResults, c.runPool(len(assets), func(i int) []*jsdumper.Results {
+			assetURL := assets[i]
+			n := scanned + i + 1
+			fileName := downloadFileName(assetURL, fmt.Sprintf("asset_%d", n))
+			localPath := filepath.Join(tempDir, fmt.Sprintf("asset_%d_%s", n, fileName))
+
+			results := c.scanRemote(assetURL, fileName, localPath)
+			if results == nil {
+				return nil
+			}
+			content, err := os.ReadFile(localPath)
+			if err != nil {
+				c.log(fmt.Sprintf("Error reading %s: %v", localPath, err), colorRed)
+				return results
+			}
+			if refs := stylesheetAssets(base, assetURL, string(content)); len(refs) > 0 {
+				linkedMu.Lock()
+				linked = append(linked, refs...)
+				linkedMu.Unlock()
+			}
+			return results
+		})...)
+		scanned += len(assets)
+
+		// Stylesheets finish in any order; the next level is sorted
+		assets = nil
+		for _, ref := range linked {
+			if !seen[ref] {
+				assets = append(assets, ref)
+				seen[ref] = true
+			}
+		}
+		sort.Strings(assets)
+		if len(assets) > 0 {
+			c.log(fmt.Sprintf("Found %d JSON/CSS asset(s) referenced by stylesheets", len(assets)), colorCyan)
+		}
+	}
+	return allResults
+}
+
+var jsonLiteralPattern = regexp.MustCompile("['\"`]([^'\"`\\s]+\\.json)(?:\\?[^'\"`\\s]*)?['\"`]")
+
+// url() references and @import rules of a stylesheet
+var cssReferencePattern = regexp.MustCompile(`(?i)(?:url\(\s*['"]?([^'")\s]+)['"]?\s*\)|@import\s+['"]([^'"]+)['"])`)
+
+// stylesheetAssets lists the same-origin JSON and CSS files a stylesheet
+// references with url() or @import, resolved against its URL; other
+// references (images, fonts) are left out. Non-CSS assets have none.
+func stylesheetAssets(base *urlpkg.URL, assetURL, content string) []string {
+	sheet, err := urlpkg.Parse(assetURL)
+	if err != nil || !strings.HasSuffix(strings.ToLower(sheet.Path), ".css") {
+		return nil
+	}
+	var assets []string
+	for _, match := range cssReferencePattern.FindAllStringSubmatch(content, -1) {
+		ref, err := urlpkg.Parse(match[1] + match[2])
+		if err != nil {
+			continue
+		}
+		resolved := sheet.ResolveReference(ref)
+		resolved.Fragment = ""
+		lowerPath := strings.ToLower(resolved.Path)
+		if resolved.Host != base.Host || !isURL(resolved.String()) ||
+			!strings.HasSuffix(lowerPath, ".css") && !strings.HasSuffix(lowerPath, ".json") {
+			continue
+		}
+		assets = append(assets, resolved.String())
+	}
+	return assets
+}
+
+// pageAssets lists the JSON and CSS files a page loads: stylesheets, manifests
+// and preloads from <link> tags, plus .json paths referenced by its scripts,
+// sorted since scripts are downloaded in parallel
+func pageAssets(base *urlpkg.URL, page string, scripts []string) []string {
+	var assets []string
+	seen := make(map[string]bool)
+	add := func(ref string) {
+		parsed, err := urlpkg.Parse(ref)
+		if err != nil {
+			return
+		}
+		resolved := base.ResolveReference(parsed)
+		// Runtime config is served by the target itself; skip third-party assets
+		if resolved.Host != base.Host || !isURL(resolved.String()) {
+			return
+		}
+		if !seen[resolved.String()] {
+			assets = append(assets, resolved.String())
+			seen[resolved.String()] = true
+		}
+	}
+
+	for _, link := range parseLinkTags(page) {
+		lowerHref := strings.ToLower(strings.Split(link.Href, "?")[0])
+		if strings.Contains(link.Rel, "stylesheet") || strings.Contains(link.Rel, "manifest") ||
+			link.As == "fetch" || strings.HasSuffix(lowerHref, ".json") || strings.HasSuffix(lowerHref, ".css") {
+			add(link.Href)
+		}
+	}
+
+	for _, script := range scripts {
+		for _, match := range jsonLiteralPattern.FindAllStringSubmatch(script, -1) {
+			if strings.HasPrefix(match[1], "/") || strings.HasPrefix(match[1], "./") || isURL(match[1]) {
+				add(match[1])
+			}
+		}
+	}
+
+	sort.Strings(assets)
+	return assets
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// recordSource remembers the URL a scanned file was downloaded from, for
+// findings.csv; the first URL wins when two downloads share a file name
+func (c *CLI) recordSource(fileName, url string) {
+	c.sourcesMu.Lock()
+	defer c.sourcesMu.Unlock()
+	if c.sources == nil {
+		c.sources = make(map[string]string)
+	}
+	if _, ok := c.sources[fileName]; !ok {
+		c.sources[fileName] = url
+	}
+}
+
+// csvCell keeps spreadsheets from evaluating values taken from scanned code
+// as formulas (CSV injection) by prefixing them with a quote. A leading tab or
+// carriage return is prefixed too, since some spreadsheets strip it and then
+// evaluate what follows. A leading - is only a formula before a digit (-1+1),
+// so private keys and flags stay intact.
+func csvCell(value string) string {
+	switch {
+	case value == "":
+	case strings.ContainsRune("=+@\t\r", rune(value[0])):
+		return "'" + value
+	case value[0] == '-' && len(value) > 1 && value[1] >= '0' && value[1] <= '9':
+		return "'" + value
+	}
+	return value
+}
+
+// Write findings.csv: one row per secret, endpoint, URL and infrastructure
+// reference, honoring the -newline and -bom text output settings. Findings
+// only found in a base64 literal have base64 as encoding. Tagged
+// scans (-tag) get a tags column, with the tags of the inputs of each row.
+func (c *CLI) writeCSV(filePath string, a *jsdumper.AggregatedResults) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create CSV file: %w", err)
+	}
+	defer file.Close()
+
+	if c.config.BOM {
+		if _, err := file.WriteString("\uFEFF"); err != nil {
+			return fmt.Errorf("failed to write CSV file: %w", err)
+		}
+	}
+	w := csv.NewWriter(file)
+	w.UseCRLF = c.config.CRLF
+
+	c.sourcesMu.Lock()
+	defer c.sourcesMu.Unlock()
+
+	tagged := len(a.Tags) > 0
+	row := func(file, value string, cells ...string) {
+		if tagged {
+			cells = append(cells, csvCell(strings.Join(a.TagsOf(file, value), ",")))
+		}
+		w.Write(cells)
+	}
+
+	if tagged {
+		w.Write([]string{"type", "severity", "file", "line", "value", "source_url", "encoding", "tags"})
+	} else {
+		w.Write([]string{"type", "severity", "file", "line", "value", "source_url", "encoding"})
+	}
+	encoded := make(map[string]bool)
+	for _, value := range a.Base64Encoded {
+		encoded[value] = true
+	}
+	encoding := func(value string) string {
+		if encoded[value] {
+			return "base64"
+		}
+		return ""
+	}
+	for _, secret := range a.Secrets {
+		line := ""
+		if secret.Line > 0 {
+			line = strconv.Itoa(secret.Line)
+		}
+		row(secret.File, secret.Value, secret.Type, secret.Severity, csvCell(secret.File), line, csvCell(secret.Value), c.sources[secret.File], secret.Encoding)
+	}
+	// Endpoints and URLs are aggregated across files, so they carry no location
+	for _, endpoint := range a.Endpoints {
+		row("", endpoint, "ENDPOINT", "", "", "", csvCell(endpoint), "", encoding(endpoint))
+	}
+	for _, url := range a.URLs {
+		row("", url, "URL", "", "", "", csvCell(url), "", encoding(url))
+	}
+	for _, ref := range a.InfraReferences {
+		row(ref.File, ref.Value, "INFRA_REFERENCE", "", csvCell(ref.File), "", csvCell(ref.Value), c.sources[ref.File], "")
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	return nil
+}
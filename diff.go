@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runDiff handles "jsdumper diff old new": compares the secrets, endpoints
+// and URLs of two runs, given as output directories or their summary.json
+func runDiff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		return fmt.Errorf("usage: jsdumper diff old-dir new-dir")
+	}
+	oldFindings, err := loadOutputFindings(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	newFindings, err := loadOutputFindings(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	printFindingsDiff(flags.Arg(0), flags.Arg(1), oldFindings, newFindings)
+	return nil
+}
+
+// loadOutputFindings reads the secrets, endpoints and URLs of an output
+// directory; a summary.json path stands for the directory it is in
+func loadOutputFindings(path string) ([]diffFinding, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run: %w", err)
+	}
+	dir := path
+	if !info.IsDir() {
+		if filepath.Base(path) != "summary.json" {
+			return nil, fmt.Errorf("%s is neither an output directory nor a summary.json", path)
+		}
+		dir = filepath.Dir(path)
+	}
+
+	keys, keysFound, err := readOutputLines(dir, "keys.txt")
+	if err != nil {
+		return nil, err
+	}
+	endpoints, endpointsFound, err := readOutputLines(dir, "endpoints.txt")
+	if err != nil {
+		return nil, err
+	}
+	urls, urlsFound, err := readOutputLines(dir, "urls.txt")
+	if err != nil {
+		return nil, err
+	}
+	if !keysFound && !endpointsFound && !urlsFound {
+		return nil, fmt.Errorf("no jsdumper output (keys.txt, endpoints.txt, urls.txt) in %s", dir)
+	}
+
+	var findings []diffFinding
+	for _, line := range keys {
+		// TYPE | file | value [| detail]; the file is left out since bundle names change between builds
+		fields := strings.SplitN(line, " | ", 4)
+		if len(fields) < 3 {
+			continue
+		}
+		findings = append(findings, diffFinding{
+			Key:   "secret:" + fields[0] + ":" + fields[2],
+			Label: fmt.Sprintf("%s %s (%s)", fields[0], fields[2], fields[1]),
+		})
+	}
+	for _, endpoint := range endpoints {
+		findings = append(findings, diffFinding{Key: "endpoint:" + endpoint, Label: "ENDPOINT " + endpoint})
+	}
+	for _, url := range urls {
+		findings = append(findings, diffFinding{Key: "url:" + url, Label: "URL " + url})
+	}
+	return findings, nil
+}
+
+// readOutputLines reads a text output of dir, following the index of a
+// file split with -split-size. found is false when there is neither.
+func readOutputLines(dir, name string) (lines []string, found bool, err error) {
+	ext := filepath.Ext(name)
+	index, err := readLines(filepath.Join(dir, strings.TrimSuffix(name, ext)+".index"+ext))
+	if err == nil {
+		for _, part := range index {
+			partLines, err := readLines(filepath.Join(dir, part))
+			if err != nil {
+				return nil, true, fmt.Errorf("failed to read %s: %w", part, err)
+			}
+			lines = append(lines, partLines...)
+		}
+		return lines, true, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, false, fmt.Errorf("failed to read %s index: %w", name, err)
+	}
+
+	lines, err = readLines(filepath.Join(dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return lines, true, nil
+}
+
+// readLines returns the non-empty lines of a text output, without byte order
+// mark or carriage returns (-bom, -newline crlf)
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(strings.TrimPrefix(scanner.Text(), "\uFEFF"), "\r")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
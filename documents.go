@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// Extensions of the documents opened with -documents: PDFs, and Office
+// documents in the legacy and Office Open XML formats that can hold macros
+var documentExtensions = map[string]bool{
+	".pdf": true,
+	".doc": true, ".dot": true, ".xls": true, ".xlt": true, ".xla": true, ".ppt": true, ".pps": true, ".pot": true,
+	".docm": true, ".dotm": true, ".xlsm": true, ".xltm": true, ".xlam": true, ".pptm": true, ".ppsm": true, ".potm": true, ".ppam": true,
+	".docx": true, ".xlsx": true, ".pptx": true,
+}
+
+// isDocument reports whether input is a PDF or Office document, by its
+// extension
+func isDocument(input string) bool {
+	return documentExtensions[strings.ToLower(filepath.Ext(input))]
+}
+
+// documentResults extracts from the scripts embedded in the document at
+// docPath, each named after the document and where it sits in it
+// (invoice.pdf#obj12, report.docm#Module1)
+func (c *CLI) documentResults(docPath string) []*jsdumper.Results {
+	data, err := os.ReadFile(docPath)
+	if err != nil {
+		c.log(fmt.Sprintf("Error reading %s: %v", docPath, err), colorRed)
+		return nil
+	}
+	scripts, err := jsdumper.DocumentScripts(string(data))
+	if err != nil {
+		c.log(fmt.Sprintf("Error reading %s: %v", docPath, err), colorRed)
+		return nil
+	}
+	if len(scripts) == 0 {
+		c.log(fmt.Sprintf("No embedded scripts found in %s", docPath), colorDim)
+		return nil
+	}
+	c.log(fmt.Sprintf("Found %d embedded script(s) in %s", len(scripts), docPath), colorCyan)
+
+	results := make([]*jsdumper.Results, 0, len(scripts))
+	for _, script := range scripts {
+		name := filepath.Base(docPath) + "#" + script.Name
+		c.recordPath(name, docPath)
+		results = append(results, c.extract(script.Content, name))
+	}
+	return results
+}
+
+// ProcessDocument extracts from the JavaScript of a PDF or the VBA macros of
+// an Office document (-documents)
+func (c *CLI) ProcessDocument(docPath string) error {
+	c.log(fmt.Sprintf("Reading document: %s", docPath), colorCyan)
+	return c.writeResults(c.documentResults(docPath))
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Most URLs a single list entry may expand to
+const maxExpansions = 10000
+
+// expandTemplate expands shell-style braces in a list entry: alternatives
+// (app.{js,min.js}) and numeric ranges (chunk-{1..50}.js, {001..120} keeps
+// the zero padding). Braces without a comma or range, such as {id}, are
+// kept as they are.
+func expandTemplate(entry string) ([]string, error) {
+	start, end, ok := findBraceGroup(entry)
+	if !ok {
+		return []string{entry}, nil
+	}
+
+	prefix, body, suffix := entry[:start], entry[start+1:end], entry[end+1:]
+	options, isRange, err := braceOptions(body)
+	if err != nil {
+		return nil, err
+	}
+	if options == nil && !isRange {
+		// Not an expansion: keep it and expand what follows
+		rest, err := expandTemplate(suffix)
+		if err != nil {
+			return nil, err
+		}
+		var expanded []string
+		for _, tail := range rest {
+			expanded = append(expanded, prefix+"{"+body+"}"+tail)
+		}
+		return expanded, nil
+	}
+
+	var expanded []string
+	for _, option := range options {
+		// Alternatives may hold braces themselves: {a,b{1..2}}
+		variants, err := expandTemplate(prefix + option + suffix)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, variants...)
+		if len(expanded) > maxExpansions {
+			return nil, fmt.Errorf("%q expands to more than %d URLs", entry, maxExpansions)
+		}
+	}
+	return expanded, nil
+}
+
+// findBraceGroup locates the first top-level {...} pair
+func findBraceGroup(entry string) (int, int, bool) {
+	depth, start := 0, -1
+	for i, c := range entry {
+		switch c {
+		case '{':
+			if depth == 0 {
+				start = i
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				return start, i, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// braceOptions splits the body of a brace group into its alternatives or
+// range values; nil options mean the group is not an expansion
+func braceOptions(body string) ([]string, bool, error) {
+	if from, to, ok := strings.Cut(body, ".."); ok && !strings.ContainsAny(body, ",{") {
+		first, err1 := strconv.Atoi(from)
+		last, err2 := strconv.Atoi(to)
+		if err1 != nil || err2 != nil {
+			return nil, false, nil
+		}
+		step := 1
+		if first > last {
+			step = -1
+		}
+		if (last-first)*step >= maxExpansions {
+			return nil, true, fmt.Errorf("range {%s} has more than %d values", body, maxExpansions)
+		}
+		width := 0
+		if (len(from) > 1 && from[0] == '0') || (len(to) > 1 && to[0] == '0') {
+			width = max(len(from), len(to))
+		}
+		var values []string
+		for n := first; ; n += step {
+			values = append(values, fmt.Sprintf("%0*d", width, n))
+			if n == last {
+				break
+			}
+		}
+		return values, true, nil
+	}
+
+	// Split on top-level commas only
+	var options []string
+	depth, start := 0, 0
+	for i, c := range body {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				options = append(options, body[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if options == nil {
+		return nil, false, nil
+	}
+	return append(options, body[start:]), false, nil
+}
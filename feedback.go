@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// DetectorFeedback counts how a secret type behaved over a run
+type DetectorFeedback struct {
+	Type           string `json:"type"`
+	Fired          int    `json:"fired"`          // Findings, including suppressed ones
+	FalsePositives int    `json:"falsePositives"` // Marked as false positives (jsdumper-ignore)
+	Files          int    `json:"files"`          // Files the type fired in
+}
+
+// PatternFeedback is the anonymous content of pattern-feedback.json: counts
+// only, never values, file names or hosts, so it can be attached to issues
+type PatternFeedback struct {
+	Tool       string             `json:"tool"`
+	Version    string             `json:"version"`
+	Patterns   string             `json:"patterns"`
+	Files      int                `json:"files"`
+	Detectors  []DetectorFeedback `json:"detectors"`
+	Endpoints  int                `json:"endpoints"`
+	URLs       int                `json:"urls"`
+	OverBudget []string           `json:"overBudget,omitempty"`
+}
+
+// feedbackCounter builds pattern-feedback.json from the results of a run,
+// one input at a time
+type feedbackCounter struct {
+	feedback   *PatternFeedback
+	byType     map[string]*DetectorFeedback
+	overBudget map[string]bool
+}
+
+func newFeedbackCounter() *feedbackCounter {
+	return &feedbackCounter{
+		feedback:   &PatternFeedback{Tool: "jsdumper", Version: jsdumper.Version(), Patterns: jsdumper.CurrentBuildInfo().Patterns, Detectors: []DetectorFeedback{}},
+		byType:     make(map[string]*DetectorFeedback),
+		overBudget: make(map[string]bool),
+	}
+}
+
+func (f *feedbackCounter) detector(secretType string) *DetectorFeedback {
+	if f.byType[secretType] == nil {
+		f.byType[secretType] = &DetectorFeedback{Type: secretType}
+	}
+	return f.byType[secretType]
+}
+
+// add counts the results of an input
+func (f *feedbackCounter) add(result *jsdumper.Results) {
+	if result == nil {
+		return
+	}
+	f.feedback.Files++
+	f.feedback.Endpoints += len(result.Endpoints)
+	f.feedback.URLs += len(result.URLs)
+
+	inFile := make(map[string]bool)
+	for _, secret := range result.Secrets {
+		f.detector(secret.Type).Fired++
+		inFile[secret.Type] = true
+	}
+	for _, secretType := range result.SuppressedTypes {
+		d := f.detector(secretType)
+		d.Fired++
+		d.FalsePositives++
+		inFile[secretType] = true
+	}
+	for secretType := range inFile {
+		f.detector(secretType).Files++
+	}
+
+	for _, name := range result.OverBudget {
+		f.overBudget[name] = true
+	}
+}
+
+// result returns the feedback of the inputs added
+func (f *feedbackCounter) result() *PatternFeedback {
+	feedback := f.feedback
+	for _, d := range f.byType {
+		feedback.Detectors = append(feedback.Detectors, *d)
+	}
+	sort.Slice(feedback.Detectors, func(i, j int) bool {
+		return feedback.Detectors[i].Type < feedback.Detectors[j].Type
+	})
+	for name := range f.overBudget {
+		feedback.OverBudget = append(feedback.OverBudget, name)
+	}
+	sort.Strings(feedback.OverBudget)
+	return feedback
+}
+
+// Write pattern-feedback.json; nothing is sent anywhere
+func writePatternFeedback(feedback *PatternFeedback, filePath string) error {
+	data, err := json.MarshalIndent(feedback, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode pattern feedback: %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write pattern feedback: %w", err)
+	}
+	return nil
+}
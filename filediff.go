@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// Characters shown on each side of a finding in code regions
+const diffRadius = 80
+
+// Length of the surrounding text used to find a region's counterpart in the old file
+const diffAnchor = 24
+
+// diffFinding is a finding of one file, keyed for comparison with the other
+type diffFinding struct {
+	Key   string // category:type:value
+	Label string // As printed, e.g. "[HIGH] JWT eyJ..."
+	Value string // Text located in the content for the code region
+}
+
+// runFileDiff handles "jsdumper filediff [-rules file] old.js new.js": diffs
+// the findings of two versions of a bundle and shows the code around new ones
+func runFileDiff(args []string) error {
+	fs := flag.NewFlagSet("filediff", flag.ExitOnError)
+	rulesFile := fs.String("rules", "", "YAML file with custom secret patterns")
+	noCode := fs.Bool("no-code", false, "Only diff findings, without code regions")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: jsdumper filediff [-rules file] [-no-code] old.js new.js")
+	}
+	oldPath, newPath := fs.Arg(0), fs.Arg(1)
+
+	extractor := jsdumper.NewExtractor()
+	if *rulesFile != "" {
+		rules, err := jsdumper.LoadRules(*rulesFile)
+		if err != nil {
+			return err
+		}
+		extractor.SetRules(rules)
+	}
+
+	oldContent, err := os.ReadFile(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	newContent, err := os.ReadFile(newPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	oldFindings := diffFindings(extractor, string(oldContent), oldPath)
+	newFindings := diffFindings(extractor, string(newContent), newPath)
+
+	added := printFindingsDiff(oldPath, newPath, oldFindings, newFindings)
+
+	if *noCode || len(added) == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Println("=== Code around new findings ===")
+	for _, f := range added {
+		pos := strings.Index(string(newContent), f.Value)
+		if pos == -1 {
+			continue
+		}
+		fmt.Printf("@@ %s (line %d) @@\n", f.Label, strings.Count(string(newContent[:pos]), "\n")+1)
+		if old, ok := counterpartRegion(string(oldContent), string(newContent), pos, len(f.Value)); ok {
+			fmt.Printf("- %s\n", old)
+		}
+		fmt.Printf("+ %s\n", codeRegion(string(newContent), pos, len(f.Value)))
+	}
+	return nil
+}
+
+// printFindingsDiff prints the findings removed from old and added in new,
+// returning the added ones
+func printFindingsDiff(oldName, newName string, oldFindings, newFindings []diffFinding) []diffFinding {
+	oldKeys := make(map[string]bool)
+	for _, f := range oldFindings {
+		oldKeys[f.Key] = true
+	}
+	newKeys := make(map[string]bool)
+	for _, f := range newFindings {
+		newKeys[f.Key] = true
+	}
+
+	var added, removed []diffFinding
+	for _, f := range newFindings {
+		if !oldKeys[f.Key] {
+			added = append(added, f)
+		}
+	}
+	for _, f := range oldFindings {
+		if !newKeys[f.Key] {
+			removed = append(removed, f)
+		}
+	}
+
+	fmt.Printf("=== Findings: %s -> %s ===\n", oldName, newName)
+	for _, f := range removed {
+		fmt.Printf("- %s\n", f.Label)
+	}
+	for _, f := range added {
+		fmt.Printf("+ %s\n", f.Label)
+	}
+	fmt.Printf("%d new, %d removed, %d unchanged\n", len(added), len(removed), len(newFindings)-len(added))
+	return added
+}
+
+// Extract every finding of content in a comparable form
+func diffFindings(extractor *jsdumper.Extractor, content, fileName string) []diffFinding {
+	results, _ := extractor.ExtractAll(context.Background(), content, fileName, jsdumper.DefaultOptions())
+
+	var findings []diffFinding
+	for _, secret := range results.Secrets {
+		findings = append(findings, diffFinding{
+			Key:   "secret:" + secret.Type + ":" + secret.Value,
+			Label: fmt.Sprintf("[%s] %s %s", secret.Severity, secret.Type, secret.Value),
+			Value: secret.Value,
+		})
+	}
+	for _, endpoint := range results.Endpoints {
+		findings = append(findings, diffFinding{Key: "endpoint:" + endpoint, Label: "ENDPOINT " + endpoint, Value: endpoint})
+	}
+	for _, url := range results.URLs {
+		findings = append(findings, diffFinding{Key: "url:" + url, Label: "URL " + url, Value: url})
+	}
+	return findings
+}
+
+// The code around content[pos:pos+length], clipped to its line and diffRadius
+func codeRegion(content string, pos, length int) string {
+	start := pos - diffRadius
+	if lineStart := strings.LastIndexByte(content[:pos], '\n') + 1; start < lineStart {
+		start = lineStart
+	}
+	end := pos + length + diffRadius
+	if lineEnd := strings.IndexByte(content[pos:], '\n'); lineEnd != -1 && end > pos+lineEnd {
+		end = pos + lineEnd
+	}
+	if end > len(content) {
+		end = len(content)
+	}
+	return strings.TrimSpace(strings.ToValidUTF8(content[start:end], ""))
+}
+
+// Find the region of the old file that corresponds to a new finding, using the
+// text just before (or after) the finding as an anchor
+func counterpartRegion(oldContent, newContent string, pos, length int) (string, bool) {
+	if start := pos - diffAnchor; start >= 0 {
+		anchor := newContent[start:pos]
+		if idx := strings.Index(oldContent, anchor); idx != -1 && !strings.Contains(anchor, "\n") {
+			return codeRegion(oldContent, idx+len(anchor), 0), true
+		}
+	}
+	if end := pos + length + diffAnchor; end <= len(newContent) {
+		anchor := newContent[pos+length : end]
+		if idx := strings.Index(oldContent, anchor); idx != -1 && !strings.Contains(anchor, "\n") {
+			return codeRegion(oldContent, idx, 0), true
+		}
+	}
+	return "", false
+}
@@ -1,7 +1,11 @@
-module jsdumper
+module github.com/d0xng/jsdumper
 
 go 1.22
 
 toolchain go1.24.4
 
-require github.com/andybalholm/brotli v1.2.0
+require (
+	github.com/andybalholm/brotli v1.2.0
+	github.com/klauspost/compress v1.17.11
+	gopkg.in/yaml.v3 v3.0.1
+)
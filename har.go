@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// harArchive is the part of a HAR (HTTP Archive) file jsdumper reads: the
+// URL and response body of each request
+type harArchive struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				URL string `json:"url"`
+			} `json:"request"`
+			Response struct {
+				Content struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// capturedScript is a JavaScript response body of a HAR file or Burp export
+type capturedScript struct {
+	URL  string
+	Body string
+}
+
+// isHAR reports whether input is a HAR file, by its extension
+func isHAR(input string) bool {
+	return strings.EqualFold(path.Ext(input), ".har")
+}
+
+// readHAR returns the JavaScript responses of a HAR file, once per URL and
+// body; responses recorded without their body are left out
+func readHAR(harPath string) ([]capturedScript, error) {
+	data, err := os.ReadFile(harPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HAR file: %w", err)
+	}
+	var archive harArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("failed to parse HAR file %s: %w", harPath, err)
+	}
+
+	var scripts []capturedScript
+	seen := make(map[capturedScript]bool)
+	for _, entry := range archive.Log.Entries {
+		content := entry.Response.Content
+		if content.Text == "" || !isJavaScriptResponse(entry.Request.URL, content.MimeType) {
+			continue
+		}
+		body := content.Text
+		if strings.EqualFold(content.Encoding, "base64") {
+			decoded, err := base64.StdEncoding.DecodeString(body)
+			if err != nil {
+				continue
+			}
+			body = string(decoded)
+		}
+		script := capturedScript{URL: entry.Request.URL, Body: body}
+		if !seen[script] {
+			scripts = append(scripts, script)
+			seen[script] = true
+		}
+	}
+	return scripts, nil
+}
+
+// isJavaScriptResponse reports whether a response is a script, by its MIME
+// type or, for servers sending a generic one, the extension of its URL
+func isJavaScriptResponse(url, mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	if strings.Contains(mimeType, "javascript") || strings.Contains(mimeType, "ecmascript") {
+		return true
+	}
+	if mimeType != "" && !strings.HasPrefix(mimeType, "text/plain") && !strings.HasPrefix(mimeType, "application/octet-stream") {
+		return false
+	}
+	switch strings.ToLower(path.Ext(downloadFileName(url, ""))) {
+	case ".js", ".mjs", ".cjs":
+		return true
+	}
+	return false
+}
+
+// ProcessHAR extracts from every JavaScript response captured in a HAR file,
+// e.g. a browser session exported from the developer tools
+func (c *CLI) ProcessHAR(harPath string) error {
+	c.log(fmt.Sprintf("Reading HAR file: %s", harPath), colorCyan)
+
+	scripts, err := readHAR(harPath)
+	if err != nil {
+		return err
+	}
+	if len(scripts) == 0 {
+		c.log(fmt.Sprintf("No JavaScript responses found in %s", harPath), colorYellow)
+		return nil
+	}
+	c.log(fmt.Sprintf("Found %d JavaScript response(s)", len(scripts)), colorCyan)
+
+	allResults := c.runPool(len(scripts), func(i int) []*jsdumper.Results {
+		script := scripts[i]
+		// Prefixed with the position of the script, as several URLs of the
+		// session often share a base name (main.js on two hosts)
+		fileName := fmt.Sprintf("%d_%s", i+1, downloadFileName(script.URL, "har.js"))
+		c.log(fmt.Sprintf("Processing: %s", script.URL), colorDim)
+		c.recordSource(fileName, script.URL)
+		return []*jsdumper.Results{c.extract(script.Body, fileName)}
+	})
+
+	return c.writeResults(allResults)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+var scriptBlockPattern = regexp.MustCompile(`(?is)<script\b([^>]*)>(.*?)</script\s*>`)
+var scriptSrcPattern = regexp.MustCompile(`(?i)\bsrc\s*=\s*['"]?([^'"\s>]+)`)
+var scriptIntegrityPattern = regexp.MustCompile(`(?i)\bintegrity\s*=\s*['"]([^'"]+)['"]`)
+var scriptTypePattern = regexp.MustCompile(`(?i)\btype\s*=\s*['"]?([^'"\s>]+)`)
+
+// scriptTag is a <script> element of an HTML page: either external (Src set)
+// or inline (Body set)
+type scriptTag struct {
+	Src       string
+	Integrity string
+	Type      string
+	Body      string
+}
+
+func parseScriptTags(html string) []scriptTag {
+	var scripts []scriptTag
+	for _, match := range scriptBlockPattern.FindAllStringSubmatch(html, -1) {
+		attrs := match[1]
+		script := scriptTag{}
+		if src := scriptSrcPattern.FindStringSubmatch(attrs); src != nil {
+			script.Src = src[1]
+		} else {
+			script.Body = match[2]
+		}
+		if integrity := scriptIntegrityPattern.FindStringSubmatch(attrs); integrity != nil {
+			script.Integrity = strings.TrimSpace(integrity[1])
+		}
+		if scriptType := scriptTypePattern.FindStringSubmatch(attrs); scriptType != nil {
+			script.Type = strings.ToLower(scriptType[1])
+		}
+		scripts = append(scripts, script)
+	}
+	return scripts
+}
+
+var linkTagPattern = regexp.MustCompile(`(?is)<link\b([^>]*)>`)
+var linkHrefPattern = regexp.MustCompile(`(?i)\bhref\s*=\s*['"]?([^'"\s>]+)`)
+var linkRelPattern = regexp.MustCompile(`(?i)\brel\s*=\s*['"]?([^'">]+)`)
+var linkAsPattern = regexp.MustCompile(`(?i)\bas\s*=\s*['"]?([^'"\s>]+)`)
+
+// linkTag is a <link> element of an HTML page
+type linkTag struct {
+	Href string
+	Rel  string
+	As   string
+}
+
+func parseLinkTags(html string) []linkTag {
+	var links []linkTag
+	for _, match := range linkTagPattern.FindAllStringSubmatch(html, -1) {
+		href := linkHrefPattern.FindStringSubmatch(match[1])
+		if href == nil {
+			continue
+		}
+		link := linkTag{Href: href[1]}
+		if rel := linkRelPattern.FindStringSubmatch(match[1]); rel != nil {
+			link.Rel = strings.ToLower(strings.TrimSpace(rel[1]))
+		}
+		if as := linkAsPattern.FindStringSubmatch(match[1]); as != nil {
+			link.As = strings.ToLower(as[1])
+		}
+		links = append(links, link)
+	}
+	return links
+}
@@ -1,23 +1,101 @@
 package main
 
 import (
+	"cmp"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// Exit statuses besides errors (1)
+const (
+	exitUsage  = 2 // Invalid flags, as for flag parsing errors
+	exitFailOn = 3 // Secrets at or above -fail-on were reported
 )
 
+// Formats accepted by -format
+var supportedFormats = map[string]bool{
+	"sarif":   true,
+	"postman": true,
+	"csv":     true,
+	"json":    true,
+	"jsonl":   true,
+}
+
 func main() {
 	var (
 		urlFlag      = flag.String("u", "", "Download and analyze a single URL")
-		listFlag     = flag.String("l", "", "Read URLs from a text file (one per line)")
+		listFlag     = flag.String("l", "", "Read URLs from a text file (one per line, optionally followed by tags)")
+		crawlFlag    = flag.String("crawl", "", "Fetch an HTML page and analyze its inline and external scripts")
+		burpFlag     = flag.String("burp", "", "Analyze the JavaScript responses of a Burp Suite proxy history export (XML or JSON)")
+		waybackFlag  = flag.String("wayback", "", "Download and analyze every version of a domain's scripts archived by the Wayback Machine")
+		waybackLimit = flag.Int("wayback-limit", 500, "With -wayback, maximum archived script versions fetched, in index order: by URL, then capture time (0 = all)")
+		assetsFlag   = flag.Bool("include-assets", false, "With -crawl, also scan same-origin JSON and CSS files the page loads")
 		outputFlag   = flag.String("o", "./", "Output directory")
 		appendFlag   = flag.Bool("a", false, "Append to output files instead of overwriting")
 		noColorFlag  = flag.Bool("no-color", false, "Disable colored output")
 		jsonFlag     = flag.Bool("json", false, "Generate summary.json with statistics")
+		baselineFlag = flag.String("baseline", "", "Only report secrets, endpoints and URLs missing from this baseline file, then update it with this run's findings")
+		provenance   = flag.Bool("provenance", false, "Write provenance.json: an in-toto statement of the tool version, input and pattern hashes, timestamps and environment")
+		feedbackFlag = flag.Bool("feedback", false, "Write pattern-feedback.json: anonymous per-detector counts to attach to issues")
+		proxyFlag    = flag.String("proxy", "", "Proxy for downloads: http://, https://, socks5:// or socks5h:// URL (default: HTTP_PROXY/HTTPS_PROXY)")
+		sessionFlag  = flag.String("session", "", "Load and save download cookies in this file to keep sessions across runs")
+		recordFlag   = flag.String("record", "", "Save every HTTP response to this fixtures directory, for -replay")
+		replayFlag   = flag.String("replay", "", "Serve HTTP responses from a -record fixtures directory instead of the network")
+		downloadFlag = flag.String("download-dir", "", "Directory to keep downloaded files in (default: a per-run directory under $XDG_CACHE_HOME/jsdumper/downloads, or the temp directory, removed afterwards)")
+		noCWDFlag    = flag.Bool("no-write-cwd", false, "Refuse to write anything in the working directory, for read-only containers (requires -o elsewhere)")
+		forbidFlag   = flag.String("forbid-hosts", "", "Comma-separated hosts, IPs or CIDR ranges never downloaded from (cloud metadata hosts always are)")
+		insecureFlag = flag.Bool("insecure", false, "Skip TLS certificate verification for downloads")
+		retriesFlag  = flag.Int("retries", 2, "Retries for downloads failing with a timeout, 429 or 5xx")
+		retryDelay   = flag.Duration("retry-delay", time.Second, "Initial wait between download retries, doubled each attempt")
+		urlTimeout   = flag.Duration("per-url-timeout", 0, "Time budget for downloading and scanning each URL (-u, -l, stdin lists, -crawl scripts and assets, -wayback); slower URLs are skipped and listed in errors.txt (0 = none)")
+		formatFlag   = flag.String("format", "", "Extra output formats, comma-separated: sarif, postman, csv, json, jsonl")
 		quietFlag    = flag.Bool("q", false, "Suppress all output except errors")
+		asciiFlag    = flag.Bool("ascii", false, "Replace non-ASCII characters in console output")
+		noEmojiFlag  = flag.Bool("no-emoji", false, "Alias for -ascii")
+		noMapsFlag   = flag.Bool("no-sourcemaps", false, "Don't fetch and scan source maps referenced by downloaded files")
+		noChunksFlag = flag.Bool("no-chunks", false, "Don't download and scan the lazily loaded chunks of downloaded webpack bundles")
+		beautifyFlag = flag.Bool("beautify", false, "Pretty-print minified files before extraction, for line numbers and patterns that stop at line ends")
+		docsFlag     = flag.Bool("documents", false, "Scan the JavaScript of PDF files and the VBA macros of Office documents, passed directly or found in a directory")
+		savePretty   = flag.Bool("save-beautified", false, "With -beautify, save the pretty-printed files in <output>/beautified to look findings up in")
+		variantsFlag = flag.Bool("variants", false, "Write endpoint-variants.txt with /api and version variants of important endpoints")
+		sriFlag      = flag.Bool("sri", false, "Report SRI coverage (sri.txt) when the input is an HTML page")
+		newlineFlag  = flag.String("newline", "lf", "Line endings for text outputs: lf or crlf")
+		bomFlag      = flag.Bool("bom", false, "Start text outputs with a UTF-8 byte order mark")
+		utf8Flag     = flag.Bool("utf8", false, "Replace invalid UTF-8 sequences in text outputs")
+		splitFlag    = flag.String("split-size", "", "Split endpoints.txt and urls.txt into numbered parts of at most this size, e.g. 10MB")
+		threads      int
+		sinks        stringList
+		headers      stringList
+		cookies      stringList
+		plugins      stringList
+		tags         stringList
+		maxMatches   = flag.Int("max-matches", 0, "Maximum matches taken from each pattern per file (0 = unlimited)")
+		patternTime  = flag.Duration("pattern-timeout", 30*time.Second, "Time budget per pattern per file (0 = unlimited)")
+		rulesFlag    = flag.String("rules", "", "YAML file with custom secret patterns and filter presets")
+		maxMemory    = flag.String("max-memory", "", "Soft memory limit, e.g. 2GB: near it, files are scanned in chunks, source maps skipped and results spilled to disk")
+		failOnFlag   = flag.String("fail-on", "", "Exit with status 3 when secrets at or above this severity are reported: CRITICAL, HIGH, MEDIUM or LOW")
+		filterFlag   = flag.String("filter", "", "Only report secrets matching these comma-separated filter presets: cloud-creds, messaging, high or -rules filters")
+		sinkTemplate = flag.String("sink-template", "", "Go template file for the body of http(s) sink requests")
+		urlNormFlag  = flag.String("normalize-urls", "host,port,tracking", "URL normalizations before dedup, comma-separated: host, port, query, tracking or none")
+		keywordsFlag = flag.String("keywords", "", "Comma-separated keywords for interesting.txt (default: built-in list)")
+		versionFlag  = flag.Bool("version", false, "Print the version, commit and pattern bundle, then exit")
+		fastFlag     = flag.Bool("fast", false, "Triage mode: only prefix-based secrets (ghp_, eyJ, SG., ...) and fetch/axios/XHR/common-route endpoints, in one pass; reduced coverage")
 	)
 
+	flag.IntVar(&threads, "t", 1, "Number of concurrent download/scan workers")
+	flag.IntVar(&threads, "threads", 1, "Alias for -t")
+	flag.Var(&headers, "H", "Extra request header \"Name: value\" for downloads (repeatable)")
+	flag.Var(&cookies, "cookie", "Cookie sent with downloads, e.g. \"session=abc\" (repeatable)")
+	flag.Var(&plugins, "plugin", "External detector command: gets each file on stdin and prints JSON findings (repeatable)")
+	flag.Var(&tags, "tag", "Label the scan, e.g. prod or a business unit; carried by findings, reports and sink payloads (repeatable; -l lines take tags of their own after the URL)")
+	flag.Var(&sinks, "sink", "Send each finding to a sink: exec:<cmd>, an http(s) URL, or syslog[://host:port] (repeatable)")
+
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [options] [input]\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "\nExtract security-relevant artifacts from JavaScript files\n\n")
@@ -27,10 +105,69 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  %s file.js\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -u https://example.com/file.js\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  %s -l urls.txt -o results\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -crawl https://example.com\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s session.har\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -burp proxy-history.xml\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s -wayback example.com\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  cat file.js | %s -\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s @scanargs.txt\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nCommands:\n")
+		fmt.Fprintf(os.Stderr, "  version [-check]    Print the version, optionally checking for a newer release\n")
+		fmt.Fprintf(os.Stderr, "  update [-force]     Download, verify and install the latest release\n")
+		fmt.Fprintf(os.Stderr, "  filediff old new    Diff the findings of two versions of a bundle\n")
+		fmt.Fprintf(os.Stderr, "  diff old new        Diff the secrets, endpoints and URLs of two output directories\n")
+		fmt.Fprintf(os.Stderr, "  patterns verify     Check detectors against their example corpus (-rules, -v)\n")
+	}
+
+	// Expand @file arguments before parsing flags
+	cmdArgs, err := expandArgFiles(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Subcommands
+	if len(cmdArgs) > 0 {
+		switch cmdArgs[0] {
+		case "version":
+			if err := runVersion(cmdArgs[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "filediff":
+			if err := runFileDiff(cmdArgs[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "diff":
+			if err := runDiff(cmdArgs[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "patterns":
+			if err := runPatterns(cmdArgs[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "update":
+			if err := runUpdate(cmdArgs[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
 	}
 
-	flag.Parse()
+	flag.CommandLine.Parse(cmdArgs)
+
+	if *versionFlag {
+		fmt.Print(jsdumper.CurrentBuildInfo().String())
+		return
+	}
 
 	args := flag.Args()
 	input := ""
@@ -39,66 +176,235 @@ func main() {
 	}
 
 	// Show help if no input, URL, or list file provided
-	if *urlFlag == "" && *listFlag == "" && (input == "" || input == "-") {
+	if *urlFlag == "" && *listFlag == "" && *crawlFlag == "" && *burpFlag == "" && *waybackFlag == "" && (input == "" || input == "-") {
 		flag.Usage()
 		return
 	}
 
-	// Initialize CLI
-	cli := NewCLI(&Config{
-		OutputDir: *outputFlag,
-		Append:    *appendFlag,
-		NoColor:   *noColorFlag,
-		JSON:      *jsonFlag,
-		Quiet:     *quietFlag,
-	})
+	newline := strings.ToLower(*newlineFlag)
+	if newline != "lf" && newline != "crlf" {
+		fmt.Fprintf(os.Stderr, "Error: invalid -newline %q (expected lf or crlf)\n", *newlineFlag)
+		os.Exit(exitUsage)
+	}
 
-	// Handle different input types
-	if *urlFlag != "" {
-		// Single URL
-		if err := cli.ProcessURL(*urlFlag); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
-	} else if *listFlag != "" {
-		// List file
-		if err := cli.ProcessList(*listFlag); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+	var splitSize int64
+	if *splitFlag != "" {
+		if *appendFlag {
+			fmt.Fprintf(os.Stderr, "Error: -split-size cannot be combined with -a\n")
+			os.Exit(exitUsage)
 		}
-	} else if input == "" || input == "-" {
-		// Stdin
-		if err := cli.ProcessStdin(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+		splitSize, err = parseSize(*splitFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -split-size: %v\n", err)
+			os.Exit(exitUsage)
 		}
-	} else {
-		// File or directory
-		info, err := os.Stat(input)
+	}
+
+	var memoryLimit int64
+	if *maxMemory != "" {
+		memoryLimit, err = parseSize(*maxMemory)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			fmt.Fprintf(os.Stderr, "Error: -max-memory: %v\n", err)
+			os.Exit(exitUsage)
 		}
+	}
+
+	normalization, err := jsdumper.ParseURLNormalization(splitList(*urlNormFlag))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -normalize-urls: %v\n", err)
+		os.Exit(exitUsage)
+	}
 
-		if info.IsDir() {
-			if err := cli.ProcessDirectory(input); err != nil {
+	failOn := strings.ToUpper(*failOnFlag)
+	if failOn != "" && jsdumper.SeverityWeight(failOn) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: unknown -fail-on severity %q (expected CRITICAL, HIGH, MEDIUM or LOW)\n", *failOnFlag)
+		os.Exit(exitUsage)
+	}
+
+	formats := splitList(strings.ToLower(*formatFlag))
+	for _, format := range formats {
+		if !supportedFormats[format] {
+			fmt.Fprintf(os.Stderr, "Error: unsupported -format %q\n", format)
+			os.Exit(exitUsage)
+		}
+	}
+
+	if *recordFlag != "" && *replayFlag != "" {
+		fmt.Fprintf(os.Stderr, "Error: -record and -replay cannot be combined\n")
+		os.Exit(exitUsage)
+	}
+
+	if *noCWDFlag {
+		locations := [][2]string{
+			{"-o", *outputFlag},
+			{"-download-dir", cmp.Or(*downloadFlag, defaultDownloadDirs()[0])},
+			{"-session", *sessionFlag},
+			{"-baseline", *baselineFlag},
+			{"-record", *recordFlag},
+		}
+		for _, location := range locations {
+			if err := checkNoWriteCWD(location[0], location[1]); err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(1)
 			}
-		} else {
-			// Check if it's a .txt file with URLs
-			if strings.HasSuffix(strings.ToLower(input), ".txt") || strings.HasSuffix(strings.ToLower(input), ".list") {
-				if err := cli.ProcessList(input); err != nil {
-					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-					os.Exit(1)
-				}
-			} else {
-				// Regular file
-				if err := cli.ProcessFile(input); err != nil {
-					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-					os.Exit(1)
-				}
-			}
 		}
 	}
+
+	beautifyDir := ""
+	if *savePretty {
+		beautifyDir = filepath.Join(*outputFlag, "beautified")
+	}
+
+	target := input
+	for _, candidate := range []string{*crawlFlag, *urlFlag, *listFlag, *burpFlag, *waybackFlag} {
+		if candidate != "" {
+			target = candidate
+			break
+		}
+	}
+
+	// Initialize CLI
+	cli, err := NewCLI(&Config{
+		OutputDir:     *outputFlag,
+		Append:        *appendFlag,
+		NoColor:       *noColorFlag,
+		JSON:          *jsonFlag,
+		Quiet:         *quietFlag,
+		Keywords:      splitList(*keywordsFlag),
+		RulesFile:     *rulesFlag,
+		Plugins:       plugins,
+		Filters:       splitList(*filterFlag),
+		FailOn:        failOn,
+		MaxMemory:     memoryLimit,
+		ASCII:         *asciiFlag || *noEmojiFlag,
+		SRI:           *sriFlag,
+		Threads:       threads,
+		Sinks:         sinks,
+		SinkTemplate:  *sinkTemplate,
+		Target:        target,
+		Tags:          uniqueTags(tags),
+		NoSourceMaps:  *noMapsFlag,
+		NoChunks:      *noChunksFlag,
+		Beautify:      *beautifyFlag || *savePretty,
+		BeautifyDir:   beautifyDir,
+		Documents:     *docsFlag,
+		Variants:      *variantsFlag,
+		IncludeAssets: *assetsFlag,
+		Feedback:      *feedbackFlag,
+		Baseline:      *baselineFlag,
+		Provenance:    *provenance,
+		Args:          cmdArgs,
+		Formats:       formats,
+		SplitSize:     splitSize,
+		NormalizeURLs: normalization,
+		Fast:          *fastFlag,
+
+		Proxy:       *proxyFlag,
+		Insecure:    *insecureFlag,
+		Headers:     headers,
+		Cookies:     cookies,
+		ForbidHosts: splitList(*forbidFlag),
+
+		Retries:    *retriesFlag,
+		RetryDelay: *retryDelay,
+
+		SessionFile: *sessionFlag,
+		Record:      *recordFlag,
+		Replay:      *replayFlag,
+		DownloadDir: *downloadFlag,
+
+		PerURLTimeout: *urlTimeout,
+		WaybackLimit:  *waybackLimit,
+
+		MaxMatches:     *maxMatches,
+		PatternTimeout: *patternTime,
+
+		CRLF:        newline == "crlf",
+		BOM:         *bomFlag,
+		EnforceUTF8: *utf8Flag,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(run(cli, input, inputFlags{
+		crawl:     *crawlFlag,
+		burp:      *burpFlag,
+		wayback:   *waybackFlag,
+		url:       *urlFlag,
+		list:      *listFlag,
+		documents: *docsFlag,
+	}, failOn))
+}
+
+// inputFlags are the flags choosing what a run scans, besides the positional
+// input
+type inputFlags struct {
+	crawl, burp, wayback, url, list string
+	documents                       bool
+}
+
+// run scans the input and returns the exit code. The CLI is closed before
+// returning, so sinks are flushed and the session saved on every path.
+func run(cli *CLI, input string, flags inputFlags, failOn string) int {
+	defer cli.Close()
+
+	if err := processInput(cli, input, flags); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	// Gate CI pipelines on leaked secrets
+	if failing := cli.Failing(); failing > 0 {
+		fmt.Fprintf(os.Stderr, "%d secret(s) at or above %s found (-fail-on)\n", failing, failOn)
+		return exitFailOn
+	}
+	return 0
+}
+
+// processInput dispatches the input to the matching Process method
+func processInput(cli *CLI, input string, flags inputFlags) error {
+	switch {
+	case flags.crawl != "":
+		// HTML page
+		return cli.ProcessCrawl(flags.crawl)
+	case flags.burp != "":
+		// Burp proxy history
+		return cli.ProcessBurp(flags.burp)
+	case flags.wayback != "":
+		// Archived versions of a domain's scripts
+		return cli.ProcessWayback(flags.wayback)
+	case flags.url != "":
+		// Single URL
+		return cli.ProcessURL(flags.url)
+	case flags.list != "":
+		// List file
+		return cli.ProcessList(flags.list)
+	case input == "" || input == "-":
+		// Stdin
+		return cli.ProcessStdin()
+	}
+
+	// File or directory
+	info, err := os.Stat(input)
+	if err != nil {
+		return err
+	}
+	lower := strings.ToLower(input)
+	switch {
+	case info.IsDir():
+		return cli.ProcessDirectory(input)
+	// HAR capture of a browser session, mobile app, document (-documents), or a .txt file with URLs
+	case isHAR(input):
+		return cli.ProcessHAR(input)
+	case isMobileApp(input):
+		return cli.ProcessMobileApp(input)
+	case flags.documents && isDocument(input):
+		return cli.ProcessDocument(input)
+	case strings.HasSuffix(lower, ".txt") || strings.HasSuffix(lower, ".list"):
+		return cli.ProcessList(input)
+	default:
+		// Regular file
+		return cli.ProcessFile(input)
+	}
 }
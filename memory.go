@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"runtime/metrics"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// memoryGuard enforces -max-memory. The limit becomes the Go runtime's soft
+// memory limit, and once the process comes close to it the run degrades
+// instead of growing: files are scanned in chunks rather than read whole,
+// source maps are skipped, and finished results are spilled to disk until
+// the outputs are written. Degraded runs stay degraded.
+type memoryGuard struct {
+	limit    int64
+	degraded atomic.Bool
+	warn     sync.Once
+
+	chunked      atomic.Int64 // Files scanned in chunks
+	skipped      atomic.Int64 // Source maps skipped
+	unbeautified atomic.Int64 // Minified files scanned as they are (-beautify)
+
+	spoolMu sync.Mutex
+	spool   *os.File
+	encoder *gob.Encoder
+	spilled int // Inputs whose results are in the spool
+}
+
+// Fraction of -max-memory at which the run degrades
+const memoryPressure = 0.8
+
+// Chunk size of files scanned in chunks
+const memoryChunkSize = 8 << 20
+
+// Files larger than this share of -max-memory are always scanned in chunks
+const memoryFileShare = 4
+
+// spooledResults is one input's results in the spool
+type spooledResults struct {
+	Results []*jsdumper.Results
+}
+
+func newMemoryGuard(limit int64) *memoryGuard {
+	if limit <= 0 {
+		return nil
+	}
+	debug.SetMemoryLimit(limit)
+	return &memoryGuard{limit: limit}
+}
+
+// memoryInUse is the memory the runtime holds, minus what it returned to the OS
+func memoryInUse() int64 {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+	return int64(samples[0].Value.Uint64() - samples[1].Value.Uint64())
+}
+
+// underPressure reports whether the run is degraded, degrading it when memory
+// use nears the limit; a nil guard (no -max-memory) never is
+func (c *CLI) underPressure() bool {
+	g := c.memory
+	if g == nil {
+		return false
+	}
+	if !g.degraded.Load() {
+		inUse := memoryInUse()
+		if float64(inUse) < memoryPressure*float64(g.limit) {
+			return false
+		}
+		g.degraded.Store(true)
+		g.warn.Do(func() {
+			c.log(fmt.Sprintf("Memory use %s is near -max-memory %s: scanning files in chunks, skipping source maps and spilling results to disk",
+				formatSize(inUse), formatSize(g.limit)), colorYellow)
+		})
+	}
+	return true
+}
+
+// scanInChunks reports whether the file at path should be scanned in chunks
+// rather than read whole
+func (c *CLI) scanInChunks(path string) bool {
+	if c.memory == nil || isHermesFile(path) {
+		return false
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > c.memory.limit/memoryFileShare {
+		return true
+	}
+	return c.underPressure()
+}
+
+// skipSourceMaps reports whether source maps are skipped to save memory
+func (c *CLI) skipSourceMaps() bool {
+	if !c.underPressure() {
+		return false
+	}
+	c.memory.skipped.Add(1)
+	return true
+}
+
+// skipBeautify reports whether minified files are scanned without being
+// beautified, which would hold a second, larger copy in memory
+func (c *CLI) skipBeautify() bool {
+	if !c.underPressure() {
+		return false
+	}
+	c.memory.unbeautified.Add(1)
+	return true
+}
+
+// extractChunks scans the file at path in overlapping chunks, so no more
+// than one chunk is in memory. Each chunk gives its own results; matches in
+// the overlap are found twice and merged when results are aggregated.
+func (c *CLI) extractChunks(ctx context.Context, path, fileName string) ([]*jsdumper.Results, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	c.memory.chunked.Add(1)
+
+	var results []*jsdumper.Results
+	hash := sha256.New()
+	buf := make([]byte, memoryChunkSize)
+	var overlap []byte
+	line := 0 // Lines before the chunk
+	for ctx.Err() == nil {
+		n, err := io.ReadFull(file, buf)
+		if n > 0 {
+			hash.Write(buf[:n])
+			chunk := append(overlap, buf[:n]...)
+			result, runErr := c.pipeline.Run(ctx, string(chunk), fileName, c.options)
+			if runErr != nil && ctx.Err() == nil {
+				c.log(fmt.Sprintf("Extraction of %s stopped early: %v", fileName, runErr), colorYellow)
+			}
+			for i := range result.Secrets {
+				if result.Secrets[i].Line > 0 {
+					result.Secrets[i].Line += line
+				}
+			}
+			for i := range result.DOMSinks {
+				if result.DOMSinks[i].Line > 0 {
+					result.DOMSinks[i].Line += line
+				}
+			}
+			results = append(results, result)
+
+			keep := min(len(chunk), jsdumper.MatchWindowOverlap)
+			line += strings.Count(string(chunk[:len(chunk)-keep]), "\n")
+			overlap = append([]byte(nil), chunk[len(chunk)-keep:]...)
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return results, fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+
+	c.recordDigest(fileName, map[string]string{"sha256": hex.EncodeToString(hash.Sum(nil))})
+	if len(results) > 0 {
+		results[0].Warnings = append(results[0].Warnings, c.takeWarnings(fileName)...)
+	}
+	return results, nil
+}
+
+// spill moves the results of an input to the spool file when memory is
+// short; they are read back by unspill
+func (c *CLI) spill(results []*jsdumper.Results) bool {
+	if len(results) == 0 || !c.underPressure() {
+		return false
+	}
+	g := c.memory
+	g.spoolMu.Lock()
+	defer g.spoolMu.Unlock()
+
+	if g.spool == nil {
+		spool, err := os.CreateTemp("", "jsdumper-spool-*")
+		if err != nil {
+			c.log(fmt.Sprintf("Error creating result spool: %v", err), colorRed)
+			return false
+		}
+		g.spool = spool
+		g.encoder = gob.NewEncoder(spool)
+	}
+	var kept []*jsdumper.Results
+	for _, result := range results {
+		if result != nil {
+			kept = append(kept, result)
+		}
+	}
+	if err := g.encoder.Encode(spooledResults{Results: kept}); err != nil {
+		c.log(fmt.Sprintf("Error spilling results: %v", err), colorRed)
+		return false
+	}
+	g.spilled++
+	if c.stream != nil {
+		c.stream.mark(results, false)
+	}
+	return true
+}
+
+// unspill reads the spilled results back one input at a time, passing each
+// result to add rather than keeping them, and removes the spool. Spilled
+// results were streamed to findings.jsonl before they were spilled.
+func (c *CLI) unspill(add func(*jsdumper.Results)) error {
+	g := c.memory
+	if g == nil || g.spool == nil {
+		return nil
+	}
+	g.spoolMu.Lock()
+	defer g.spoolMu.Unlock()
+	defer func() {
+		g.spool.Close()
+		os.Remove(g.spool.Name())
+		g.spool, g.encoder = nil, nil
+	}()
+
+	if _, err := g.spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read result spool: %w", err)
+	}
+	decoder := gob.NewDecoder(g.spool)
+	for {
+		var entry spooledResults
+		if err := decoder.Decode(&entry); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to read result spool: %w", err)
+		}
+		for _, result := range entry.Results {
+			add(result)
+		}
+	}
+	return nil
+}
+
+// logMemory reports in the summary how a degraded run differed
+func (c *CLI) logMemory() {
+	g := c.memory
+	if g == nil || !g.degraded.Load() && g.chunked.Load() == 0 {
+		return
+	}
+	message := fmt.Sprintf("Memory soft limit (-max-memory %s): %d file(s) scanned in chunks, %d source map(s) skipped, %d input(s) spilled to disk",
+		formatSize(g.limit), g.chunked.Load(), g.skipped.Load(), g.spilled)
+	if c.config.Beautify {
+		message += fmt.Sprintf(", %d file(s) not beautified", g.unbeautified.Load())
+	}
+	c.log(message, colorYellow)
+}
+
+// formatSize formats n bytes with binary units, e.g. 1.5GB
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fGB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
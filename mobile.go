@@ -0,0 +1,98 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// Extensions of React Native bundles: index.android.bundle and main.jsbundle
+// hold the app's JavaScript, or its Hermes bytecode, and .hbc is bytecode
+var bundleExtensions = map[string]bool{".bundle": true, ".jsbundle": true, ".hbc": true}
+
+// isMobileApp reports whether input is an Android (.apk, .aab) or iOS (.ipa)
+// app, by its extension
+func isMobileApp(input string) bool {
+	switch strings.ToLower(path.Ext(input)) {
+	case ".apk", ".aab", ".ipa":
+		return true
+	}
+	return false
+}
+
+// isAppScript reports whether a file of an app archive is JavaScript: a
+// React Native bundle or a script of the app's web views
+func isAppScript(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return bundleExtensions[ext] || ext == ".js" || ext == ".mjs"
+}
+
+// ProcessMobileApp extracts from the React Native bundles and scripts inside
+// an APK, AAB or IPA, decompiling Hermes bytecode
+func (c *CLI) ProcessMobileApp(appPath string) error {
+	c.log(fmt.Sprintf("Reading app: %s", appPath), colorCyan)
+
+	archive, err := zip.OpenReader(appPath)
+	if err != nil {
+		return fmt.Errorf("failed to open app archive: %w", err)
+	}
+	defer archive.Close()
+
+	var scripts []*zip.File
+	for _, file := range archive.File {
+		if !file.FileInfo().IsDir() && isAppScript(file.Name) {
+			scripts = append(scripts, file)
+		}
+	}
+	if len(scripts) == 0 {
+		c.log(fmt.Sprintf("No JavaScript bundles found in %s", appPath), colorYellow)
+		return nil
+	}
+	c.log(fmt.Sprintf("Found %d JavaScript bundle(s) and script(s)", len(scripts)), colorCyan)
+
+	allResults := c.runPool(len(scripts), func(i int) []*jsdumper.Results {
+		file := scripts[i]
+		c.log(fmt.Sprintf("Processing: %s", file.Name), colorDim)
+		content, err := readZipFile(file)
+		if err != nil {
+			c.log(fmt.Sprintf("Error reading %s: %v", file.Name, err), colorRed)
+			return nil
+		}
+		return []*jsdumper.Results{c.extract(content, file.Name)}
+	})
+
+	return c.writeResults(allResults)
+}
+
+func readZipFile(file *zip.File) (string, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// isHermesFile reports whether the file at path is Hermes bytecode, which
+// is decompiled whole rather than scanned in chunks
+func isHermesFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	magic := make([]byte, 8)
+	if _, err := io.ReadFull(file, magic); err != nil {
+		return false
+	}
+	return jsdumper.IsHermesBytecode(string(magic))
+}
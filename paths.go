@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Default locations of downloaded files, in order of preference: the user
+// cache ($XDG_CACHE_HOME/jsdumper/downloads on Linux), then the system temp
+// directory for containers without a writable home
+func defaultDownloadDirs() []string {
+	var dirs []string
+	if cache, err := os.UserCacheDir(); err == nil {
+		dirs = append(dirs, filepath.Join(cache, "jsdumper", "downloads"))
+	}
+	return append(dirs, filepath.Join(os.TempDir(), "jsdumper-downloads"))
+}
+
+// downloadDir creates and returns the directory downloaded files are kept in:
+// -download-dir, or a directory of this run's own under the first default
+// that can be created, so concurrent runs don't overwrite each other's files.
+// Close removes the latter.
+func (c *CLI) downloadDir() (string, error) {
+	if c.config.DownloadDir != "" {
+		if err := os.MkdirAll(c.config.DownloadDir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create download directory: %w", err)
+		}
+		return c.config.DownloadDir, nil
+	}
+	if c.runDir != "" {
+		return c.runDir, nil
+	}
+
+	var err error
+	for _, dir := range defaultDownloadDirs() {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			continue
+		}
+		if c.runDir, err = os.MkdirTemp(dir, "run-"); err == nil {
+			return c.runDir, nil
+		}
+	}
+	return "", fmt.Errorf("failed to create download directory: %w", err)
+}
+
+// checkNoWriteCWD rejects a location set by flagName that is in the working
+// directory, for -no-write-cwd
+func checkNoWriteCWD(flagName, path string) error {
+	if path == "" {
+		return nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	rel, err := filepath.Rel(cwd, abs)
+	if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s %s is in the working directory, which -no-write-cwd forbids", flagName, path)
+	}
+	return nil
+}
@@ -1,10 +0,0 @@
-package main
-
-// Patterns struct holds regex patterns (currently patterns are defined inline in extractor.go)
-// This file is kept for future pattern organization if needed
-type Patterns struct {
-}
-
-func NewPatterns() *Patterns {
-	return &Patterns{}
-}
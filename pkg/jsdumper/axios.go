@@ -0,0 +1,260 @@
+package jsdumper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// HTTPClient is an axios instance configured in code: its axios.create()
+// options, defaults assignments and interceptors. The base URL also resolves
+// the paths requested through the instance.
+type HTTPClient struct {
+	Name         string            `json:"name,omitempty"`    // Variable holding the instance, "axios" for the global defaults
+	BaseURL      string            `json:"baseURL,omitempty"` // Runtime values are kept as ${expression}
+	Headers      map[string]string `json:"headers,omitempty"`
+	Interceptors []string          `json:"interceptors,omitempty"` // request, response
+	File         string            `json:"file"`
+}
+
+// Longest object literal read for client options, in bytes
+const maxObjectLiteral = 4000
+
+func (e *Extractor) extractHTTPClients(run *extraction, content, fileName string) []HTTPClient {
+	var clients []*HTTPClient
+
+	// The last client declared under name, created if there is none yet
+	named := func(name string) *HTTPClient {
+		for i := len(clients) - 1; i >= 0; i-- {
+			if clients[i].Name == name {
+				return clients[i]
+			}
+		}
+		client := &HTTPClient{Name: name, File: fileName}
+		clients = append(clients, client)
+		return client
+	}
+
+	for _, loc := range run.findAllSubmatchIndex("axiosCreate", e.patterns.AxiosCreate, content) {
+		options, ok := objectLiteral(content, loc[1]-1)
+		if !ok {
+			continue
+		}
+		client := &HTTPClient{File: fileName}
+		if loc[2] >= 0 {
+			client.Name = content[loc[2]:loc[3]]
+		}
+		if m := e.patterns.AxiosBaseURL.FindStringSubmatchIndex(options); m != nil {
+			client.BaseURL = assignedValue(options, m, 1)
+		}
+		if m := e.patterns.AxiosHeaders.FindStringIndex(options); m != nil {
+			if headers, ok := objectLiteral(options, m[1]-1); ok {
+				for _, h := range e.patterns.AxiosHeader.FindAllStringSubmatchIndex(headers, -1) {
+					client.setHeader(headers[h[2]:h[3]], assignedValue(headers, h, 2))
+				}
+			}
+		}
+		clients = append(clients, client)
+	}
+
+	if run.canceled() {
+		return nil
+	}
+
+	for _, loc := range run.findAllSubmatchIndex("axiosDefaults", e.patterns.AxiosDefaults, content) {
+		client := named(content[loc[2]:loc[3]])
+		value := assignedValue(content, loc, 5)
+		switch {
+		case loc[4] >= 0:
+			client.BaseURL = value
+		case loc[6] >= 0:
+			client.setHeader(content[loc[6]:loc[7]], value)
+		default:
+			client.setHeader(content[loc[8]:loc[9]], value)
+		}
+	}
+	for _, match := range run.findAllSubmatch("axiosInterceptor", e.patterns.AxiosInterceptor, content) {
+		client := named(match[1])
+		if !slices.Contains(client.Interceptors, match[2]) {
+			client.Interceptors = append(client.Interceptors, match[2])
+			sort.Strings(client.Interceptors)
+		}
+	}
+
+	var found []HTTPClient
+	seen := make(map[string]bool)
+	for _, client := range clients {
+		key := client.key()
+		if (client.BaseURL != "" || len(client.Headers) > 0 || len(client.Interceptors) > 0) && !seen[key] {
+			found = append(found, *client)
+			seen[key] = true
+		}
+	}
+	return found
+}
+
+func (c *HTTPClient) setHeader(name, value string) {
+	if c.Headers == nil {
+		c.Headers = make(map[string]string)
+	}
+	c.Headers[name] = value
+}
+
+// key identifies a client by its name and configuration
+func (c *HTTPClient) key() string {
+	headers, _ := json.Marshal(c.Headers) // Map keys are sorted
+	return c.Name + ":" + c.BaseURL + ":" + string(headers) + ":" + strings.Join(c.Interceptors, ",")
+}
+
+// headerSecrets runs the secret detectors over the literal headers of
+// clients, which the patterns miss where they are set, as in
+// axios.defaults.headers.common["Authorization"] = "Bearer ...". Secrets
+// already in known are left out.
+func (e *Extractor) headerSecrets(run *extraction, clients []HTTPClient, fileName string, known []Secret) []Secret {
+	var headers strings.Builder
+	for _, client := range clients {
+		names := make([]string, 0, len(client.Headers))
+		for name := range client.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if value := client.Headers[name]; !strings.Contains(value, "${") {
+				fmt.Fprintf(&headers, "%s: %q\n", name, value)
+			}
+		}
+	}
+	if headers.Len() == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, secret := range known {
+		seen[secret.Type+":"+secret.Value] = true
+	}
+	var found []Secret
+	for _, secret := range e.extractSecrets(run, headers.String(), fileName) {
+		if !seen[secret.Type+":"+secret.Value] {
+			found = append(found, secret)
+			seen[secret.Type+":"+secret.Value] = true
+		}
+	}
+	return found
+}
+
+// clientEndpoints resolves the paths requested through clients with a
+// literal base URL: api.get("/users") with baseURL "https://api.acme.io/v1"
+// is /v1/users, and https://api.acme.io/v1/users when the base is absolute
+func (e *Extractor) clientEndpoints(run *extraction, content string, clients []HTTPClient) ([]string, []string, map[string][]string) {
+	var endpoints, urls []string
+	methods := make(map[string][]string)
+	seen := make(map[string]bool)
+
+	for _, client := range clients {
+		if client.Name == "" || client.BaseURL == "" || strings.Contains(client.BaseURL, "${") || run.canceled() {
+			continue
+		}
+		call := regexp.MustCompile(`(?:^|[^\w$.]|\bthis\.)` + regexp.QuoteMeta(client.Name) +
+			`\.(get|post|put|delete|patch|head|options|request)\s*(?:<[^()]*?>)?\s*\(\s*['"` + "`" + `]([^'"` + "`" + `\s]*)`)
+		for _, match := range run.findAllSubmatch("axiosCall", call, content) {
+			path := match[2]
+			if strings.Contains(path, "://") {
+				continue // Absolute URLs ignore the base URL
+			}
+			if i := strings.Index(path, "${"); i != -1 {
+				path = path[:i]
+			}
+			target := strings.TrimRight(client.BaseURL, "/") + "/" + strings.TrimLeft(path, "/")
+
+			if strings.Contains(target, "://") {
+				if canonical, ok := canonicalURL(target); ok {
+					if url := normalizeURL(canonical, run.opts.URLs); url != "" && !seen[url] {
+						urls = append(urls, url)
+						seen[url] = true
+					}
+				}
+				target = endpointPath(target)
+			}
+			endpoint := normalizeEndpoint(target)
+			if endpoint == "" || endpoint == "/" {
+				continue
+			}
+			if match[1] != "request" {
+				addMethod(methods, endpoint, strings.ToUpper(match[1]))
+			}
+			if !seen[endpoint] {
+				endpoints = append(endpoints, endpoint)
+				seen[endpoint] = true
+			}
+		}
+	}
+	return endpoints, urls, methods
+}
+
+// assignedValue reads a value matched by axiosValue, whose four groups start
+// at group first: a literal, or an expression kept as ${expression}. A
+// literal continued with + ("Bearer " + token) gets a trailing ${...}.
+func assignedValue(s string, loc []int, first int) string {
+	for group := first; group < first+3; group++ {
+		if start := loc[2*group]; start >= 0 {
+			value := s[start:loc[2*group+1]]
+			if rest := strings.TrimLeft(s[loc[1]:], " \t\r\n"); strings.HasPrefix(rest, "+") {
+				value += "${...}"
+			}
+			return value
+		}
+	}
+	if start := loc[2*(first+3)]; start >= 0 {
+		return "${" + s[start:loc[2*(first+3)+1]] + "}"
+	}
+	return ""
+}
+
+// objectLiteral returns the {...} literal opening at content[open], skipping
+// braces in strings; ok is false when it doesn't close within maxObjectLiteral
+func objectLiteral(content string, open int) (string, bool) {
+	depth := 0
+	var quote byte
+	end := min(len(content), open+maxObjectLiteral)
+	for i := open; i < end; i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return content[open : i+1], true
+			}
+		}
+	}
+	return "", false
+}
+
+// Write http-clients.json
+func (a *AggregatedResults) WriteHTTPClients(filePath string) error {
+	output := map[string]interface{}{
+		"jsdumper": CurrentBuildInfo(),
+		"clients":  a.HTTPClients,
+	}
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode HTTP clients: %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write HTTP clients: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package jsdumper
+
+import (
+	"encoding/base64"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Frontends stash keys and URLs base64-encoded, to be decoded with atob()
+// when used, which hides them from every pattern. Long base64 string
+// literals are decoded and the ones holding text scanned again.
+
+// Longest base64 literal decoded; longer ones are embedded fonts and images
+const maxBase64Literal = 64 << 10
+
+// extractBase64 runs the secret, endpoint and URL detectors over the text
+// of base64 string literals, one level deep: decoded text isn't searched
+// for base64 again. Secrets found there are marked base64-encoded and
+// located at their literal; endpoints and URLs found only there are listed
+// in results.Base64Encoded.
+func (e *Extractor) extractBase64(run *extraction, content, fileName string, results *Results) {
+	// Decoded literals are scanned as one document, a line each
+	var decoded strings.Builder
+	var starts, lines []int
+	var index lineIndex
+	for _, loc := range run.findAllSubmatchIndex("base64Literal", e.patterns.Base64Literal, content) {
+		text, ok := decodeBase64Literal(content[loc[2]:loc[3]])
+		if !ok {
+			continue
+		}
+		if index == nil {
+			index = newLineIndex(content)
+		}
+		starts = append(starts, decoded.Len())
+		lines = append(lines, index.line(loc[2]))
+		// Bare values are quoted, as the endpoint patterns expect
+		if !strings.ContainsAny(text, "'\"`") {
+			text = `"` + text + `"`
+		}
+		decoded.WriteString(text)
+		decoded.WriteByte('\n')
+	}
+	if len(starts) == 0 || run.canceled() {
+		return
+	}
+	doc := decoded.String()
+
+	if run.opts.enabled(DetectorSecrets) {
+		known := make(map[string]bool)
+		for _, secret := range results.Secrets {
+			known[secret.Type+":"+secret.Value] = true
+		}
+		var found []Secret
+		for _, secret := range e.extractSecrets(run, doc, fileName) {
+			if known[secret.Type+":"+secret.Value] {
+				continue
+			}
+			secret.Encoding = "base64"
+			if pos := strings.Index(doc, secret.Value); pos != -1 {
+				secret.Line = lines[sort.SearchInts(starts, pos+1)-1]
+			}
+			found = append(found, secret)
+		}
+		analyzeJWTs(found, run.now)
+		results.Secrets = append(results.Secrets, found...)
+	}
+	if run.opts.enabled(DetectorEndpoints) {
+		endpoints, methods := e.extractEndpoints(run, doc)
+		for _, endpoint := range endpoints {
+			if slices.Contains(results.Endpoints, endpoint) {
+				continue
+			}
+			results.Endpoints = append(results.Endpoints, endpoint)
+			results.Base64Encoded = append(results.Base64Encoded, endpoint)
+			for _, method := range methods[endpoint] {
+				addMethod(results.EndpointMethods, endpoint, method)
+			}
+		}
+		results.ImportantEndpoints = e.extractImportantEndpoints(results.Endpoints)
+	}
+	if run.opts.enabled(DetectorURLs) {
+		for _, url := range e.extractURLs(run, doc) {
+			if !slices.Contains(results.URLs, url) {
+				results.URLs = append(results.URLs, url)
+				results.Base64Encoded = append(results.Base64Encoded, url)
+			}
+		}
+	}
+}
+
+// decodeBase64Literal decodes a standard or URL-safe base64 literal, padded
+// or not, and reports whether it holds text
+func decodeBase64Literal(literal string) (string, bool) {
+	if len(literal) > maxBase64Literal {
+		return "", false
+	}
+	encoded := strings.TrimRight(literal, "=")
+	encoding := base64.RawStdEncoding
+	if strings.ContainsAny(encoded, "-_") {
+		if strings.ContainsAny(encoded, "+/") {
+			return "", false
+		}
+		encoding = base64.RawURLEncoding
+	}
+	data, err := encoding.DecodeString(encoded)
+	if err != nil || !isText(data) {
+		return "", false
+	}
+	return string(data), true
+}
+
+// isText reports whether data is UTF-8 without control characters other
+// than whitespace. Identifiers and hashes that happen to be valid base64
+// decode to binary.
+func isText(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if r < 0x20 && r != '\n' && r != '\r' && r != '\t' || r == 0x7f {
+			return false
+		}
+	}
+	return true
+}
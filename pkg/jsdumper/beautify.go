@@ -0,0 +1,308 @@
+package jsdumper
+
+import (
+	"context"
+	"strings"
+)
+
+// Minified bundles put thousands of statements on a line, which defeats line
+// numbers and the patterns that stop at a line end. Beautify breaks them up
+// at statement and block boundaries. It only ever inserts line breaks and
+// indentation, so strings, regular expressions and template literals, and
+// every pattern that allows whitespace between tokens, match as before.
+
+const (
+	minifiedMinSize    = 1000 // Smaller files are left as they are
+	minifiedLineLength = 250  // Average line length from which a file is minified
+	beautifyMaxIndent  = 16   // Deeper blocks are not indented further
+)
+
+// Keywords after which a slash starts a regular expression, not a division
+var regexKeywords = map[string]bool{
+	"return": true, "typeof": true, "case": true, "do": true, "else": true, "in": true, "of": true,
+	"delete": true, "void": true, "throw": true, "new": true, "instanceof": true, "yield": true, "await": true,
+}
+
+// IsMinified reports whether content looks minified: long, with long lines
+func IsMinified(content string) bool {
+	if len(content) < minifiedMinSize {
+		return false
+	}
+	lines := strings.Count(content, "\n") + 1
+	return len(content)/lines >= minifiedLineLength
+}
+
+// Beautify is a Transformer that pretty-prints minified JavaScript, one
+// statement per line with blocks indented; other content is returned as it is
+func Beautify(ctx context.Context, fileName, content string) (string, error) {
+	if !IsMinified(content) || IsHermesBytecode(content) || LooksLikeHTML(content) {
+		return content, nil
+	}
+	b := beautifier{src: content}
+	b.out.Grow(len(content) + len(content)/4)
+	if err := b.run(ctx); err != nil {
+		return content, err
+	}
+	return b.out.String(), nil
+}
+
+type beautifier struct {
+	src    string
+	out    strings.Builder
+	indent int
+	parens []int // Open parentheses, one count per open block
+	last   byte  // Last significant character written, 0 at the start
+	word   string
+}
+
+func (b *beautifier) run(ctx context.Context) error {
+	b.parens = []int{0}
+	for i, steps := 0, 0; i < len(b.src); steps++ {
+		if steps%(1<<16) == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c := b.src[i]
+		switch {
+		case c == '"' || c == '\'':
+			i = b.copyToken(i, skipJSString(b.src, i))
+		case c == '`':
+			i = b.copyToken(i, skipTemplate(b.src, i))
+		case c == '/' && i+1 < len(b.src) && b.src[i+1] == '/':
+			end := strings.IndexByte(b.src[i:], '\n')
+			if end == -1 {
+				end = len(b.src) - i
+			}
+			b.out.WriteString(b.src[i : i+end])
+			i += end
+		case c == '/' && i+1 < len(b.src) && b.src[i+1] == '*':
+			end := strings.Index(b.src[i+2:], "*/")
+			if end == -1 {
+				end = len(b.src) - i - 4
+			}
+			b.out.WriteString(b.src[i : i+end+4])
+			i += end + 4
+		case c == '/' && b.regexAllowed():
+			i = b.copyToken(i, skipRegex(b.src, i))
+		case c == '{':
+			b.write(c)
+			i++
+			if next := b.peek(i); next < len(b.src) && b.src[next] == '}' {
+				b.write('}') // Empty block
+				i = b.endBlock(next + 1)
+				continue
+			}
+			b.parens = append(b.parens, 0)
+			b.indent++
+			i = b.newline(i)
+		case c == '}':
+			if b.indent > 0 {
+				b.indent--
+			}
+			if len(b.parens) > 1 {
+				b.parens = b.parens[:len(b.parens)-1]
+			}
+			// Line breaks before a closing brace leave its indentation to it
+			if !b.atLineStart() {
+				b.out.WriteByte('\n')
+			}
+			b.writeIndent()
+			b.write(c)
+			i = b.endBlock(i + 1)
+		case c == '(':
+			b.parens[len(b.parens)-1]++
+			b.write(c)
+			i++
+		case c == ')':
+			if b.parens[len(b.parens)-1] > 0 {
+				b.parens[len(b.parens)-1]--
+			}
+			b.write(c)
+			i++
+		case c == ';':
+			b.write(c)
+			i++
+			// for (;;) headers stay on their line
+			if b.parens[len(b.parens)-1] == 0 {
+				i = b.newline(i)
+			}
+		case c == '\n':
+			b.out.WriteByte('\n')
+			i = b.peek(i + 1)
+			if i < len(b.src) && b.src[i] == '}' {
+				continue // Indented by the closing brace
+			}
+			b.writeIndent()
+		case c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9':
+			start := i
+			for i < len(b.src) && isIdentByte(b.src[i]) {
+				i++
+			}
+			b.out.WriteString(b.src[start:i])
+			b.last, b.word = 'a', b.src[start:i]
+		default:
+			b.out.WriteByte(c)
+			if c != ' ' && c != '\t' && c != '\r' {
+				b.last, b.word = c, ""
+			}
+			i++
+		}
+	}
+	return nil
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// copyToken writes the literal src[start:end] as it is
+func (b *beautifier) copyToken(start, end int) int {
+	b.out.WriteString(b.src[start:end])
+	b.last, b.word = 'a', ""
+	return end
+}
+
+func (b *beautifier) write(c byte) {
+	b.out.WriteByte(c)
+	b.last, b.word = c, ""
+}
+
+// regexAllowed reports whether a slash here starts a regular expression
+func (b *beautifier) regexAllowed() bool {
+	return regexAllowedAfter(b.last, b.word)
+}
+
+// regexAllowedAfter reports whether a slash starts a regular expression
+// after the last significant character (a for an operand) and word: after
+// an operator, punctuation or keyword rather than an operand
+func regexAllowedAfter(last byte, word string) bool {
+	if last == 0 || strings.IndexByte("(,=:[!&|?{};+-*%<>~^", last) >= 0 {
+		return true
+	}
+	return last == 'a' && regexKeywords[word]
+}
+
+// peek returns the index of the next non-blank character from i
+func (b *beautifier) peek(i int) int {
+	for i < len(b.src) && (b.src[i] == ' ' || b.src[i] == '\t' || b.src[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+// endBlock breaks the line after a closing brace at src[i-1], unless an
+// expression goes on: a call argument, a property access, a list
+func (b *beautifier) endBlock(i int) int {
+	if next := b.peek(i); next < len(b.src) && strings.IndexByte(")],;.", b.src[next]) == -1 {
+		return b.newline(i)
+	}
+	return i
+}
+
+// newline breaks the line before src[i], unless the source already does
+func (b *beautifier) newline(i int) int {
+	next := b.peek(i)
+	if next >= len(b.src) || b.src[next] == '\n' {
+		return i
+	}
+	b.out.WriteByte('\n')
+	if b.src[next] != '}' {
+		b.writeIndent()
+	}
+	return next
+}
+
+func (b *beautifier) atLineStart() bool {
+	s := b.out.String()
+	return s == "" || s[len(s)-1] == '\n'
+}
+
+func (b *beautifier) writeIndent() {
+	b.out.WriteString(strings.Repeat("  ", min(b.indent, beautifyMaxIndent)))
+}
+
+// skipJSString returns the index after the string literal starting at i;
+// unterminated strings end at the line end
+func skipJSString(src string, i int) int {
+	quote := src[i]
+	for i++; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			return i
+		}
+	}
+	return len(src)
+}
+
+// skipTemplate returns the index after the template literal starting at i,
+// stepping over the strings and templates nested in its ${} expressions
+func skipTemplate(src string, i int) int {
+	for i++; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '`':
+			return i + 1
+		case '$':
+			if i+1 < len(src) && src[i+1] == '{' {
+				i = skipExpression(src, i+2) - 1
+			}
+		}
+	}
+	return len(src)
+}
+
+// skipExpression returns the index after the brace closing the template
+// expression that starts at i
+func skipExpression(src string, i int) int {
+	depth := 1
+	for i < len(src) {
+		switch src[i] {
+		case '"', '\'':
+			i = skipJSString(src, i)
+			continue
+		case '`':
+			i = skipTemplate(src, i)
+			continue
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+		i++
+	}
+	return len(src)
+}
+
+// skipRegex returns the index after the regular expression literal starting
+// at i and its flags; character classes may hold unescaped slashes
+func skipRegex(src string, i int) int {
+	inClass := false
+	for i++; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				i++
+				for i < len(src) && isIdentByte(src[i]) {
+					i++
+				}
+				return i
+			}
+		case '\n':
+			return i
+		}
+	}
+	return len(src)
+}
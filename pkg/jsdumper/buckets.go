@@ -0,0 +1,62 @@
+package jsdumper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Bucket is a cloud storage bucket (or Azure container) the code references
+type Bucket struct {
+	Provider string // s3, gcs or azure
+	Name     string // Bucket, or account/container for Azure
+	File     string
+}
+
+// First path segments of storage API URLs, not bucket names
+var storageAPIPaths = map[string]bool{"storage": true, "upload": true, "download": true, "batch": true}
+
+func (e *Extractor) extractBuckets(run *extraction, content, fileName string) []Bucket {
+	var buckets []Bucket
+	seen := make(map[string]bool)
+
+	for _, p := range e.patterns.Buckets {
+		if run.canceled() {
+			break
+		}
+		for _, match := range run.findAllSubmatch(p.name, p.pattern, content) {
+			var parts []string
+			for _, group := range match[1:] {
+				if group != "" {
+					parts = append(parts, strings.ToLower(group))
+				}
+			}
+			name := strings.Join(parts, "/")
+
+			key := p.provider + ":" + name
+			if name == "" || seen[key] || storageAPIPaths[name] {
+				continue
+			}
+			buckets = append(buckets, Bucket{Provider: p.provider, Name: name, File: fileName})
+			seen[key] = true
+		}
+	}
+
+	return buckets
+}
+
+func (a *AggregatedResults) FormatBuckets() []string {
+	sorted := append([]Bucket(nil), a.Buckets...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Provider != sorted[j].Provider {
+			return sorted[i].Provider < sorted[j].Provider
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var lines []string
+	for _, bucket := range sorted {
+		lines = append(lines, fmt.Sprintf("%s | %s | %s", bucket.Provider, bucket.Name, bucket.File))
+	}
+	return lines
+}
@@ -0,0 +1,114 @@
+package jsdumper
+
+import (
+	"context"
+	"regexp"
+	"time"
+)
+
+// Large inputs are matched in overlapping windows so a pattern's time budget
+// can be checked between windows; matches longer than the overlap may be cut
+const (
+	matchWindowSize = 1 << 20
+	// MatchWindowOverlap is also the overlap to keep between the chunks of a
+	// file scanned in pieces
+	MatchWindowOverlap = 8 << 10
+)
+
+// extraction carries the state of one ExtractAll call: cancellation, options,
+// and the patterns that ran out of budget
+type extraction struct {
+	ctx        context.Context
+	opts       *Options
+	now        time.Time // JWT expiry is checked against it, once per extraction
+	overBudget []string
+}
+
+func (r *extraction) canceled() bool {
+	return r.ctx.Err() != nil
+}
+
+// findAllSubmatchIndex is FindAllStringSubmatchIndex bounded by the match
+// limit and per-pattern time budget; name identifies the pattern in stats
+func (r *extraction) findAllSubmatchIndex(name string, pattern *regexp.Regexp, content string) [][]int {
+	limit := r.opts.limit()
+	if len(content) <= matchWindowSize {
+		matches := pattern.FindAllStringSubmatchIndex(content, limit)
+		if limit > 0 && len(matches) >= limit {
+			r.exceeded(name)
+		}
+		return matches
+	}
+
+	started := time.Now()
+	var matches [][]int
+	for start := 0; start < len(content); start += matchWindowSize - MatchWindowOverlap {
+		if r.canceled() {
+			break
+		}
+		if r.opts.PatternTimeout > 0 && time.Since(started) > r.opts.PatternTimeout {
+			r.exceeded(name)
+			break
+		}
+
+		end := start + matchWindowSize
+		last := end >= len(content)
+		if last {
+			end = len(content)
+		}
+		// Matches starting in the overlap belong to the next window
+		boundary := start + matchWindowSize - MatchWindowOverlap
+
+		for _, loc := range pattern.FindAllStringSubmatchIndex(content[start:end], -1) {
+			if !last && start+loc[0] >= boundary {
+				break
+			}
+			for i := range loc {
+				if loc[i] >= 0 {
+					loc[i] += start
+				}
+			}
+			matches = append(matches, loc)
+			if limit > 0 && len(matches) >= limit {
+				r.exceeded(name)
+				return matches
+			}
+		}
+
+		if last {
+			break
+		}
+	}
+	return matches
+}
+
+func (r *extraction) findAllSubmatch(name string, pattern *regexp.Regexp, content string) [][]string {
+	var matches [][]string
+	for _, loc := range r.findAllSubmatchIndex(name, pattern, content) {
+		match := make([]string, len(loc)/2)
+		for i := range match {
+			if loc[2*i] >= 0 {
+				match[i] = content[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+func (r *extraction) findAll(name string, pattern *regexp.Regexp, content string) []string {
+	var matches []string
+	for _, loc := range r.findAllSubmatchIndex(name, pattern, content) {
+		matches = append(matches, content[loc[0]:loc[1]])
+	}
+	return matches
+}
+
+func (r *extraction) exceeded(name string) {
+	for _, existing := range r.overBudget {
+		if existing == name {
+			return
+		}
+	}
+	r.overBudget = append(r.overBudget, name)
+}
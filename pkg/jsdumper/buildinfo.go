@@ -0,0 +1,250 @@
+package jsdumper
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Build metadata, overridden at build time (see release.sh) with
+// -ldflags "-X github.com/d0xng/jsdumper/pkg/jsdumper.version=v1.2.3 ..."
+var (
+	// version is the running release
+	version = "dev"
+	// commit is the git revision built; go build records it on its own
+	commit = ""
+	// buildDate is when the binary was built, RFC 3339
+	buildDate = ""
+	// patternsVersion names the built-in pattern bundle; without it the
+	// bundle is identified by a hash of the patterns
+	patternsVersion = ""
+)
+
+// Version is the running release
+func Version() string {
+	return version
+}
+
+// BuildInfo identifies the binary and the detection patterns behind an
+// output, so consumers can gate on tool or pattern versions. JSON outputs
+// carry it under the "jsdumper" key.
+type BuildInfo struct {
+	Version  string `json:"version"`
+	Commit   string `json:"commit,omitempty"`
+	Date     string `json:"date,omitempty"`
+	Patterns string `json:"patterns"`
+	Go       string `json:"go"`
+	Platform string `json:"platform"`
+}
+
+// CurrentBuildInfo describes the running binary
+func CurrentBuildInfo() BuildInfo {
+	return buildInfo()
+}
+
+var buildInfo = sync.OnceValue(func() BuildInfo {
+	info := BuildInfo{
+		Version:  version,
+		Commit:   commit,
+		Date:     buildDate,
+		Patterns: patternsVersion,
+		Go:       runtime.Version(),
+		Platform: runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	// Plain go build in a checkout stamps the revision
+	if build, ok := debug.ReadBuildInfo(); ok {
+		modified := false
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Commit = cmp.Or(info.Commit, setting.Value)
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if modified && commit == "" && info.Commit != "" {
+			info.Commit += "-dirty"
+		}
+	}
+
+	if info.Patterns == "" {
+		hash := strings.TrimPrefix(NewExtractor().PatternSetHash(), "sha256:")
+		info.Patterns = "sha256:" + hash[:12]
+	}
+	return info
+})
+
+// String is the -version output
+func (b BuildInfo) String() string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "jsdumper %s\n", b.Version)
+	if b.Commit != "" {
+		fmt.Fprintf(&s, "  commit:   %s\n", b.Commit)
+	}
+	if b.Date != "" {
+		fmt.Fprintf(&s, "  built:    %s\n", b.Date)
+	}
+	fmt.Fprintf(&s, "  patterns: %s\n", b.Patterns)
+	fmt.Fprintf(&s, "  go:       %s %s\n", b.Go, b.Platform)
+	return s.String()
+}
+
+// PatternSetHash identifies the detection logic in use: every pattern
+// listed by patternSources, the interesting.txt keywords and the custom
+// rules, in declaration order
+func (e *Extractor) PatternSetHash() string {
+	h := sha256.New()
+	for _, source := range e.patternSources() {
+		io.WriteString(h, source+"\n")
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// patternSources lists the regular expressions findings depend on with their
+// name and settings, one line each. A pattern added to patterns or at package
+// level must be listed here, or the pattern hash doesn't change with it.
+func (e *Extractor) patternSources() []string {
+	var sources []string
+	add := func(name string, re *regexp.Regexp, settings ...string) {
+		sources = append(sources, strings.Join(append([]string{name, re.String()}, settings...), "\t"))
+	}
+	p := e.patterns
+
+	// Secrets
+	for _, pattern := range p.Secrets {
+		add(pattern.name, pattern.pattern, pattern.keyType, pattern.severity)
+	}
+	add("googleAPIKey", p.GoogleAPIKey)
+	add("stripe", p.Stripe)
+	add("braintree", p.Braintree)
+	add("paypal", p.PayPal)
+	add("apiKey", p.APIKey)
+	add("password", p.Password)
+	add("connection", p.Connection)
+	add("fragmentURL", p.FragmentURL)
+	for _, pattern := range p.PushKeys {
+		add(pattern.name, pattern.pattern, pattern.keyType, pattern.severity, pattern.context)
+	}
+	add("pushChannel", p.PushChannel)
+
+	// Endpoints, URLs and script loaders
+	for _, pattern := range p.Endpoints {
+		add(pattern.name, pattern.pattern, strconv.FormatBool(pattern.keepAssets), strconv.FormatBool(pattern.fullURL))
+	}
+	add("url", p.URL)
+	add("hostTemplate", p.HostTemplate)
+	add("endpointMethod", p.EndpointMethod)
+	add("fetchMethod", p.FetchMethod)
+	add("scriptSrc", p.ScriptSrc)
+	add("dynamicImport", p.DynamicImport)
+
+	// Chunks
+	add("chunkNameMap", p.ChunkNameMap)
+	add("chunkMapEntry", p.ChunkMapEntry)
+	add("chunkComment", p.ChunkComment)
+	add("chunkRoute", p.ChunkRoute)
+	add("chunkManifest", p.ChunkManifest)
+
+	for _, pattern := range p.Integrations {
+		add(pattern.name, pattern.pattern, pattern.kind, pattern.provider, strconv.Itoa(pattern.account))
+	}
+	for _, pattern := range p.Buckets {
+		add(pattern.name, pattern.pattern, pattern.provider)
+	}
+	for _, pattern := range p.Infra {
+		add(pattern.name, pattern.pattern, pattern.kind)
+	}
+
+	// GraphQL
+	add("graphQLTag", p.GraphQLTag)
+	add("graphQLString", p.GraphQLString)
+	add("graphQLAST", p.GraphQLAST)
+	add("templateInterpolation", p.TemplateInterpolation)
+	add("persistedManifest", p.PersistedManifest)
+	add("persistedMap", p.PersistedMap)
+	add("persistedRelay", p.PersistedRelay)
+	add("persistedCodegen", p.PersistedCodegen)
+
+	for _, pattern := range p.DOMSinks {
+		add("domSink", pattern.pattern, pattern.sink)
+	}
+	add("webSocketURL", p.WebSocketURL)
+	add("webSocketCall", p.WebSocketCall)
+	add("base64Literal", p.Base64Literal)
+
+	// Axios
+	add("axiosCreate", p.AxiosCreate)
+	add("axiosBaseURL", p.AxiosBaseURL)
+	add("axiosHeaders", p.AxiosHeaders)
+	add("axiosHeader", p.AxiosHeader)
+	add("axiosDefaults", p.AxiosDefaults)
+	add("axiosInterceptor", p.AxiosInterceptor)
+
+	add("ipv4", p.IPv4)
+	add("ipv6", p.IPv6)
+
+	// Security headers and Cloudflare Workers
+	add("cspHeader", p.CSPHeader)
+	add("cspPolicy", p.CSPPolicy)
+	add("corsHeader", p.CORSHeader)
+	add("corsConfig", p.CORSConfig)
+	add("corsOrigin", p.CORSOrigin)
+	add("workerMarker", p.WorkerMarker)
+	add("workerHandler", p.WorkerHandler)
+	add("workerEnv", p.WorkerEnv)
+	for _, pattern := range p.WranglerBindings {
+		add(pattern.name, pattern.pattern, pattern.kind)
+	}
+
+	// Package-level patterns: filters, classifiers and decoders
+	add("integrityNonce", integrityNoncePattern)
+	add("suppression", suppressionPattern)
+	add("apiVersion", apiVersionPattern)
+	add("hostname", hostnamePattern)
+	add("chunkHash", chunkHash)
+	add("chunkHashValue", chunkHashValue)
+	add("webpackChunkFile", webpackChunkFile)
+	add("webpackMapEntry", webpackMapEntry)
+	add("webpackPublicPath", webpackPublicPath)
+	add("cloudRegion", cloudRegion)
+	add("envVariable", envVariable)
+	add("regionVariable", regionVariable)
+	add("secretBindingName", secretBindingName)
+	for _, flow := range accountFlows {
+		add("accountFlow", flow.pattern, flow.flow)
+	}
+	add("stringArrayStart", stringArrayStart)
+	add("stringArrayDecoder", stringArrayDecoder)
+	add("stringArrayShift", stringArrayShift)
+	add("stringArrayChecksum", stringArrayChecksum)
+	add("stringArrayCall", stringArrayCall)
+	add("pdfObjectHeader", pdfObjectHeader)
+	add("pdfNameEscape", pdfNameEscape)
+	add("pdfJSKey", pdfJSKey)
+	add("pdfStreamStart", pdfStreamStart)
+	add("pdfReference", pdfReference)
+	add("pdfFilter", pdfFilter)
+	add("pdfInteger", pdfInteger)
+
+	// Keywords and custom rules
+	for _, keyword := range e.keywords {
+		add("keyword", keyword.pattern, keyword.keyword)
+	}
+	for _, rule := range e.rules {
+		group := ""
+		if rule.Group != nil {
+			group = strconv.Itoa(*rule.Group)
+		}
+		add(rule.Name, rule.pattern, rule.Severity, strconv.FormatFloat(rule.Entropy, 'g', -1, 64), group)
+	}
+	return sources
+}
@@ -0,0 +1,163 @@
+package jsdumper
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ChunkName is the human-readable name of a lazily loaded chunk, from the
+// chunk name maps of bundler runtimes and from route-to-chunk tables. Names
+// alone (admin-billing-export) reveal functionality the page never links to.
+type ChunkName struct {
+	Name  string // e.g. admin-billing-export
+	Route string // Client route loading the chunk, "" when unknown
+	Chunk string // Chunk ID or file, "" when unknown
+	File  string
+}
+
+func (c ChunkName) key() string {
+	return c.Name + "|" + c.Route + "|" + c.Chunk
+}
+
+// Content hashes in chunk file names and hash maps: admin.3f2a1b9c.chunk.js,
+// AdminBilling-4f3a9C_e.js. Only the last name segment is a hash candidate.
+var (
+	chunkHash       = regexp.MustCompile(`[.-]\w{8,20}$`)
+	chunkHashValue  = regexp.MustCompile(`^[0-9a-f]{6,32}$`)
+	chunkExtensions = []string{".chunk.js", ".chunk.mjs", ".js", ".mjs"}
+)
+
+func (e *Extractor) extractChunkNames(run *extraction, content, fileName string) []ChunkName {
+	var chunks []ChunkName
+	seen := make(map[string]bool)
+	add := func(chunk ChunkName) {
+		if chunk.Name == "" && chunk.Route == "" || seen[chunk.key()] {
+			return
+		}
+		chunk.File = fileName
+		chunks = append(chunks, chunk)
+		seen[chunk.key()] = true
+	}
+
+	// Chunk ID -> name maps of webpack runtimes:
+	// "static/js/"+({42:"admin-billing-export"}[e]||e)+"."+{42:"3f2a1b9c"}[e]+".js"
+	names := make(map[string]string)
+	for _, match := range run.findAllSubmatch("chunkNameMap", e.patterns.ChunkNameMap, content) {
+		entries := e.patterns.ChunkMapEntry.FindAllStringSubmatch(match[1], -1)
+		for _, entry := range entries {
+			// The content hash map has the same shape
+			if !chunkHashValue.MatchString(entry[2]) {
+				names[entry[1]] = entry[2]
+			}
+		}
+	}
+	// Names kept from the source: import(/* webpackChunkName: "admin" */ ...)
+	// and the /*! import() | admin */ comments of development builds
+	for _, match := range run.findAllSubmatch("chunkComment", e.patterns.ChunkComment, content) {
+		add(ChunkName{Name: firstGroup(match[1:])})
+	}
+
+	claimed := make(map[string]bool) // Chunk IDs with a route
+
+	// Router tables: { path: "/admin/billing", component: () => import("./AdminBilling-4f3a9c1e.js") }
+	for _, match := range run.findAllSubmatch("chunkRoute", e.patterns.ChunkRoute, content) {
+		route, chunk := match[1], firstGroup(match[2:])
+		name := names[chunk]
+		if name != "" {
+			claimed[chunk] = true
+		} else if strings.ContainsAny(chunk, "./") {
+			name = chunkBaseName(chunk)
+		}
+		add(ChunkName{Name: name, Route: route, Chunk: chunk})
+	}
+
+	// Next.js build manifests: "/admin/billing":["static/chunks/pages/admin/billing-0a1b2c3d.js"]
+	for _, match := range run.findAllSubmatch("chunkManifest", e.patterns.ChunkManifest, content) {
+		add(ChunkName{Name: chunkBaseName(strings.TrimPrefix(match[2], "static/chunks/")), Route: match[1], Chunk: match[2]})
+	}
+
+	// Named chunks no route claimed
+	ids := make([]string, 0, len(names))
+	for id := range names {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if !claimed[id] {
+			add(ChunkName{Name: names[id], Chunk: id})
+		}
+	}
+
+	return chunks
+}
+
+// chunkBaseName is the name of a chunk file without its directory, extension
+// and content hash; Next.js page chunks keep their pages/ path
+func chunkBaseName(file string) string {
+	file = strings.TrimPrefix(file, "./")
+	if !strings.HasPrefix(file, "pages/") && !strings.HasPrefix(file, "app/") {
+		file = path.Base(file)
+	}
+	for _, ext := range chunkExtensions {
+		if strings.HasSuffix(file, ext) {
+			file = strings.TrimSuffix(file, ext)
+			break
+		}
+	}
+	// A hash has digits; admin-settings doesn't
+	if loc := chunkHash.FindStringIndex(file); loc != nil && strings.ContainsAny(file[loc[0]:], "0123456789") {
+		file = file[:loc[0]]
+	}
+	return file
+}
+
+// The chunk file name function of webpack runtimes, from which the URL of
+// every lazily loaded chunk can be rebuilt:
+// "static/js/"+({42:"admin"}[e]||e)+"."+{42:"3f2a1b9c",77:"8d7e6f5a"}[e]+".chunk.js".
+// Groups: directory, ID -> name entries, separator, ID -> hash entries and
+// suffix. The public path chunk files are served under is set apart: r.p="/".
+var (
+	webpackChunkFile  = regexp.MustCompile(`(?:"([^"]*)"\s*\+\s*)?(?:\(\s*\(?\s*\{([^{}]*)\}\s*\)?\s*\[\s*[\w$]+\s*\]\s*\|\|\s*[\w$]+\s*\)|[\w$]+)\s*\+\s*"([^"]*)"\s*\+\s*\(?\s*\{([^{}]*)\}\s*\)?\s*\[\s*[\w$]+\s*\]\s*\+\s*"([^"]*\.m?js)"`)
+	webpackMapEntry   = regexp.MustCompile(`"?([\w.-]+)"?\s*:\s*"([^"\\]*)"`)
+	webpackPublicPath = regexp.MustCompile(`\b[\w$]+\.p\s*=\s*"((?:https?:)?//[^"\s]*|\.?/[^"\s]*)"`)
+)
+
+// WebpackChunkFiles rebuilds the file names of the chunks a webpack runtime
+// loads on demand, relative to its public path; publicPath is "" when the
+// runtime computes it at run time
+func WebpackChunkFiles(content string) (publicPath string, files []string) {
+	seen := make(map[string]bool)
+	for _, match := range webpackChunkFile.FindAllStringSubmatch(content, -1) {
+		names := make(map[string]string)
+		for _, entry := range webpackMapEntry.FindAllStringSubmatch(match[2], -1) {
+			names[entry[1]] = entry[2]
+		}
+		for _, entry := range webpackMapEntry.FindAllStringSubmatch(match[4], -1) {
+			id, hash := entry[1], entry[2]
+			file := match[1] + cmp.Or(names[id], id) + match[3] + hash + match[5]
+			if !seen[file] {
+				files = append(files, file)
+				seen[file] = true
+			}
+		}
+	}
+	if len(files) > 0 {
+		if match := webpackPublicPath.FindStringSubmatch(content); match != nil {
+			publicPath = match[1]
+		}
+	}
+	return publicPath, files
+}
+
+func (a *AggregatedResults) FormatChunkNames() []string {
+	var lines []string
+	for _, chunk := range a.ChunkNames {
+		// "-" stands in for unknown columns
+		lines = append(lines, fmt.Sprintf("%s | %s | %s | %s", cmp.Or(chunk.Name, "-"), cmp.Or(chunk.Route, "-"), cmp.Or(chunk.Chunk, "-"), chunk.File))
+	}
+	return lines
+}
@@ -0,0 +1,132 @@
+package jsdumper
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Some servers compress twice or compress without saying so; stop unwrapping
+// sniffed layers after this many
+const maxSniffedLayers = 3
+
+// decoder is a chain of streaming decompressors over a response body
+type decoder struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (d *decoder) Close() error {
+	for i := len(d.closers) - 1; i >= 0; i-- {
+		d.closers[i].Close()
+	}
+	return nil
+}
+
+// newDecoder decodes body according to its Content-Encoding header, which may
+// list several codings ("gzip, br") applied in order, then unwraps any
+// undeclared compression recognizable by its magic bytes (gzip, zlib, zstd)
+func newDecoder(body io.Reader, contentEncoding string) (io.ReadCloser, error) {
+	d := &decoder{Reader: body}
+
+	codings := strings.Split(strings.ToLower(contentEncoding), ",")
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding := strings.TrimSpace(codings[i])
+		if coding == "" || coding == "identity" {
+			continue
+		}
+		if err := d.push(coding); err != nil {
+			d.Close()
+			return nil, err
+		}
+	}
+
+	for i := 0; i < maxSniffedLayers; i++ {
+		buffered := bufio.NewReader(d.Reader)
+		d.Reader = buffered
+		coding := sniffEncoding(buffered)
+		if coding == "" {
+			break
+		}
+		if err := d.push(coding); err != nil {
+			d.Close()
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+// DecodeBody decodes a response body the way Download does, for responses
+// captured elsewhere (e.g. a proxy history)
+func DecodeBody(body io.Reader, contentEncoding string) (io.ReadCloser, error) {
+	return newDecoder(body, contentEncoding)
+}
+
+// Wrap the current reader in a decompressor for coding
+func (d *decoder) push(coding string) error {
+	switch coding {
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(d.Reader)
+		if err != nil {
+			return fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		d.Reader = reader
+		d.closers = append(d.closers, reader)
+	case "deflate":
+		// "deflate" should be zlib-wrapped, but raw deflate streams are common
+		buffered := bufio.NewReader(d.Reader)
+		if header, err := buffered.Peek(2); err == nil && isZlibHeader(header) {
+			reader, err := zlib.NewReader(buffered)
+			if err != nil {
+				return fmt.Errorf("failed to create zlib reader: %w", err)
+			}
+			d.Reader = reader
+			d.closers = append(d.closers, reader)
+		} else {
+			reader := flate.NewReader(buffered)
+			d.Reader = reader
+			d.closers = append(d.closers, reader)
+		}
+	case "br", "brotli":
+		d.Reader = brotli.NewReader(d.Reader)
+	case "zstd":
+		reader, err := zstd.NewReader(d.Reader)
+		if err != nil {
+			return fmt.Errorf("failed to create zstd reader: %w", err)
+		}
+		d.Reader = reader
+		d.closers = append(d.closers, reader.IOReadCloser())
+	default:
+		return fmt.Errorf("unsupported Content-Encoding %q", coding)
+	}
+	return nil
+}
+
+// Identify compressed data by its magic bytes. Brotli has no magic number,
+// so it is only decoded when declared in Content-Encoding.
+func sniffEncoding(r *bufio.Reader) string {
+	header, _ := r.Peek(4)
+	switch {
+	case len(header) >= 2 && header[0] == 0x1F && header[1] == 0x8B:
+		return "gzip"
+	case len(header) >= 4 && header[0] == 0x28 && header[1] == 0xB5 && header[2] == 0x2F && header[3] == 0xFD:
+		return "zstd"
+	case len(header) >= 2 && header[0] == 0x78 && strings.IndexByte("\x01\x5E\x9C\xDA", header[1]) != -1:
+		// Only the zlib headers real encoders emit; "x " would pass the checksum
+		return "deflate"
+	}
+	return ""
+}
+
+// A zlib header is CMF/FLG with compression method 8 and a valid checksum
+func isZlibHeader(header []byte) bool {
+	return header[0]&0x0F == 8 && header[0]>>4 <= 7 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
@@ -0,0 +1,20 @@
+// Package jsdumper extracts secrets, API endpoints, URLs and other
+// security-relevant artifacts from JavaScript, and aggregates the results of
+// many files. It is the engine of the jsdumper command:
+//
+//	results, err := jsdumper.Scan(content)
+//
+// An Extractor takes options, keywords and custom rules, a Pipeline adds
+// stages before and after extraction, and Aggregate merges the results of
+// several files into deduplicated, sorted findings:
+//
+//	extractor := jsdumper.NewExtractor()
+//	opts := jsdumper.DefaultOptions()
+//	opts.Detectors = []string{jsdumper.DetectorSecrets, jsdumper.DetectorEndpoints}
+//	results, err := extractor.ExtractAll(ctx, content, "app.js", opts)
+//	aggregated := jsdumper.Aggregate([]*jsdumper.Results{results})
+//
+// A Downloader fetches remote scripts with the command's proxy, retry and
+// forbidden-host handling. Extractors and Downloaders are safe for
+// concurrent use.
+package jsdumper
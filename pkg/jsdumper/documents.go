@@ -0,0 +1,64 @@
+package jsdumper
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxDecompressedSize caps what a single stream of a document may decompress
+// to, so a small crafted file (a zip or Flate bomb) can't exhaust memory
+const maxDecompressedSize = 64 << 20
+
+// EmbeddedScript is a script carried by a document
+type EmbeddedScript struct {
+	Name    string // Where it is in the document: obj12 in a PDF, the VBA module in an Office document
+	Content string
+}
+
+// DocumentScripts returns the scripts embedded in a document: the JavaScript
+// of a PDF, or the VBA macros of an Office document, legacy (.doc, .xls,
+// .ppt) or Office Open XML (.docm, .xlsm, .pptm)
+func DocumentScripts(content string) ([]EmbeddedScript, error) {
+	switch {
+	case IsPDF(content):
+		return PDFScripts(content)
+	case IsOLE(content):
+		return VBAScripts(content)
+	case strings.HasPrefix(content, "PK\x03\x04"):
+		return officeXMLScripts(content)
+	}
+	return nil, fmt.Errorf("not a PDF or Office document")
+}
+
+// officeXMLScripts returns the VBA macros of an Office Open XML document,
+// kept in vbaProject.bin (word/, xl/ or ppt/)
+func officeXMLScripts(content string) ([]EmbeddedScript, error) {
+	archive, err := zip.NewReader(strings.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Office document: %w", err)
+	}
+	var scripts []EmbeddedScript
+	for _, file := range archive.File {
+		if !strings.EqualFold(path.Base(file.Name), "vbaProject.bin") {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		project, err := io.ReadAll(io.LimitReader(reader, maxDecompressedSize))
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		modules, err := VBAScripts(string(project))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		scripts = append(scripts, modules...)
+	}
+	return scripts, nil
+}
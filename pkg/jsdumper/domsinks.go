@@ -0,0 +1,80 @@
+package jsdumper
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DOMSink is a use of an API that turns a string into HTML or code, the
+// endpoint of a DOM XSS if attacker-controlled data reaches it
+type DOMSink struct {
+	Sink    string // innerHTML, document.write, eval, ...
+	File    string
+	Line    int
+	Context string
+}
+
+// domSinkPattern flags one sink; a non-empty group 1 marks a harmless use
+// (clearing innerHTML, the Function("return this") global lookup)
+type domSinkPattern struct {
+	sink    string
+	pattern *regexp.Regexp
+}
+
+func (e *Extractor) extractDOMSinks(run *extraction, content, fileName string) []DOMSink {
+	var sinks []DOMSink
+	seen := make(map[string]bool)
+	lines := newLineIndex(content)
+
+	// Enough code around the sink to see what flows into it
+	const contextSize = 60
+
+	for _, p := range e.patterns.DOMSinks {
+		if run.canceled() {
+			break
+		}
+		for _, loc := range run.findAllSubmatchIndex(p.sink, p.pattern, content) {
+			if len(loc) > 2 && loc[2] >= 0 {
+				continue
+			}
+			context := contextAround(content, loc[0], loc[1], contextSize)
+			key := p.sink + ":" + context
+			if !seen[key] {
+				sinks = append(sinks, DOMSink{
+					Sink:    p.sink,
+					File:    fileName,
+					Line:    lines.line(loc[0]),
+					Context: context,
+				})
+				seen[key] = true
+			}
+		}
+	}
+
+	sort.SliceStable(sinks, func(i, j int) bool { return sinks[i].Line < sinks[j].Line })
+	return sinks
+}
+
+func (a *AggregatedResults) FormatDOMSinks() []string {
+	var lines []string
+	for _, sink := range a.DOMSinks {
+		lines = append(lines, fmt.Sprintf("%s | %s:%d | %s", sink.Sink, sink.File, sink.Line, sink.Context))
+	}
+	return lines
+}
+
+// contextAround returns the match at content[start:end] with up to size bytes
+// on each side, whitespace collapsed so it fits on one line
+func contextAround(content string, start, end, size int) string {
+	start -= size
+	if start < 0 {
+		start = 0
+	}
+	end += size
+	if end > len(content) {
+		end = len(content)
+	}
+	return strings.Join(strings.Fields(strings.ToValidUTF8(content[start:end], "")), " ")
+}
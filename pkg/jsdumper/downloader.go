@@ -0,0 +1,350 @@
+package jsdumper
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	urlpkg "net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+type Downloader struct {
+	client     *http.Client
+	headers    http.Header
+	retries    int
+	retryDelay time.Duration
+
+	jar         *sessionJar
+	sessionFile string
+	policy      *hostPolicy
+}
+
+// DownloaderConfig holds network settings for remote downloads
+type DownloaderConfig struct {
+	// Proxy is an http://, https://, socks5:// or socks5h:// proxy URL.
+	// When empty, HTTP_PROXY/HTTPS_PROXY/NO_PROXY from the environment apply.
+	Proxy string
+	// Insecure skips TLS certificate verification (e.g. behind Burp without its CA installed)
+	Insecure bool
+	// Headers are extra "Name: value" request headers; they override the browser defaults
+	Headers []string
+	// Cookies are sent as a single Cookie header, e.g. "session=abc"
+	Cookies []string
+	// Retries is how often a transient failure (timeout, 429, 5xx) is retried;
+	// the wait starts at RetryDelay and doubles with each attempt
+	Retries    int
+	RetryDelay time.Duration
+	// SessionFile persists the cookie jar across runs (empty = this run only)
+	SessionFile string
+	// ForbidHosts are host names, IPs or CIDR ranges never contacted, on top
+	// of the cloud metadata defaults
+	ForbidHosts []string
+	// Record saves every response to this fixtures directory; Replay serves
+	// responses from one instead of the network
+	Record string
+	Replay string
+}
+
+// maxRedirects is how many redirects a download follows, like net/http's default
+const maxRedirects = 10
+
+var errTooManyRedirects = fmt.Errorf("stopped after %d redirects", maxRedirects)
+
+// statusError is a non-200 response
+type statusError struct {
+	StatusCode int
+	Status     string
+	RetryAfter time.Duration // From the Retry-After header, if any
+	SetCookie  bool          // The response set cookies, e.g. an anti-bot challenge
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Status)
+}
+
+func NewDownloader(config DownloaderConfig) (*Downloader, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if config.Proxy != "" {
+		proxyURL, err := urlpkg.Parse(config.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		switch proxyURL.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q (expected http, https, socks5 or socks5h)", proxyURL.Scheme)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	policy, err := newHostPolicy(config.ForbidHosts)
+	if err != nil {
+		return nil, err
+	}
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   policy.dialControl,
+	}).DialContext
+
+	if config.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	headers := make(http.Header)
+	for _, header := range config.Headers {
+		name, value, ok := strings.Cut(header, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header %q (expected \"Name: value\")", header)
+		}
+		headers.Add(name, strings.TrimSpace(value))
+	}
+	if len(config.Cookies) > 0 {
+		headers.Add("Cookie", strings.Join(config.Cookies, "; "))
+	}
+
+	var roundTripper http.RoundTripper = transport
+	switch {
+	case config.Replay != "":
+		if roundTripper, err = newReplayTransport(config.Replay); err != nil {
+			return nil, err
+		}
+	case config.Record != "":
+		if roundTripper, err = newRecordTransport(transport, config.Record); err != nil {
+			return nil, err
+		}
+	}
+
+	jar := newSessionJar()
+	if config.SessionFile != "" {
+		if err := jar.Load(config.SessionFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Downloader{
+		headers:     headers,
+		retries:     config.Retries,
+		retryDelay:  config.RetryDelay,
+		jar:         jar,
+		sessionFile: config.SessionFile,
+		policy:      policy,
+		client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: roundTripper,
+			Jar:       jar,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxRedirects {
+					return errTooManyRedirects
+				}
+				return policy.checkURL(req.URL.String()) // Follow redirects, except to forbidden hosts
+			},
+		},
+	}, nil
+}
+
+// Close saves the session cookies when a session file is configured
+func (d *Downloader) Close() error {
+	if d.sessionFile == "" {
+		return nil
+	}
+	return d.jar.Save(d.sessionFile)
+}
+
+// Download fetches url into outputPath, retrying transient failures
+func (d *Downloader) Download(url, outputPath string) error {
+	return d.DownloadContext(context.Background(), url, outputPath)
+}
+
+// DownloadContext is Download, abandoned (retries included) when ctx is done
+func (d *Downloader) DownloadContext(ctx context.Context, url, outputPath string) error {
+	if err := d.policy.checkURL(url); err != nil {
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		err := d.download(ctx, url, outputPath, 0)
+		if err == nil || attempt >= d.retries || !isTransient(err) || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-time.After(d.backoff(attempt, err)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Wait before retry number attempt+1: exponential, or the server's Retry-After if longer
+func (d *Downloader) backoff(attempt int, err error) time.Duration {
+	delay := d.retryDelay << attempt
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > delay {
+		delay = statusErr.RetryAfter
+		if delay > time.Minute {
+			delay = time.Minute
+		}
+	}
+	return delay
+}
+
+// Timeouts, dropped connections, rate limiting and server errors are worth
+// retrying, as are 403 refusals that came with a cookie for the next attempt
+// (anti-bot challenges; 503 ones are server errors)
+func isTransient(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500 ||
+			statusErr.SetCookie && statusErr.StatusCode == http.StatusForbidden
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// download fetches url, following the redirects the client hands back
+// (300, or 304 with a Location) itself; redirects counts the hops so far
+func (d *Downloader) download(ctx context.Context, url, outputPath string, redirects int) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set browser-like headers
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br, zstd")
+	req.Header.Set("Connection", "keep-alive")
+	req.Header.Set("Cache-Control", "max-age=0")
+
+	// User-supplied headers and cookies
+	for name, values := range d.headers {
+		if strings.EqualFold(name, "Host") {
+			req.Host = values[0]
+			continue
+		}
+		req.Header[name] = values
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Handle redirects manually if needed
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 && resp.Header.Get("Location") != "" {
+		redirectURL := resp.Header.Get("Location")
+		// Handle relative redirects
+		if !strings.HasPrefix(redirectURL, "http") {
+			if strings.HasPrefix(redirectURL, "/") {
+				u, err := urlpkg.Parse(url)
+				if err == nil {
+					redirectURL = u.Scheme + "://" + u.Host + redirectURL
+				}
+			} else {
+				baseURL := url
+				if lastSlash := strings.LastIndex(baseURL, "/"); lastSlash != -1 {
+					baseURL = baseURL[:lastSlash+1]
+				}
+				redirectURL = baseURL + redirectURL
+			}
+		}
+		if redirects >= maxRedirects {
+			return errTooManyRedirects
+		}
+		if err := d.policy.checkURL(redirectURL); err != nil {
+			return err
+		}
+		return d.download(ctx, redirectURL, outputPath, redirects+1)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		statusErr := &statusError{StatusCode: resp.StatusCode, Status: resp.Status, SetCookie: len(resp.Cookies()) > 0}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			statusErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return statusErr
+	}
+
+	// Create output file
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	// Decompress while writing, whatever the server declared or forgot to declare
+	body := &errorReader{Reader: resp.Body}
+	decoder, err := newDecoder(body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		if body.err != nil {
+			return fmt.Errorf("failed to read response: %w", body.err)
+		}
+		return &PartialError{Kind: WarningDecompression, Err: err}
+	}
+	defer decoder.Close()
+
+	// Copy to file, keeping what was decoded when the response breaks off
+	reader := &errorReader{Reader: decoder}
+	if _, err := io.Copy(file, reader); err != nil {
+		switch {
+		case reader.err == nil:
+			return fmt.Errorf("failed to write file: %w", err)
+		case errors.Is(body.err, io.ErrUnexpectedEOF):
+			return &PartialError{Kind: WarningTruncated, Err: body.err}
+		case body.err != nil:
+			return fmt.Errorf("failed to read response: %w", body.err)
+		}
+		return &PartialError{Kind: WarningDecompression, Err: reader.err}
+	}
+
+	return nil
+}
+
+// PartialError is returned by Download when the response was saved only in
+// part: the file holds what could be decoded, and Kind (WarningTruncated or
+// WarningDecompression) says why the rest is missing
+type PartialError struct {
+	Kind string
+	Err  error
+}
+
+func (e *PartialError) Error() string {
+	if e.Kind == WarningTruncated {
+		return fmt.Sprintf("response truncated: %v", e.Err)
+	}
+	return fmt.Sprintf("failed to decompress response: %v", e.Err)
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// errorReader remembers the error its reader failed with, to tell a broken
+// response body from a broken compressed stream
+type errorReader struct {
+	io.Reader
+	err error
+}
+
+func (r *errorReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,169 @@
+package jsdumper
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Strings can spell secrets and URLs with escape sequences, "\x68ttps://"
+// or "\u0041IzaSy...", which no pattern matches. DecodeEscapes writes the
+// characters of the \x, \u and \u{} escapes of string and template literals
+// instead. Escapes of characters that would end or change the literal
+// (quotes, backslashes, $, line terminators and other control characters)
+// are kept, so the code around the literals and its lines stay the same.
+
+// DecodeEscapes is a Transformer that decodes the hexadecimal and Unicode
+// escapes inside string and template literals. Comments, regular
+// expressions and identifiers are left as they are.
+func DecodeEscapes(ctx context.Context, fileName, content string) (string, error) {
+	if !strings.Contains(content, `\x`) && !strings.Contains(content, `\u`) {
+		return content, nil
+	}
+
+	var out strings.Builder
+	copied := 0 // content[copied:] is not written yet
+	last, word := byte(0), ""
+	for i, steps := 0, 0; i < len(content); steps++ {
+		if steps%(1<<16) == 0 && ctx.Err() != nil {
+			return content, ctx.Err()
+		}
+		c := content[i]
+		switch {
+		case isBlank(c):
+			i++
+			continue
+		case c == '"' || c == '\'' || c == '`':
+			end := skipJSString(content, i)
+			if c == '`' {
+				end = skipTemplate(content, i)
+			}
+			if decoded, ok := decodeLiteralEscapes(content[i:end]); ok {
+				out.WriteString(content[copied:i])
+				out.WriteString(decoded)
+				copied = end
+			}
+			i, last, word = end, 'a', ""
+			continue
+		case c == '/' && i+1 < len(content) && content[i+1] == '/':
+			if next := strings.IndexByte(content[i:], '\n'); next != -1 {
+				i += next
+			} else {
+				i = len(content)
+			}
+			continue
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			if next := strings.Index(content[i+2:], "*/"); next != -1 {
+				i += next + 4
+			} else {
+				i = len(content)
+			}
+			continue
+		case c == '/' && regexAllowedAfter(last, word):
+			i, last, word = skipRegex(content, i), 'a', ""
+			continue
+		case c == '\\' || isIdentByte(c):
+			// Identifiers may be escaped too: \u0061lert
+			start := i
+			for i < len(content) && (isIdentByte(content[i]) || content[i] == '\\') {
+				i++
+			}
+			last, word = 'a', content[start:i]
+			continue
+		}
+		last, word = c, ""
+		i++
+	}
+	if copied == 0 {
+		return content, nil
+	}
+	out.WriteString(content[copied:])
+	return out.String(), nil
+}
+
+// decodeLiteralEscapes returns literal with the escapes it can write as
+// characters decoded, and whether there were any
+func decodeLiteralEscapes(literal string) (string, bool) {
+	if !strings.Contains(literal, `\x`) && !strings.Contains(literal, `\u`) {
+		return literal, false
+	}
+	var b strings.Builder
+	decoded := false
+	for i := 0; i < len(literal); {
+		if literal[i] != '\\' || i+1 == len(literal) {
+			b.WriteByte(literal[i])
+			i++
+			continue
+		}
+		r, next, ok := escapeAt(literal, i)
+		if !ok || !isLiteralSafe(r) {
+			// Kept, with the escaped character: \\x41 is a backslash and x41
+			b.WriteString(literal[i : i+2])
+			i += 2
+			continue
+		}
+		b.WriteRune(r)
+		i, decoded = next, true
+	}
+	return b.String(), decoded
+}
+
+// escapeAt decodes the \x, \u or \u{} escape whose backslash is at s[i],
+// a surrogate pair written as two \u escapes included, and returns its
+// character and the index after it
+func escapeAt(s string, i int) (rune, int, bool) {
+	if i+1 >= len(s) {
+		return 0, i, false
+	}
+	switch s[i+1] {
+	case 'x':
+		if i+4 > len(s) {
+			return 0, i, false
+		}
+		n, err := strconv.ParseUint(s[i+2:i+4], 16, 8)
+		return rune(n), i + 4, err == nil
+	case 'u':
+		if i+2 < len(s) && s[i+2] == '{' {
+			end := strings.IndexByte(s[i+3:min(len(s), i+11)], '}')
+			if end <= 0 {
+				return 0, i, false
+			}
+			n, err := strconv.ParseUint(s[i+3:i+3+end], 16, 32)
+			if err != nil || n > 0x10FFFF || utf16.IsSurrogate(rune(n)) {
+				return 0, i, false
+			}
+			return rune(n), i + 4 + end, true
+		}
+		if i+6 > len(s) {
+			return 0, i, false
+		}
+		n, err := strconv.ParseUint(s[i+2:i+6], 16, 16)
+		if err != nil {
+			return 0, i, false
+		}
+		r := rune(n)
+		if !utf16.IsSurrogate(r) {
+			return r, i + 6, true
+		}
+		// A high surrogate is only a character with the low one after it
+		if i+12 <= len(s) && s[i+6] == '\\' && s[i+7] == 'u' {
+			low, err := strconv.ParseUint(s[i+8:i+12], 16, 16)
+			if pair := utf16.DecodeRune(r, rune(low)); err == nil && pair != utf8.RuneError {
+				return pair, i + 12, true
+			}
+		}
+	}
+	return 0, i, false
+}
+
+// isLiteralSafe reports whether r can be written as it is inside any string
+// or template literal without changing it
+func isLiteralSafe(r rune) bool {
+	switch r {
+	case '"', '\'', '`', '\\', '$', 0x7f, '\u2028', '\u2029':
+		return false
+	}
+	return r >= 0x20
+}
@@ -0,0 +1,178 @@
+package jsdumper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ConfigExposure is security configuration set from JavaScript, typically in
+// server-side or edge bundles: a Content-Security-Policy or CORS headers
+type ConfigExposure struct {
+	Kind       string              `json:"-"` // csp or cors
+	Header     string              `json:"header,omitempty"`
+	Value      string              `json:"value"`
+	Directives map[string][]string `json:"directives,omitempty"` // CSP directive -> sources
+	Origins    []string            `json:"origins,omitempty"`    // Allowed origins and hosts
+	Issues     []string            `json:"issues,omitempty"`     // Weaknesses worth a look
+	File       string              `json:"file"`
+}
+
+// CSP source keywords and schemes, as opposed to origins
+var cspKeywordSources = []string{"'", "data:", "blob:", "filesystem:", "mediastream:"}
+
+func (e *Extractor) extractConfigExposures(run *extraction, content, fileName string) []ConfigExposure {
+	var exposures []ConfigExposure
+	seen := make(map[string]bool)
+
+	add := func(exposure ConfigExposure) {
+		key := exposure.Kind + ":" + exposure.Header + ":" + exposure.Value
+		if exposure.Value != "" && !seen[key] {
+			exposure.File = fileName
+			exposures = append(exposures, exposure)
+			seen[key] = true
+		}
+	}
+
+	// Content-Security-Policy headers, then bare policy strings
+	for _, match := range run.findAllSubmatch("cspHeader", e.patterns.CSPHeader, content) {
+		add(parseCSP(match[1], firstGroup(match[2:])))
+	}
+	for _, match := range run.findAllSubmatch("cspPolicy", e.patterns.CSPPolicy, content) {
+		policy := strings.TrimSpace(match[1])
+		if !seen["csp:Content-Security-Policy:"+policy] && !seen["csp:Content-Security-Policy-Report-Only:"+policy] {
+			add(parseCSP("", policy))
+		}
+	}
+
+	if run.canceled() {
+		return exposures
+	}
+
+	// CORS response headers; a non-literal Allow-Origin may reflect the request
+	credentials := false
+	var cors []ConfigExposure
+	for _, match := range run.findAllSubmatch("corsHeader", e.patterns.CORSHeader, content) {
+		header := match[1]
+		value := firstGroup(match[2:5])
+		exposure := ConfigExposure{Kind: "cors", Header: header, Value: value}
+		if value == "" && match[5] != "" {
+			exposure.Value = match[5]
+			if strings.EqualFold(header, "Access-Control-Allow-Origin") {
+				exposure.Issues = append(exposure.Issues, "origin computed at runtime, check for reflection")
+			}
+		}
+		switch strings.ToLower(header) {
+		case "access-control-allow-origin":
+			if value != "" {
+				exposure.Origins = splitList(value)
+			}
+		case "access-control-allow-credentials":
+			credentials = credentials || strings.EqualFold(value, "true")
+		}
+		cors = append(cors, exposure)
+	}
+
+	// cors({ origin: ..., credentials: true }) middleware options
+	for _, match := range run.findAllSubmatch("corsConfig", e.patterns.CORSConfig, content) {
+		options := match[1]
+		exposure := ConfigExposure{Kind: "cors", Header: "cors()", Value: strings.Join(strings.Fields(options), " ")}
+		for _, origin := range e.patterns.CORSOrigin.FindAllStringSubmatch(options, -1) {
+			exposure.Origins = append(exposure.Origins, firstGroup(origin[1:]))
+		}
+		if strings.Contains(strings.ReplaceAll(options, " ", ""), "origin:true") {
+			exposure.Issues = append(exposure.Issues, "origin: true reflects any requesting origin")
+		}
+		if strings.Contains(strings.ReplaceAll(options, " ", ""), "credentials:true") {
+			exposure.Issues = append(exposure.Issues, "credentials allowed")
+		}
+		cors = append(cors, exposure)
+	}
+
+	for _, exposure := range cors {
+		if credentials && strings.EqualFold(exposure.Header, "Access-Control-Allow-Origin") {
+			if exposure.Value == "*" {
+				exposure.Issues = append(exposure.Issues, "wildcard origin with Access-Control-Allow-Credentials: true")
+			} else if len(exposure.Issues) > 0 {
+				exposure.Issues = append(exposure.Issues, "runtime origin with Access-Control-Allow-Credentials: true")
+			}
+		}
+		add(exposure)
+	}
+
+	return exposures
+}
+
+// Parse a policy into its directives and origins, flagging weak sources
+func parseCSP(header, policy string) ConfigExposure {
+	exposure := ConfigExposure{Kind: "csp", Header: header, Value: strings.TrimSpace(policy), Directives: make(map[string][]string)}
+	seenOrigins := make(map[string]bool)
+
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		sources := fields[1:]
+		exposure.Directives[name] = sources
+
+		scriptPolicy := name == "script-src" || name == "default-src"
+		for _, source := range sources {
+			lower := strings.ToLower(source)
+			switch {
+			case scriptPolicy && lower == "'unsafe-inline'":
+				exposure.Issues = append(exposure.Issues, name+" allows 'unsafe-inline'")
+			case scriptPolicy && lower == "'unsafe-eval'":
+				exposure.Issues = append(exposure.Issues, name+" allows 'unsafe-eval'")
+			case scriptPolicy && (lower == "*" || lower == "https:" || lower == "http:"):
+				exposure.Issues = append(exposure.Issues, name+" allows any host ("+source+")")
+			}
+
+			isKeyword := lower == "*" || strings.HasSuffix(lower, ":") && !strings.Contains(lower, "/")
+			for _, prefix := range cspKeywordSources {
+				isKeyword = isKeyword || strings.HasPrefix(lower, prefix)
+			}
+			if !isKeyword && !seenOrigins[source] && name != "report-uri" && name != "report-to" {
+				exposure.Origins = append(exposure.Origins, source)
+				seenOrigins[source] = true
+			}
+		}
+	}
+	return exposure
+}
+
+// The first non-empty group, for patterns with one alternative per quote style
+func firstGroup(groups []string) string {
+	for _, group := range groups {
+		if group != "" {
+			return group
+		}
+	}
+	return ""
+}
+
+// Write config-exposure.json, grouping exposures by kind
+func (a *AggregatedResults) WriteConfigExposures(filePath string) error {
+	groups := map[string][]ConfigExposure{
+		"csp":  {},
+		"cors": {},
+	}
+	for _, exposure := range a.ConfigExposures {
+		groups[exposure.Kind] = append(groups[exposure.Kind], exposure)
+	}
+
+	output := map[string]interface{}{"jsdumper": CurrentBuildInfo()}
+	for kind, group := range groups {
+		output[kind] = group
+	}
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config exposures: %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config exposures: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,749 @@
+package jsdumper
+
+import (
+	"context"
+	urlpkg "net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type Results struct {
+	File               string
+	Secrets            []Secret
+	Endpoints          []string
+	EndpointMethods    map[string][]string // HTTP methods the code uses per endpoint
+	ImportantEndpoints []string
+	URLs               []string
+	HostTemplates      []string // Hostnames built from an env/region variable, e.g. api-{region}.acme.io
+	WebSockets         []string
+	Interesting        []Interesting
+	Integrations       []Integration
+	Buckets            []Bucket
+	InfraReferences    []InfraReference
+	ConfigExposures    []ConfigExposure
+	Bindings           []Binding
+	IPs                []IPAddress
+	GraphQL            []GraphQLOperation
+	PersistedQueries   []PersistedQuery
+	DOMSinks           []DOMSink
+	HTTPClients        []HTTPClient
+	ChunkNames         []ChunkName
+	Base64Encoded      []string  // Endpoints and URLs only found in base64 literals
+	Suppressed         int       // Secrets dropped by jsdumper-ignore annotations
+	SuppressedTypes    []string  // Type of each suppressed secret
+	OverBudget         []string  // Patterns stopped by the match/time budget
+	Warnings           []Warning // Data-quality issues with the input, see Warning
+	Tags               []string  // Options.Tags of the scan
+}
+
+type Secret struct {
+	Type     string
+	File     string
+	Value    string
+	Severity string
+	Line     int      // Line of the first occurrence (0 = unknown)
+	Detail   string   // What to look at in the value, e.g. a connection string's credentials
+	JWT      *JWTInfo // Decoded header and claims of JWTs
+	Encoding string   // "base64" when found in a base64 literal, "" in plain sight
+}
+
+// Interesting is a keyword hit kept for manual review, outside the secret detectors
+type Interesting struct {
+	Keyword string
+	File    string
+	Context string
+}
+
+// Default keywords for interesting.txt, overridable with -keywords
+var defaultKeywords = []string{
+	"internal use only",
+	"do not ship",
+	"backdoor",
+	"secret",
+	"staging",
+}
+
+type Extractor struct {
+	patterns *patterns
+	keywords []keywordPattern
+	rules    []Rule // User-defined patterns from -rules
+	fast     func() *fastPass
+}
+
+// keywordPattern is a case-insensitive literal match for interesting.txt
+type keywordPattern struct {
+	keyword string
+	pattern *regexp.Regexp
+}
+
+func NewExtractor() *Extractor {
+	e := &Extractor{patterns: newPatterns()}
+	e.fast = sync.OnceValue(func() *fastPass { return newFastPass(e.patterns) })
+	e.SetKeywords(defaultKeywords)
+	return e
+}
+
+// Shared by Scan, so the patterns are compiled once
+var defaultExtractor = sync.OnceValue(NewExtractor)
+
+// Scan extracts from content with the default options and keywords
+func Scan(content string) (*Results, error) {
+	return defaultExtractor().ExtractAll(context.Background(), content, "", DefaultOptions())
+}
+
+// SetKeywords replaces the interesting.txt keywords, compiling them once
+func (e *Extractor) SetKeywords(keywords []string) {
+	e.keywords = nil
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			continue
+		}
+		e.keywords = append(e.keywords, keywordPattern{
+			keyword: keyword,
+			pattern: regexp.MustCompile(`(?i)` + regexp.QuoteMeta(keyword)),
+		})
+	}
+}
+
+// SetRules replaces the custom secret patterns (see LoadRules)
+func (e *Extractor) SetRules(rules []Rule) {
+	e.rules = rules
+}
+
+// ExtractAll runs the enabled detectors over content. Extraction stops early
+// when ctx is canceled, returning the partial results together with ctx.Err().
+func (e *Extractor) ExtractAll(ctx context.Context, content, fileName string, opts Options) (*Results, error) {
+	results := &Results{File: fileName, Tags: opts.Tags}
+	run := &extraction{ctx: ctx, opts: &opts, now: opts.now()}
+
+	if opts.Fast {
+		e.extractFast(run, content, fileName, results)
+		results.OverBudget = run.overBudget
+		return results, ctx.Err()
+	}
+
+	// HTTP clients are read first: the headers they send may be secrets, and
+	// paths requested through axios instances are resolved against their base URL
+	var clients []HTTPClient
+	if opts.enabled(DetectorHTTPClients) || opts.enabled(DetectorSecrets) || opts.enabled(DetectorEndpoints) || opts.enabled(DetectorURLs) {
+		clients = e.extractHTTPClients(run, content, fileName)
+		if opts.enabled(DetectorHTTPClients) {
+			results.HTTPClients = clients
+		}
+	}
+
+	if opts.enabled(DetectorSecrets) {
+		results.Secrets = e.extractSecrets(run, content, fileName)
+		results.Secrets = append(results.Secrets, e.headerSecrets(run, clients, fileName, results.Secrets)...)
+		finishSecrets(run, content, results)
+	}
+	if opts.enabled(DetectorEndpoints) {
+		results.Endpoints, results.EndpointMethods = e.extractEndpoints(run, foldConstants(content))
+		results.ImportantEndpoints = e.extractImportantEndpoints(results.Endpoints)
+	}
+	if opts.enabled(DetectorURLs) {
+		results.URLs = e.extractURLs(run, content)
+		results.HostTemplates = e.extractHostTemplates(run, content)
+	}
+	if opts.enabled(DetectorEndpoints) || opts.enabled(DetectorURLs) {
+		endpoints, urls, methods := e.clientEndpoints(run, content, clients)
+		if opts.enabled(DetectorEndpoints) {
+			for _, endpoint := range endpoints {
+				if !slices.Contains(results.Endpoints, endpoint) {
+					results.Endpoints = append(results.Endpoints, endpoint)
+				}
+				for _, method := range methods[endpoint] {
+					addMethod(results.EndpointMethods, endpoint, method)
+				}
+			}
+			results.ImportantEndpoints = e.extractImportantEndpoints(results.Endpoints)
+		}
+		if opts.enabled(DetectorURLs) {
+			for _, url := range urls {
+				if !slices.Contains(results.URLs, url) {
+					results.URLs = append(results.URLs, url)
+				}
+			}
+		}
+	}
+	if opts.enabled(DetectorSecrets) || opts.enabled(DetectorEndpoints) || opts.enabled(DetectorURLs) {
+		e.extractBase64(run, content, fileName, results)
+	}
+	if opts.enabled(DetectorWebSockets) {
+		results.WebSockets = e.extractWebSockets(run, content)
+	}
+	if opts.enabled(DetectorInteresting) {
+		results.Interesting = e.extractInteresting(run, content, fileName)
+		// Channels only matter next to a key; without the secrets detector, the
+		// push keys are looked up (and filtered the same way) for this alone
+		keys := results.Secrets
+		if !opts.enabled(DetectorSecrets) {
+			keys = filterIntegritySecrets(content, filterPlaceholderSecrets(e.extractPushKeys(run, content, fileName)))
+		}
+		results.Interesting = append(results.Interesting, e.extractPushChannels(run, content, fileName, keys)...)
+	}
+	if opts.enabled(DetectorURLs) || opts.enabled(DetectorInteresting) {
+		loaded, computed := e.extractScriptLoaders(run, content, fileName)
+		if opts.enabled(DetectorURLs) {
+			for _, url := range loaded {
+				if !slices.Contains(results.URLs, url) {
+					results.URLs = append(results.URLs, url)
+				}
+			}
+		}
+		if opts.enabled(DetectorInteresting) {
+			results.Interesting = append(results.Interesting, computed...)
+		}
+	}
+	if opts.enabled(DetectorIntegrations) {
+		results.Integrations = e.extractIntegrations(run, content, fileName)
+	}
+	if opts.enabled(DetectorBuckets) {
+		results.Buckets = e.extractBuckets(run, content, fileName)
+	}
+	if opts.enabled(DetectorInfra) {
+		results.InfraReferences = e.extractInfraReferences(run, content, fileName)
+	}
+	if opts.enabled(DetectorConfig) {
+		results.ConfigExposures = e.extractConfigExposures(run, content, fileName)
+	}
+	if opts.enabled(DetectorBindings) {
+		results.Bindings = e.extractBindings(run, content, fileName)
+	}
+	if opts.enabled(DetectorIPs) {
+		results.IPs = e.extractIPs(run, content, fileName)
+	}
+	if opts.enabled(DetectorGraphQL) {
+		results.GraphQL = e.extractGraphQL(run, content, fileName)
+	}
+	if opts.enabled(DetectorPersisted) {
+		results.PersistedQueries = e.extractPersistedQueries(run, content, fileName)
+	}
+	if opts.enabled(DetectorDOMSinks) {
+		results.DOMSinks = e.extractDOMSinks(run, content, fileName)
+	}
+	if opts.enabled(DetectorChunks) {
+		results.ChunkNames = e.extractChunkNames(run, content, fileName)
+	}
+
+	results.OverBudget = run.overBudget
+	return results, ctx.Err()
+}
+
+// finishSecrets drops the secrets suppressed by jsdumper:ignore comments,
+// then adds their line numbers and decoded JWT claims
+func finishSecrets(run *extraction, content string, results *Results) {
+	var suppressed []Secret
+	results.Secrets, suppressed = parseSuppressions(content).filter(content, results.Secrets)
+	results.Suppressed = len(suppressed)
+	for _, secret := range suppressed {
+		results.SuppressedTypes = append(results.SuppressedTypes, secret.Type)
+	}
+	locateSecrets(content, results.Secrets)
+	analyzeJWTs(results.Secrets, run.now)
+}
+
+func (e *Extractor) extractSecrets(run *extraction, content, fileName string) []Secret {
+	var secrets []Secret
+
+	for _, p := range e.patterns.Secrets {
+		for _, match := range run.findAllSubmatch(p.name, p.pattern, content) {
+			value := match[0]
+			if len(match) > 1 {
+				value = match[1]
+			}
+			if p.entropy != nil && !hasHighEntropy(value, p.entropy(run.opts.Entropy)) {
+				continue
+			}
+			secrets = append(secrets, Secret{
+				Type:     p.keyType,
+				File:     fileName,
+				Value:    value,
+				Severity: p.severity,
+			})
+		}
+
+		if run.canceled() {
+			return secrets
+		}
+	}
+
+	// Standalone Google API keys, unless already reported as a Firebase key
+	firebaseKeys := make(map[string]bool)
+	for _, secret := range secrets {
+		if secret.Type == "FIREBASE_API_KEY" {
+			firebaseKeys[secret.Value] = true
+		}
+	}
+	for _, match := range run.findAllSubmatch("googleAPIKey", e.patterns.GoogleAPIKey, content) {
+		if !firebaseKeys[match[1]] {
+			secrets = append(secrets, Secret{
+				Type:     "GOOGLE_API_KEY",
+				File:     fileName,
+				Value:    match[1],
+				Severity: "MEDIUM",
+			})
+		}
+	}
+
+	// Stripe keys - live keys are HIGH, test-mode keys only LOW
+	matches := run.findAllSubmatch("stripe", e.patterns.Stripe, content)
+	for _, match := range matches {
+		if len(match) > 3 {
+			keyType := "STRIPE_SECRET_KEY"
+			if match[2] == "rk" {
+				keyType = "STRIPE_RESTRICTED_KEY"
+			}
+			severity := "HIGH"
+			if match[3] == "test" {
+				keyType = strings.Replace(keyType, "STRIPE_", "STRIPE_TEST_", 1)
+				severity = "LOW"
+			}
+			secrets = append(secrets, Secret{
+				Type:     keyType,
+				File:     fileName,
+				Value:    match[1],
+				Severity: severity,
+			})
+		}
+	}
+
+	if run.canceled() {
+		return secrets
+	}
+
+	// Braintree access tokens carry their environment (production or sandbox)
+	braintreeMatches := run.findAllSubmatch("braintree", e.patterns.Braintree, content)
+	for _, match := range braintreeMatches {
+		keyType := "BRAINTREE_ACCESS_TOKEN"
+		severity := "HIGH"
+		if match[1] == "sandbox" {
+			keyType = "BRAINTREE_SANDBOX_ACCESS_TOKEN"
+			severity = "LOW"
+		}
+		secrets = append(secrets, Secret{
+			Type:     keyType,
+			File:     fileName,
+			Value:    match[0],
+			Severity: severity,
+		})
+	}
+
+	if run.canceled() {
+		return secrets
+	}
+
+	// PayPal client secrets - PayPal keys don't encode their environment,
+	// so look for a sandbox marker around the assignment
+	for _, loc := range run.findAllSubmatchIndex("paypal", e.patterns.PayPal, content) {
+		value := content[loc[2]:loc[3]]
+		if !hasHighEntropy(value, run.opts.Entropy.ClientSecret) {
+			continue
+		}
+		start := loc[0] - 200
+		if start < 0 {
+			start = 0
+		}
+		end := loc[1] + 200
+		if end > len(content) {
+			end = len(content)
+		}
+		keyType := "PAYPAL_CLIENT_SECRET"
+		severity := "HIGH"
+		if strings.Contains(strings.ToLower(content[start:end]), "sandbox") {
+			keyType = "PAYPAL_SANDBOX_CLIENT_SECRET"
+			severity = "LOW"
+		}
+		secrets = append(secrets, Secret{
+			Type:     keyType,
+			File:     fileName,
+			Value:    value,
+			Severity: severity,
+		})
+	}
+
+	if run.canceled() {
+		return secrets
+	}
+
+	// Generic API keys (high entropy)
+	matches = run.findAllSubmatch("apiKey", e.patterns.APIKey, content)
+	for _, match := range matches {
+		if len(match) > 1 && hasHighEntropy(match[1], run.opts.Entropy.APIKey) {
+			// Exclude common false positives
+			if !strings.Contains(match[1], "example") && !strings.Contains(match[1], "test") {
+				secrets = append(secrets, Secret{
+					Type:     "API_KEY",
+					File:     fileName,
+					Value:    match[1],
+					Severity: "MEDIUM",
+				})
+			}
+		}
+	}
+
+	if run.canceled() {
+		return secrets
+	}
+
+	// Hardcoded passwords (auth-related variables only)
+	// More strict pattern to avoid false positives with code
+	matches = run.findAllSubmatch("password", e.patterns.Password, content)
+	for _, match := range matches {
+		if len(match) > 1 && hasHighEntropy(match[1], run.opts.Entropy.Password) {
+			value := match[1]
+			lowerValue := strings.ToLower(value)
+
+			// Exclude common false positives
+			excludePatterns := []string{
+				"example",
+				"test",
+				"password",
+				"void",
+				"undefined",
+				"null",
+				"function",
+				"return",
+				"if",
+				"else",
+				"throw",
+				"b.hex",
+				"b.utf8",
+				"b.rstr",
+				"b.b64",
+				"b.b64u",
+			}
+
+			isFalsePositive := false
+			for _, pattern := range excludePatterns {
+				if strings.Contains(lowerValue, pattern) {
+					isFalsePositive = true
+					break
+				}
+			}
+
+			// Also exclude if it looks like code (contains operators, brackets, etc.)
+			if strings.Contains(value, "!=") || strings.Contains(value, "===") ||
+				strings.Contains(value, "!===") || strings.Contains(value, "&&") ||
+				strings.Contains(value, "||") || strings.Contains(value, "(") ||
+				strings.Contains(value, ")") || strings.Contains(value, "{") ||
+				strings.Contains(value, "}") || strings.Contains(value, ".") {
+				isFalsePositive = true
+			}
+
+			if !isFalsePositive {
+				secrets = append(secrets, Secret{
+					Type:     "PASSWORD",
+					File:     fileName,
+					Value:    value,
+					Severity: "HIGH",
+				})
+			}
+		}
+	}
+
+	// Connection strings with embedded credentials
+	for _, match := range run.findAllSubmatch("connectionString", e.patterns.Connection, content) {
+		user, password := match[2], match[3]
+		if isPlaceholderCredential(password) {
+			continue
+		}
+		secrets = append(secrets, Secret{
+			Type:     "CONNECTION_STRING",
+			File:     fileName,
+			Value:    strings.TrimRight(match[1], ".,;:)"),
+			Severity: "HIGH",
+			Detail:   "credentials " + user + ":" + password,
+		})
+	}
+
+	// User-defined rules
+	for i := range e.rules {
+		if run.canceled() {
+			return secrets
+		}
+		secrets = append(secrets, e.rules[i].extract(run, content, fileName)...)
+	}
+
+	// Implicit-flow tokens left in URL fragments (#access_token=..., #id_token=...)
+	secrets = append(secrets, e.extractFragmentTokens(run, content, fileName)...)
+
+	// Web push and realtime messaging keys
+	secrets = append(secrets, e.extractPushKeys(run, content, fileName)...)
+
+	secrets = filterIntegritySecrets(content, filterPlaceholderSecrets(secrets))
+	return deduplicateSecrets(secrets)
+}
+
+func (e *Extractor) extractFragmentTokens(run *extraction, content, fileName string) []Secret {
+	var secrets []Secret
+
+	for _, match := range run.findAll("fragmentURL", e.patterns.FragmentURL, content) {
+		parsed, err := urlpkg.Parse(match)
+		if err != nil || parsed.Fragment == "" {
+			continue
+		}
+		params, err := urlpkg.ParseQuery(parsed.Fragment)
+		if err != nil && len(params) == 0 {
+			continue
+		}
+
+		for _, param := range []string{"access_token", "id_token"} {
+			for _, value := range params[param] {
+				// Skip placeholders and template expressions
+				if len(value) < 16 || strings.ContainsAny(value, "{}$<>") {
+					continue
+				}
+				secrets = append(secrets, Secret{
+					Type:     "FRAGMENT_" + strings.ToUpper(param),
+					File:     fileName,
+					Value:    value,
+					Severity: "HIGH",
+				})
+			}
+		}
+	}
+
+	return secrets
+}
+
+func (e *Extractor) extractPushKeys(run *extraction, content, fileName string) []Secret {
+	var secrets []Secret
+
+	lowerContent := strings.ToLower(content)
+	for _, p := range e.patterns.PushKeys {
+		if run.canceled() {
+			return secrets
+		}
+		if p.context != "" && !containsWord(lowerContent, p.context) {
+			continue
+		}
+		for _, match := range run.findAllSubmatch(p.name, p.pattern, content) {
+			value := match[1]
+			if len(match) > 2 && value == "" {
+				value = match[2]
+			}
+			secrets = append(secrets, Secret{
+				Type:     p.keyType,
+				File:     fileName,
+				Value:    value,
+				Severity: p.severity,
+			})
+		}
+	}
+	return secrets
+}
+
+// extractPushChannels lists the channel names a Pusher/Ably client subscribes
+// to, which tell what events a leaked key can read, when the filtered secrets
+// of the file include a Pusher or Ably key
+func (e *Extractor) extractPushChannels(run *extraction, content, fileName string, secrets []Secret) []Interesting {
+	hasKey := false
+	for _, secret := range secrets {
+		if strings.HasPrefix(secret.Type, "PUSHER_") || secret.Type == "ABLY_API_KEY" {
+			hasKey = true
+			break
+		}
+	}
+	if !hasKey || run.canceled() {
+		return nil
+	}
+
+	var channels []Interesting
+	seen := make(map[string]bool)
+	for _, match := range run.findAllSubmatch("pushChannel", e.patterns.PushChannel, content) {
+		if !seen[match[1]] {
+			channels = append(channels, Interesting{Keyword: "push channel", File: fileName, Context: match[1]})
+			seen[match[1]] = true
+		}
+	}
+	return channels
+}
+
+// containsWord reports whether word occurs in s other than inside a longer
+// identifier ("ably" in "probably")
+func containsWord(s, word string) bool {
+	for i := 0; i+len(word) <= len(s); {
+		j := strings.Index(s[i:], word)
+		if j == -1 {
+			return false
+		}
+		start, end := i+j, i+j+len(word)
+		if (start == 0 || !isIdentByte(s[start-1])) && (end == len(s) || !isIdentByte(s[end])) {
+			return true
+		}
+		i = start + 1
+	}
+	return false
+}
+
+func (e *Extractor) extractEndpoints(run *extraction, content string) ([]string, map[string][]string) {
+	var endpoints []string
+	methods := make(map[string][]string)
+	seen := make(map[string]bool)
+
+	for _, p := range e.patterns.Endpoints {
+		for _, loc := range run.findAllSubmatchIndex(p.name, p.pattern, content) {
+			path := content[loc[2]:loc[3]]
+			if p.fullURL {
+				path = endpointPath(path)
+			}
+
+			normalized := normalizeEndpoint(path)
+			if normalized == "" {
+				continue
+			}
+			// A bare origin (new SockJS("https://host")) has no endpoint
+			if p.fullURL && normalized == "/" {
+				continue
+			}
+			if !seen[normalized] && !p.keepAssets && isAssetPath(normalized) {
+				continue
+			}
+			addMethod(methods, normalized, e.endpointMethod(content, loc))
+			if !seen[normalized] {
+				endpoints = append(endpoints, normalized)
+				seen[normalized] = true
+			}
+		}
+
+		if run.canceled() {
+			return endpoints, methods
+		}
+	}
+
+	return endpoints, methods
+}
+
+// endpointMethod returns the HTTP method of an endpoint match, if the code
+// says: api.post("/path"), xhr.open("PUT", "/path") or fetch("/path", { method: "DELETE" })
+func (e *Extractor) endpointMethod(content string, loc []int) string {
+	if m := e.patterns.EndpointMethod.FindStringSubmatch(content[loc[0]:loc[2]]); m != nil {
+		return strings.ToUpper(firstGroup(m[1:]))
+	}
+	if m := e.patterns.FetchMethod.FindStringSubmatch(content[loc[1]:min(len(content), loc[1]+300)]); m != nil && strings.HasPrefix(content[loc[0]:], "fetch") {
+		return strings.ToUpper(m[1])
+	}
+	return ""
+}
+
+// addMethod records that endpoint is requested with method
+func addMethod(methods map[string][]string, endpoint, method string) {
+	if method != "" && !slices.Contains(methods[endpoint], method) {
+		methods[endpoint] = append(methods[endpoint], method)
+	}
+}
+
+func (e *Extractor) extractImportantEndpoints(allEndpoints []string) []string {
+	var important []string
+	seen := make(map[string]bool)
+
+	for _, endpoint := range allEndpoints {
+		if isImportantEndpoint(endpoint) && !seen[endpoint] {
+			important = append(important, endpoint)
+			seen[endpoint] = true
+		}
+	}
+
+	return important
+}
+
+func (e *Extractor) extractURLs(run *extraction, content string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+
+	matches := run.findAll("url", e.patterns.URL, content)
+	for _, match := range matches {
+		canonical, ok := canonicalURL(match)
+		if !ok {
+			continue
+		}
+
+		normalized := normalizeURL(canonical, run.opts.URLs)
+		if normalized != "" && !seen[normalized] {
+			// Filter out common CDN/media URLs unless they look like APIs
+			if !isExcludedURL(normalized) {
+				urls = append(urls, normalized)
+				seen[normalized] = true
+			}
+		}
+	}
+
+	return urls
+}
+
+// Passwords in documentation and templates rather than real credentials
+func isPlaceholderCredential(password string) bool {
+	lower := strings.ToLower(password)
+	switch lower {
+	case "password", "passwd", "pass", "pwd", "secret", "changeme", "example", "xxx", "xxxx", "****":
+		return true
+	}
+	for _, marker := range []string{"${", "{{", "<", "%s", "process.env"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return strings.HasPrefix(lower, "$") || strings.HasPrefix(lower, ":")
+}
+
+// Record the line of each secret's first occurrence in content
+func locateSecrets(content string, secrets []Secret) {
+	if len(secrets) == 0 {
+		return
+	}
+	index := newLineIndex(content)
+	for i := range secrets {
+		if pos := strings.Index(content, secrets[i].Value); pos != -1 {
+			secrets[i].Line = index.line(pos)
+		}
+	}
+}
+
+func deduplicateSecrets(secrets []Secret) []Secret {
+	seen := make(map[string]bool)
+	var unique []Secret
+
+	for _, secret := range secrets {
+		key := secret.Type + ":" + secret.Value
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, secret)
+		}
+	}
+
+	return unique
+}
+
+func (e *Extractor) extractInteresting(run *extraction, content, fileName string) []Interesting {
+	var hits []Interesting
+	seen := make(map[string]bool)
+
+	// Keep a short window around each hit so the line makes sense on its own
+	const contextSize = 40
+
+	for _, kp := range e.keywords {
+		if run.canceled() {
+			break
+		}
+		keyword := kp.keyword
+		for _, loc := range run.findAllSubmatchIndex("keyword:"+keyword, kp.pattern, content) {
+			context := contextAround(content, loc[0], loc[1], contextSize)
+
+			key := strings.ToLower(keyword) + ":" + context
+			if !seen[key] {
+				hits = append(hits, Interesting{
+					Keyword: keyword,
+					File:    fileName,
+					Context: context,
+				})
+				seen[key] = true
+			}
+		}
+	}
+
+	return hits
+}
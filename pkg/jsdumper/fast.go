@@ -0,0 +1,188 @@
+package jsdumper
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// fastPass is the detector set of -fast: the secret patterns that start
+// with a literal token prefix (ghp_, eyJ, SG., -----BEGIN, ...) and the main
+// endpoint patterns. One scan of the content looks for their prefixes and
+// only the patterns whose prefix is found run, anchored there. Context-based
+// secrets (client_secret = "..."), URLs and every other category are
+// skipped, so results are reduced-coverage.
+type fastPass struct {
+	triggers [256][]fastTrigger // By first byte
+}
+
+// fastTrigger is a literal that starts every match of pattern
+type fastTrigger struct {
+	literal  string
+	boundary bool // The pattern starts with \b
+	pattern  *regexp.Regexp
+	secret   *secretPattern
+	endpoint *endpointPattern
+}
+
+// Endpoint patterns run by -fast, with the literals their matches start with:
+// direct fetch/axios/XHR calls and quoted paths under well-known prefixes
+// (/api, /v1, /admin, ...)
+var fastEndpointPatterns = map[string][]string{
+	"fetch":       {"fetch"},
+	"axios":       {"axios."},
+	"xhr":         {".open"},
+	"commonRoute": {`"/`, `'/`},
+}
+
+// Shorter prefixes (Twilio's AC/SK) match too much to be worth a trigger
+const minFastPrefix = 3
+
+// Longest match looked for after a trigger; PEM blocks are the longest values
+const fastMatchWindow = 16 << 10
+
+// Printed at the start and in the summary of -fast runs
+const FastCoverageNote = "Fast mode (-fast): reduced coverage, only prefix-based secrets and fetch/axios/XHR/common-route endpoints are searched"
+
+func newFastPass(p *patterns) *fastPass {
+	f := &fastPass{}
+	add := func(literal string, boundary bool, pattern *regexp.Regexp, t fastTrigger) {
+		t.literal = literal
+		t.boundary = boundary
+		// Anchored, so a trigger without a match costs a few steps
+		t.pattern = regexp.MustCompile(`^(?:` + pattern.String() + `)`)
+		f.triggers[literal[0]] = append(f.triggers[literal[0]], t)
+	}
+
+	googleAPIKey := secretPattern{"googleAPIKey", "GOOGLE_API_KEY", "MEDIUM", p.GoogleAPIKey, nil}
+	for _, s := range append(p.Secrets, googleAPIKey) {
+		if prefix, boundary := literalPrefix(s.pattern); len(prefix) >= minFastPrefix {
+			add(prefix, boundary, s.pattern, fastTrigger{secret: &s})
+		}
+	}
+	for _, ep := range p.Endpoints {
+		for _, literal := range fastEndpointPatterns[ep.name] {
+			add(literal, false, ep.pattern, fastTrigger{endpoint: &ep})
+		}
+	}
+	return f
+}
+
+// literalPrefix returns the case-sensitive literal every match of pattern
+// starts with, skipping leading capture groups, and whether a word boundary
+// comes before it
+func literalPrefix(pattern *regexp.Regexp) (string, bool) {
+	re, err := syntax.Parse(pattern.String(), syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	boundary := false
+	for {
+		switch re.Op {
+		case syntax.OpCapture:
+			re = re.Sub[0]
+		case syntax.OpConcat:
+			i := 0
+			for i < len(re.Sub) && re.Sub[i].Op == syntax.OpWordBoundary {
+				boundary = true
+				i++
+			}
+			if i == len(re.Sub) {
+				return "", false
+			}
+			re = re.Sub[i]
+		case syntax.OpLiteral:
+			if re.Flags&syntax.FoldCase != 0 {
+				return "", false
+			}
+			return string(re.Rune), boundary
+		default:
+			return "", false
+		}
+	}
+}
+
+// extractFast runs the -fast pass: secrets and endpoints from one scan of
+// content, post-processed like the full detectors
+func (e *Extractor) extractFast(run *extraction, content, fileName string, results *Results) {
+	fast := e.fast()
+	secrets := run.opts.enabled(DetectorSecrets)
+	endpoints := run.opts.enabled(DetectorEndpoints)
+	if endpoints {
+		results.EndpointMethods = make(map[string][]string)
+	}
+	seen := make(map[string]bool)
+	limit := run.opts.limit()
+	matches := 0
+
+	for i := 0; i < len(content); i++ {
+		if i%matchWindowSize == 0 && run.canceled() {
+			break
+		}
+		triggers := fast.triggers[content[i]]
+		if len(triggers) == 0 {
+			continue
+		}
+
+		for _, t := range triggers {
+			if !strings.HasPrefix(content[i:], t.literal) || (t.boundary && i > 0 && isWordByte(content[i-1])) {
+				continue
+			}
+			loc := t.pattern.FindStringSubmatchIndex(content[i:min(len(content), i+fastMatchWindow)])
+			if loc == nil {
+				continue
+			}
+			for j := range loc {
+				if loc[j] >= 0 {
+					loc[j] += i
+				}
+			}
+			// The value is group 1, or the whole match
+			value := content[loc[0]:loc[1]]
+			if len(loc) > 2 && loc[2] >= 0 {
+				value = content[loc[2]:loc[3]]
+			}
+
+			if t.secret != nil && secrets && (t.secret.entropy == nil || hasHighEntropy(value, t.secret.entropy(run.opts.Entropy))) {
+				results.Secrets = append(results.Secrets, Secret{
+					Type:     t.secret.keyType,
+					File:     fileName,
+					Value:    value,
+					Severity: t.secret.severity,
+				})
+			}
+			if t.endpoint != nil && endpoints {
+				normalized := normalizeEndpoint(value)
+				if normalized != "" && (seen[normalized] || t.endpoint.keepAssets || !isAssetPath(normalized)) {
+					addMethod(results.EndpointMethods, normalized, e.endpointMethod(content, loc))
+					if !seen[normalized] {
+						results.Endpoints = append(results.Endpoints, normalized)
+						seen[normalized] = true
+					}
+				}
+			}
+
+			// Matches don't overlap, as with the full detectors
+			i = max(i, loc[1]-1)
+			matches++
+			break
+		}
+
+		if limit > 0 && matches >= limit {
+			run.exceeded("fast")
+			break
+		}
+	}
+
+	if secrets {
+		results.Secrets = deduplicateSecrets(filterIntegritySecrets(content, filterPlaceholderSecrets(results.Secrets)))
+		finishSecrets(run, content, results)
+	}
+	if endpoints {
+		results.ImportantEndpoints = e.extractImportantEndpoints(results.Endpoints)
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
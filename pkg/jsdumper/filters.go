@@ -0,0 +1,103 @@
+package jsdumper
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Filter is a named secret filter preset, selected with -filter. Presets are
+// built in or defined in the -rules file:
+//
+//	filters:
+//	  cloud-creds:
+//	    types: [AWS_*, GCP_*]   # secret types, * wildcards allowed (default: any)
+//	    severity: HIGH          # minimum severity (default: any)
+type Filter struct {
+	Types    []string `yaml:"types"`
+	Severity string   `yaml:"severity"`
+}
+
+// Built-in presets; a -rules file can override them by name
+var builtinFilters = map[string]Filter{
+	"cloud-creds": {Types: []string{"AWS_*", "GCP_*", "GOOGLE_*", "FIREBASE_*", "PRIVATE_KEY"}, Severity: "HIGH"},
+	"messaging":   {Types: []string{"TWILIO_*", "SENDGRID_*", "MAILGUN_*"}},
+	"high":        {Severity: "HIGH"},
+}
+
+func (f Filter) validate(name string) error {
+	if f.Severity != "" && !validSeverities[f.Severity] {
+		return fmt.Errorf("filter %s: invalid severity %q", name, f.Severity)
+	}
+	for _, pattern := range f.Types {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("filter %s: invalid type pattern %q", name, pattern)
+		}
+	}
+	return nil
+}
+
+func (f Filter) match(secret Secret) bool {
+	if f.Severity != "" && severityWeights[secret.Severity] < severityWeights[f.Severity] {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, pattern := range f.Types {
+		if matched, _ := path.Match(pattern, secret.Type); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveFilters looks up comma-separated preset names; user-defined presets
+// take precedence over built-in ones
+func ResolveFilters(names []string, defined map[string]Filter) ([]Filter, error) {
+	var filters []Filter
+	for _, name := range names {
+		filter, ok := defined[name]
+		if !ok {
+			filter, ok = builtinFilters[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown filter %q (available: %s)", name, strings.Join(filterNames(defined), ", "))
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+func filterNames(defined map[string]Filter) []string {
+	var names []string
+	for name := range builtinFilters {
+		names = append(names, name)
+	}
+	for name := range defined {
+		if _, builtin := builtinFilters[name]; !builtin {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FilterStage keeps the secrets matching any of the filters
+func FilterStage(filters []Filter) ResultStage {
+	return func(_ context.Context, results *Results) error {
+		var kept []Secret
+		for _, secret := range results.Secrets {
+			for _, filter := range filters {
+				if filter.match(secret) {
+					kept = append(kept, secret)
+					break
+				}
+			}
+		}
+		results.Secrets = kept
+		return nil
+	}
+}
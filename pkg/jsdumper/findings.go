@@ -0,0 +1,168 @@
+package jsdumper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// findingsDocument is findings.json (-format json): every secret, endpoint
+// and URL of the run with what is known about it, for tooling that would
+// otherwise re-parse the text outputs, and the warnings about its inputs
+type findingsDocument struct {
+	JSDumper  BuildInfo         `json:"jsdumper"`
+	Coverage  string            `json:"coverage"`
+	Tags      []string          `json:"tags,omitempty"`
+	Secrets   []secretFinding   `json:"secrets"`
+	Endpoints []endpointFinding `json:"endpoints"`
+	URLs      []urlFinding      `json:"urls"`
+	Warnings  []Warning         `json:"warnings"` // Inputs whose findings may be incomplete
+}
+
+type secretFinding struct {
+	Type     string   `json:"type"`
+	Severity string   `json:"severity"`
+	File     string   `json:"file"`
+	Line     int      `json:"line,omitempty"`
+	Value    string   `json:"value"`
+	Detail   string   `json:"detail,omitempty"`
+	JWT      *JWTInfo `json:"jwt,omitempty"`
+	Encoding string   `json:"encoding,omitempty"` // base64: found in a decoded base64 literal
+	Tags     []string `json:"tags,omitempty"`     // Tags of the input it was found in
+}
+
+type endpointFinding struct {
+	Path      string   `json:"path"`
+	Methods   []string `json:"methods,omitempty"`
+	Important bool     `json:"important"`
+	Flow      string   `json:"flow,omitempty"` // Account flow: password-reset, recovery, verification, magic-link or mfa
+	Encoding  string   `json:"encoding,omitempty"`
+	Files     []string `json:"files"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+type urlFinding struct {
+	URL      string   `json:"url"`
+	Encoding string   `json:"encoding,omitempty"`
+	Files    []string `json:"files"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// WriteFindings writes findings.json; endpoints and URLs list the files they
+// were found in, and findings of tagged inputs carry their tags
+func (a *AggregatedResults) WriteFindings(filePath string) error {
+	doc := findingsDocument{
+		JSDumper:  CurrentBuildInfo(),
+		Coverage:  a.Coverage,
+		Tags:      a.Tags,
+		Secrets:   []secretFinding{},
+		Endpoints: []endpointFinding{},
+		URLs:      []urlFinding{},
+		Warnings:  append([]Warning{}, a.Warnings...),
+	}
+	for _, secret := range a.Secrets {
+		doc.Secrets = append(doc.Secrets, secretFinding{
+			Type:     secret.Type,
+			Severity: secret.Severity,
+			File:     secret.File,
+			Line:     secret.Line,
+			Value:    secret.Value,
+			Detail:   secret.Detail,
+			JWT:      secret.JWT,
+			Encoding: secret.Encoding,
+			Tags:     a.TagsOf(secret.File, secret.Value),
+		})
+	}
+	important := toSet(a.ImportantEndpoints)
+	encoded := toSet(a.Base64Encoded)
+	for _, endpoint := range a.Endpoints {
+		doc.Endpoints = append(doc.Endpoints, endpointFinding{
+			Path:      endpoint,
+			Methods:   a.EndpointMethods[endpoint],
+			Important: important[endpoint],
+			Flow:      AccountFlow(endpoint),
+			Encoding:  base64Encoding(encoded[endpoint]),
+			Files:     append([]string{}, a.endpointFiles[endpoint]...),
+			Tags:      a.TagsOf("", endpoint),
+		})
+	}
+	for _, url := range a.URLs {
+		doc.URLs = append(doc.URLs, urlFinding{
+			URL:      url,
+			Encoding: base64Encoding(encoded[url]),
+			Files:    append([]string{}, a.urlFiles[url]...),
+			Tags:     a.TagsOf("", url),
+		})
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal findings: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write findings file: %w", err)
+	}
+	return nil
+}
+
+// base64Encoding is the encoding of an endpoint or URL in findings.json
+func base64Encoding(encoded bool) string {
+	if encoded {
+		return "base64"
+	}
+	return ""
+}
+
+// Finding is a single result as delivered to output sinks. Endpoints of
+// account flows carry their flow.
+type Finding struct {
+	Category string   `json:"category"` // secret, endpoint, url, interesting or infra
+	Type     string   `json:"type,omitempty"`
+	Severity string   `json:"severity,omitempty"`
+	File     string   `json:"file,omitempty"`
+	Value    string   `json:"value"`
+	Flow     string   `json:"flow,omitempty"`     // Account flow of an endpoint: password-reset, recovery, ...
+	Encoding string   `json:"encoding,omitempty"` // base64: only found in a decoded base64 literal
+	Tags     []string `json:"tags,omitempty"`     // Tags of the inputs it came from (-tag)
+}
+
+// Flatten aggregated results into findings, each with the tags of the
+// inputs it came from
+func (a *AggregatedResults) Findings() []Finding {
+	findings := flattenFindings(a.Secrets, a.Endpoints, a.URLs, a.Interesting, a.InfraReferences, a.Base64Encoded, "", nil)
+	for i, finding := range findings {
+		findings[i].Tags = a.TagsOf(finding.File, finding.Value)
+	}
+	return findings
+}
+
+// Flatten the results of one file into findings
+func (r *Results) Findings() []Finding {
+	return flattenFindings(r.Secrets, r.Endpoints, r.URLs, r.Interesting, r.InfraReferences, r.Base64Encoded, r.File, r.Tags)
+}
+
+// flattenFindings lists findings by category; endpoints and URLs carry file,
+// empty once aggregated across files. Every finding carries tags.
+func flattenFindings(secrets []Secret, endpoints, urls []string, interesting []Interesting, infra []InfraReference, base64Encoded []string, file string, tags []string) []Finding {
+	var findings []Finding
+	encoded := toSet(base64Encoded)
+	for _, secret := range secrets {
+		findings = append(findings, Finding{Category: "secret", Type: secret.Type, Severity: secret.Severity, File: secret.File, Value: secret.Value, Encoding: secret.Encoding})
+	}
+	for _, endpoint := range endpoints {
+		findings = append(findings, Finding{Category: "endpoint", Flow: AccountFlow(endpoint), File: file, Value: endpoint, Encoding: base64Encoding(encoded[endpoint])})
+	}
+	for _, url := range urls {
+		findings = append(findings, Finding{Category: "url", File: file, Value: url, Encoding: base64Encoding(encoded[url])})
+	}
+	for _, hit := range interesting {
+		findings = append(findings, Finding{Category: "interesting", Type: hit.Keyword, File: hit.File, Value: hit.Context})
+	}
+	for _, ref := range infra {
+		findings = append(findings, Finding{Category: "infra", Type: ref.Kind, File: ref.File, Value: ref.Value})
+	}
+	for i := range findings {
+		findings[i].Tags = tags
+	}
+	return findings
+}
@@ -0,0 +1,134 @@
+package jsdumper
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// fixture is one recorded HTTP exchange (-record), served back by -replay.
+// The body is kept as received, still compressed if the server compressed it.
+type fixture struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// fixtureName is the file of the exchange for method and url in a fixtures
+// directory
+func fixtureName(method, url string) string {
+	sum := sha256.Sum256([]byte(method + " " + url))
+	return hex.EncodeToString(sum[:8]) + ".json"
+}
+
+// maxFixtureSize caps the body of a recorded response, which is held in
+// memory to be saved
+const maxFixtureSize = 128 << 20
+
+// recordTransport saves every response it receives to dir
+type recordTransport struct {
+	next http.RoundTripper
+	dir  string
+}
+
+func newRecordTransport(next http.RoundTripper, dir string) (*recordTransport, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create fixtures directory: %w", err)
+	}
+	return &recordTransport{next: next, dir: dir}, nil
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFixtureSize+1))
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxFixtureSize {
+		return nil, fmt.Errorf("response of %s too large to record (over %d MB)", req.URL, maxFixtureSize>>20)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	data, err := json.MarshalIndent(fixture{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Status: resp.StatusCode,
+		Header: resp.Header,
+		Body:   body,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode fixture: %w", err)
+	}
+	// Written aside and renamed, as parallel workers may fetch the same URL
+	file, err := os.CreateTemp(t.dir, ".fixture-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to record fixture: %w", err)
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), filepath.Join(t.dir, fixtureName(req.Method, req.URL.String())))
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to record fixture: %w", err)
+	}
+	return resp, nil
+}
+
+// replayTransport answers requests from the fixtures in dir, without any
+// network access; requests that weren't recorded fail
+type replayTransport struct {
+	dir string
+}
+
+func newReplayTransport(dir string) (*replayTransport, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open fixtures directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("fixtures path %s is not a directory", dir)
+	}
+	return &replayTransport{dir: dir}, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	data, err := os.ReadFile(filepath.Join(t.dir, fixtureName(req.Method, req.URL.String())))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture for %s: %w", req.URL, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        f.Header,
+		Body:          io.NopCloser(bytes.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}, nil
+}
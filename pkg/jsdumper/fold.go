@@ -0,0 +1,322 @@
+package jsdumper
+
+import (
+	"strings"
+)
+
+// Bundlers and developers split paths across literals ("/api/" + "v2" +
+// "/users", BASE + "/auth"), which the endpoint patterns, matching one
+// literal, miss. foldConstants joins them before endpoint extraction.
+
+// Passes of foldConstants: a constant defined from other constants is only
+// known once they are folded into its definition
+const foldPasses = 3
+
+// Shortest constant name folded: minifiers reuse one- and two-letter names
+// in every scope, so e="/v1" says nothing about the e of another function
+const foldMinNameLength = 3
+
+// foldSpan is an operand of a + chain: a string literal (template literals
+// without ${}), an identifier or an integer
+type foldSpan struct{ start, end int }
+
+// foldConstants returns content with the concatenations of string literals
+// and string constants replaced by the string they make. Constants are names
+// bound once in the file, to a literal. The lines of content are kept, so
+// what follows a folded expression stays on its line.
+func foldConstants(content string) string {
+	for pass := 0; pass < foldPasses; pass++ {
+		chains := foldChains(content)
+		if len(chains) == 0 {
+			break
+		}
+		folded := foldPass(content, chains, foldedConstants(content, chains))
+		if folded == content {
+			break
+		}
+		content = folded
+	}
+	return content
+}
+
+// foldChains finds the + chains of content. Strings, template literals,
+// comments and regular expressions are stepped over like the beautifier
+// does, so quotes and + signs inside them aren't taken for operands.
+func foldChains(content string) [][]foldSpan {
+	var chains [][]foldSpan
+	var chain []foldSpan
+	plus := false // A + follows the last operand of chain
+	end := func() {
+		if len(chain) > 1 {
+			chains = append(chains, chain)
+		}
+		chain, plus = nil, false
+	}
+	operand := func(span foldSpan) {
+		if !plus {
+			end()
+		}
+		chain, plus = append(chain, span), false
+	}
+
+	last, word := byte(0), ""
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case isBlank(c):
+			i++
+			continue
+		case c == '"' || c == '\'' || c == '`':
+			// Unterminated strings and templates with ${} aren't operands
+			if next := foldLiteralAt(content, i); next != -1 {
+				operand(foldSpan{i, next})
+			} else {
+				end()
+			}
+			if c == '`' {
+				i = skipTemplate(content, i)
+			} else {
+				i = skipJSString(content, i)
+			}
+			last, word = 'a', ""
+			continue
+		case c == '/' && i+1 < len(content) && content[i+1] == '/':
+			if next := strings.IndexByte(content[i:], '\n'); next != -1 {
+				i += next
+			} else {
+				i = len(content)
+			}
+			continue
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			if next := strings.Index(content[i+2:], "*/"); next != -1 {
+				i += next + 4
+			} else {
+				i = len(content)
+			}
+			continue
+		case c == '/' && regexAllowedAfter(last, word):
+			end()
+			i, last, word = skipRegex(content, i), 'a', ""
+			continue
+		case isIdentByte(c):
+			start := i
+			for i < len(content) && isIdentByte(content[i]) {
+				i++
+			}
+			operand(foldSpan{start, i})
+			last, word = 'a', content[start:i]
+			continue
+		case c == '+' && i+1 < len(content) && content[i+1] != '+' && content[i+1] != '=' && len(chain) > 0 && !plus:
+			plus = true
+		default:
+			end()
+		}
+		last, word = c, ""
+		i++
+	}
+	end()
+	return chains
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// foldLiteralAt returns the end of the string or template literal without
+// ${} starting at start, or -1
+func foldLiteralAt(content string, start int) int {
+	if start >= len(content) {
+		return -1
+	}
+	switch c := content[start]; c {
+	case '"', '\'':
+		end := skipJSString(content, start)
+		if end > start+1 && content[end-1] == c {
+			return end
+		}
+	case '`':
+		end := skipTemplate(content, start)
+		if end > start+1 && !strings.ContainsAny(content[start:end], "$\\") {
+			return end
+		}
+	}
+	return -1
+}
+
+// foldedConstants returns the string constants among the identifiers of
+// chains: their literal, quotes included, by name. A constant is declared
+// once, to a literal (const BASE = "/v1", API = '/api'), and never assigned.
+func foldedConstants(content string, chains [][]foldSpan) map[string]string {
+	names := make(map[string]bool)
+	for _, chain := range chains {
+		for _, span := range chain {
+			if name := content[span.start:span.end]; len(name) >= foldMinNameLength && isIdentStart(name[0]) {
+				names[name] = true
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	// One pass over the identifiers of content counts the bindings of names
+	bindings := make(map[string]int)
+	literals := make(map[string]string)
+	for i := 0; i < len(content); {
+		if !isIdentByte(content[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(content) && isIdentByte(content[i]) {
+			i++
+		}
+		name := content[start:i]
+		if !names[name] || start > 0 && content[start-1] == '.' {
+			continue
+		}
+		j := i
+		for j < len(content) && isBlank(content[j]) {
+			j++
+		}
+		if j+1 < len(content) && content[j] == '=' && content[j+1] != '=' && content[j+1] != '>' {
+			bindings[name]++
+			literals[name] = foldDeclared(content, start, j+1)
+		}
+	}
+
+	constants := make(map[string]string)
+	for name, count := range bindings {
+		if count == 1 && literals[name] != "" {
+			constants[name] = literals[name]
+		}
+	}
+	return constants
+}
+
+func isIdentStart(c byte) bool {
+	return isIdentByte(c) && (c < '0' || c > '9')
+}
+
+// foldDeclared returns the literal of the declaration whose name starts at
+// i and whose value starts at value, or "" unless it is const/let/var name
+// = literal, or name = literal after a comma
+func foldDeclared(content string, i, value int) string {
+	before := strings.TrimRight(content[:i], " \t\r\n")
+	if !strings.HasSuffix(before, ",") && !strings.HasSuffix(before, "const") && !strings.HasSuffix(before, "let") && !strings.HasSuffix(before, "var") {
+		return ""
+	}
+	for value < len(content) && isBlank(content[value]) {
+		value++
+	}
+	end := foldLiteralAt(content, value)
+	if end == -1 {
+		return ""
+	}
+	rest := strings.TrimLeft(content[end:min(len(content), end+16)], " \t\r")
+	if rest != "" && strings.IndexByte(",;\n})", rest[0]) == -1 {
+		return "" // An expression goes on
+	}
+	return content[value:end]
+}
+
+// foldPass folds each run of known operands of the chains
+func foldPass(content string, chains [][]foldSpan, constants map[string]string) string {
+	var out strings.Builder
+	last := 0
+	for _, chain := range chains {
+		out.WriteString(content[last:chain[0].start])
+		out.WriteString(foldChain(content, chain, constants))
+		last = chain[len(chain)-1].end
+	}
+	out.WriteString(content[last:])
+	return out.String()
+}
+
+// foldChain folds the runs of known operands of a chain. The newlines of
+// the chain go after it, to keep the line count.
+func foldChain(content string, chain []foldSpan, constants map[string]string) string {
+	var parts []string
+	var run []string // Raw contents of the known operands in a row
+	quote := byte('"')
+	folded := false
+	flush := func(previous string) {
+		if len(run) == 1 {
+			parts = append(parts, previous)
+		} else if len(run) > 1 {
+			parts = append(parts, string(quote)+strings.Join(run, "")+string(quote))
+			folded = true
+		}
+		run = nil
+	}
+
+	isString := false // A string is on the left, so + concatenates
+	previous := ""
+	for _, span := range chain {
+		token := content[span.start:span.end]
+		value, known := foldValue(token, constants)
+
+		// Numbers add up until a string is on the left; properties, calls
+		// and indexing make an operand something else
+		after := strings.TrimLeft(content[span.end:min(len(content), span.end+16)], " \t\r\n")
+		switch {
+		case span.start > 0 && content[span.start-1] == '.':
+			known = false
+		case after != "" && strings.IndexByte(".([", after[0]) >= 0:
+			known = false
+		case !isIdentStart(token[0]) && !isQuoted(token):
+			known = known && isString
+		}
+
+		if !known {
+			flush(previous)
+			parts = append(parts, token)
+		} else {
+			if len(run) == 0 {
+				quote = foldQuote(token, constants)
+			}
+			run = append(run, value)
+			isString = isString || !(token[0] >= '0' && token[0] <= '9')
+		}
+		previous = token
+	}
+	flush(previous)
+
+	original := content[chain[0].start:chain[len(chain)-1].end]
+	if !folded {
+		return original
+	}
+	return strings.Join(parts, "+") + strings.Repeat("\n", strings.Count(original, "\n"))
+}
+
+// foldQuote returns the quote of a folded literal starting with token: the
+// token's, or its constant's; template literals become double-quoted strings
+func foldQuote(token string, constants map[string]string) byte {
+	if literal, ok := constants[token]; ok {
+		token = literal
+	}
+	if token[0] == '\'' {
+		return '\''
+	}
+	return '"'
+}
+
+// foldValue returns the raw contents of a literal, integer or known constant
+func foldValue(token string, constants map[string]string) (string, bool) {
+	if isQuoted(token) {
+		return token[1 : len(token)-1], true
+	}
+	if literal, ok := constants[token]; ok {
+		return literal[1 : len(literal)-1], true
+	}
+	for i := 0; i < len(token); i++ {
+		if token[i] < '0' || token[i] > '9' {
+			return "", false
+		}
+	}
+	return token, true
+}
+
+func isQuoted(token string) bool {
+	return token[0] == '"' || token[0] == '\'' || token[0] == '`'
+}
@@ -0,0 +1,102 @@
+package jsdumper
+
+import (
+	"fmt"
+	"net"
+	urlpkg "net/url"
+	"strings"
+	"syscall"
+)
+
+// Hosts never contacted, whatever the input: cloud metadata services, where
+// a URL found in a bundle or list could leak instance credentials
+var defaultForbiddenHosts = []string{
+	"169.254.0.0/16",  // Link-local: AWS, Azure, GCP, DigitalOcean, ECS task metadata
+	"fd00:ec2::254",   // AWS IMDS over IPv6
+	"100.100.100.200", // Alibaba Cloud
+	"metadata.google.internal",
+	"metadata.goog",
+}
+
+// hostPolicy refuses downloads from forbidden hosts (-forbid-hosts)
+type hostPolicy struct {
+	names []string     // Host names; subdomains are forbidden too
+	nets  []*net.IPNet // IPs and CIDR ranges, checked on every connection
+}
+
+// forbiddenHostError is a download refused by the host policy
+type forbiddenHostError struct {
+	Host string
+}
+
+func (e *forbiddenHostError) Error() string {
+	return fmt.Sprintf("host %s is forbidden (-forbid-hosts)", e.Host)
+}
+
+func newHostPolicy(entries []string) (*hostPolicy, error) {
+	policy := &hostPolicy{}
+	for _, entry := range append(append([]string{}, defaultForbiddenHosts...), entries...) {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			policy.nets = append(policy.nets, ipNet)
+			continue
+		}
+		if ip := net.ParseIP(strings.Trim(entry, "[]")); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			policy.nets = append(policy.nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		name := strings.Trim(entry, ".")
+		if !hostnamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid forbidden host %q (expected a host name, IP or CIDR range)", entry)
+		}
+		policy.names = append(policy.names, name)
+	}
+	return policy, nil
+}
+
+// checkURL rejects URLs whose host is forbidden by name or IP literal
+func (p *hostPolicy) checkURL(rawURL string) error {
+	parsed, err := urlpkg.Parse(rawURL)
+	if err != nil {
+		return nil // The request itself reports malformed URLs
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	for _, name := range p.names {
+		if host == name || strings.HasSuffix(host, "."+name) {
+			return &forbiddenHostError{Host: host}
+		}
+	}
+	if ip := net.ParseIP(host); ip != nil && p.forbiddenIP(ip) {
+		return &forbiddenHostError{Host: host}
+	}
+	return nil
+}
+
+func (p *hostPolicy) forbiddenIP(ip net.IP) bool {
+	for _, ipNet := range p.nets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// dialControl runs before every connection, after DNS resolution, so names
+// that resolve to a forbidden IP are refused too
+func (p *hostPolicy) dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && p.forbiddenIP(ip) {
+		return &forbiddenHostError{Host: host}
+	}
+	return nil
+}
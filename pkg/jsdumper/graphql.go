@@ -0,0 +1,226 @@
+package jsdumper
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// GraphQLOperation is a query, mutation, subscription or fragment defined in
+// the code, with the root fields it selects
+type GraphQLOperation struct {
+	Type   string   // query, mutation, subscription or fragment
+	Name   string   // Operation name, "" for anonymous queries; "Name on Type" for fragments
+	Fields []string // Root fields: the API operations being called
+	File   string
+}
+
+func (e *Extractor) extractGraphQL(run *extraction, content, fileName string) []GraphQLOperation {
+	var operations []GraphQLOperation
+	seen := make(map[string]bool)
+
+	add := func(operation GraphQLOperation) {
+		key := operation.Type + ":" + operation.Name + ":" + strings.Join(operation.Fields, ",")
+		if !seen[key] {
+			operation.File = fileName
+			operations = append(operations, operation)
+			seen[key] = true
+		}
+	}
+
+	// gql`...` and graphql(`...`) tagged templates; interpolations are
+	// fragment spreads or other documents, dropped before parsing
+	for _, match := range run.findAllSubmatch("graphqlTag", e.patterns.GraphQLTag, content) {
+		document := e.patterns.TemplateInterpolation.ReplaceAllString(match[1], " ")
+		for _, operation := range parseGraphQL(document, true) {
+			add(operation)
+		}
+	}
+
+	// Query strings, including tagged templates compiled to plain strings
+	for _, match := range run.findAllSubmatch("graphqlString", e.patterns.GraphQLString, content) {
+		for _, operation := range parseGraphQL(unescapeJSString(firstGroup(match[1:])), false) {
+			add(operation)
+		}
+	}
+
+	// Documents precompiled to an AST by graphql-tag/loader or babel plugins
+	for _, match := range run.findAllSubmatch("graphqlAST", e.patterns.GraphQLAST, content) {
+		add(GraphQLOperation{Type: match[1], Name: match[2]})
+	}
+
+	return operations
+}
+
+// Unescape the escapes common in query strings: newlines, tabs and quotes
+func unescapeJSString(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\r`, "", `\"`, `"`, `\'`, "'", `\\`, `\`).Replace(s)
+}
+
+// parseGraphQL returns the operations and fragments defined in a document.
+// Anonymous queries ({ user { id } }) are only accepted from tagged
+// templates, where the document is known to be GraphQL.
+func parseGraphQL(document string, allowAnonymous bool) []GraphQLOperation {
+	tokens := graphQLTokens(document)
+	var operations []GraphQLOperation
+
+	for i := 0; i < len(tokens); {
+		operation := GraphQLOperation{}
+		switch tokens[i] {
+		case "query", "mutation", "subscription":
+			operation.Type = tokens[i]
+			i++
+			if i < len(tokens) && isGraphQLName(tokens[i]) {
+				operation.Name = tokens[i]
+				i++
+			}
+		case "fragment":
+			if i+3 >= len(tokens) || !isGraphQLName(tokens[i+1]) || tokens[i+2] != "on" {
+				return operations
+			}
+			operation.Type = "fragment"
+			operation.Name = tokens[i+1] + " on " + tokens[i+3]
+			i += 4
+		case "{":
+			if !allowAnonymous {
+				return operations
+			}
+			operation.Type = "query"
+		default:
+			return operations
+		}
+
+		// Skip variable definitions and directives up to the selection set
+		depth := 0
+		for i < len(tokens) && (tokens[i] != "{" || depth > 0) {
+			switch tokens[i] {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			}
+			i++
+		}
+		if i == len(tokens) {
+			return operations
+		}
+
+		fields, next, ok := graphQLRootFields(tokens, i)
+		if !ok {
+			return operations
+		}
+		operation.Fields = fields
+		operations = append(operations, operation)
+		i = next
+	}
+	return operations
+}
+
+// graphQLRootFields reads the selection set opening at tokens[start] and
+// returns its top-level fields (aliases resolved, spreads skipped) and the
+// index after the closing brace
+func graphQLRootFields(tokens []string, start int) ([]string, int, bool) {
+	var fields []string
+	braces, parens := 0, 0
+	for i := start; i < len(tokens); i++ {
+		token := tokens[i]
+		switch token {
+		case "{":
+			braces++
+			continue
+		case "}":
+			braces--
+			if braces == 0 {
+				return fields, i + 1, len(fields) > 0
+			}
+			continue
+		case "(":
+			parens++
+			continue
+		case ")":
+			parens--
+			continue
+		}
+		if braces != 1 || parens != 0 || !isGraphQLName(token) {
+			continue
+		}
+		previous := tokens[i-1]
+		if previous == "..." || previous == "@" || previous == "on" || previous == "$" {
+			continue
+		}
+		if i+1 < len(tokens) && tokens[i+1] == ":" {
+			continue // Alias; the field name follows
+		}
+		if !slices.Contains(fields, token) {
+			fields = append(fields, token)
+		}
+	}
+	return nil, len(tokens), false
+}
+
+// Split a document into names and punctuators, dropping comments, strings,
+// numbers and commas
+func graphQLTokens(document string) []string {
+	var tokens []string
+	for i := 0; i < len(document); {
+		c := document[i]
+		switch {
+		case c == '#':
+			for i < len(document) && document[i] != '\n' {
+				i++
+			}
+		case c == '"':
+			i++
+			for i < len(document) && document[i] != '"' {
+				if document[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case strings.HasPrefix(document[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		case strings.IndexByte("{}():@$!=[]", c) >= 0:
+			tokens = append(tokens, string(c))
+			i++
+		case c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+			start := i
+			for i < len(document) && (document[i] == '_' || document[i] >= 'A' && document[i] <= 'Z' ||
+				document[i] >= 'a' && document[i] <= 'z' || document[i] >= '0' && document[i] <= '9') {
+				i++
+			}
+			tokens = append(tokens, document[start:i])
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+func isGraphQLName(token string) bool {
+	c := token[0]
+	return c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+func (a *AggregatedResults) FormatGraphQL() []string {
+	order := map[string]int{"query": 0, "mutation": 1, "subscription": 2, "fragment": 3}
+	sorted := append([]GraphQLOperation(nil), a.GraphQL...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Type != sorted[j].Type {
+			return order[sorted[i].Type] < order[sorted[j].Type]
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var lines []string
+	for _, operation := range sorted {
+		name := operation.Name
+		if name == "" {
+			name = "(anonymous)"
+		}
+		lines = append(lines, fmt.Sprintf("%s | %s | %s | %s", operation.Type, name, strings.Join(operation.Fields, ", "), operation.File))
+	}
+	return lines
+}
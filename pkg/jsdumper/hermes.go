@@ -0,0 +1,159 @@
+package jsdumper
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Hermes bytecode (React Native apps built with Hermes ship their
+// index.android.bundle or main.jsbundle compiled) keeps every string literal
+// and identifier of the app in one string table. DecompileHermes recovers it
+// as JavaScript the detectors can read.
+
+// hermesMagic starts every Hermes bytecode file
+const hermesMagic = "\xC6\x1F\xBC\x03\xC1\x03\x19\x1F"
+
+const (
+	hermesHeaderSize     = 128
+	hermesFuncHeaderSize = 16
+	hermesMinVersion     = 59 // First version with the string kinds table
+	hermesOverflowLength = 0xFF
+)
+
+// Shortest printable run kept when the string table can't be parsed
+const minPrintableRun = 6
+
+// IsHermesBytecode reports whether content is a Hermes bytecode file
+func IsHermesBytecode(content string) bool {
+	return strings.HasPrefix(content, hermesMagic)
+}
+
+// DecompileHermes is a Transformer that replaces Hermes bytecode with its
+// string table, one string literal per line in table order; other content is
+// returned as it is. Versions whose layout isn't understood fall back to the
+// printable runs of the file, like strings(1).
+func DecompileHermes(ctx context.Context, fileName, content string) (string, error) {
+	if !IsHermesBytecode(content) {
+		return content, nil
+	}
+	strs, err := HermesStrings(content)
+	if err != nil {
+		strs = printableRuns(content)
+	}
+
+	var b strings.Builder
+	for _, s := range strs {
+		b.WriteString(strconv.Quote(s))
+		b.WriteString(";\n")
+	}
+	return b.String(), nil
+}
+
+// HermesStrings decodes the string table of Hermes bytecode. The header
+// fields it needs, and the sections before the string storage, have kept
+// their layout since version 59.
+func HermesStrings(content string) ([]string, error) {
+	if len(content) < hermesHeaderSize || !IsHermesBytecode(content) {
+		return nil, fmt.Errorf("not Hermes bytecode")
+	}
+	data := []byte(content)
+	u32 := func(offset int) int {
+		return int(binary.LittleEndian.Uint32(data[offset:]))
+	}
+
+	version := u32(8)
+	if version < hermesMinVersion {
+		return nil, fmt.Errorf("unsupported Hermes bytecode version %d", version)
+	}
+	functionCount, stringKindCount, identifierCount := u32(40), u32(44), u32(48)
+	stringCount, overflowCount, storageSize := u32(52), u32(56), u32(60)
+
+	// Sections follow the header in order, each aligned to 4 bytes
+	offset := hermesHeaderSize
+	section := func(count, size int) (int, error) {
+		start := (offset + 3) &^ 3
+		if count < 0 || count > len(data)/size || start+count*size > len(data) {
+			return 0, fmt.Errorf("Hermes bytecode version %d: section out of bounds", version)
+		}
+		offset = start + count*size
+		return start, nil
+	}
+	var tables [6]int
+	for i, s := range [][2]int{
+		{functionCount, hermesFuncHeaderSize},
+		{stringKindCount, 4},
+		{identifierCount, 4},
+		{stringCount, 4},   // Small string table
+		{overflowCount, 8}, // Overflow string table
+		{storageSize, 1},   // String storage
+	} {
+		start, err := section(s[0], s[1])
+		if err != nil {
+			return nil, err
+		}
+		tables[i] = start
+	}
+	smallTable, overflowTable, storage := tables[3], tables[4], tables[5]
+
+	strs := make([]string, 0, stringCount)
+	for i := 0; i < stringCount; i++ {
+		// Bit 0: UTF-16, bits 1-23: offset, bits 24-31: length
+		entry := u32(smallTable + i*4)
+		isUTF16 := entry&1 == 1
+		start, length := entry>>1&0x7FFFFF, entry>>24
+		if length == hermesOverflowLength {
+			if start >= overflowCount {
+				return nil, fmt.Errorf("Hermes bytecode version %d: string %d out of bounds", version, i)
+			}
+			start, length = u32(overflowTable+start*8), u32(overflowTable+start*8+4)
+		}
+		size := length
+		if isUTF16 {
+			size *= 2
+		}
+		if start+size > storageSize {
+			return nil, fmt.Errorf("Hermes bytecode version %d: string %d out of bounds", version, i)
+		}
+		raw := data[storage+start : storage+start+size]
+
+		if isUTF16 {
+			units := make([]uint16, length)
+			for j := range units {
+				units[j] = binary.LittleEndian.Uint16(raw[j*2:])
+			}
+			strs = append(strs, string(utf16.Decode(units)))
+			continue
+		}
+		// One-byte strings are Latin-1
+		runes := make([]rune, len(raw))
+		for j, c := range raw {
+			runes[j] = rune(c)
+		}
+		strs = append(strs, string(runes))
+	}
+	return strs, nil
+}
+
+// printableRuns lists the runs of printable ASCII of at least minPrintableRun
+// characters in content
+func printableRuns(content string) []string {
+	var runs []string
+	start := -1
+	for i := 0; i <= len(content); i++ {
+		if i < len(content) && content[i] >= 0x20 && content[i] < 0x7F {
+			if start == -1 {
+				start = i
+			}
+			continue
+		}
+		if start != -1 && i-start >= minPrintableRun {
+			runs = append(runs, content[start:i])
+		}
+		start = -1
+	}
+	return runs
+}
@@ -0,0 +1,208 @@
+package jsdumper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// HostFamily is a set of hostnames differing only by an environment or
+// region token (api-eu.acme.io, api-us.acme.io), or a hostname the code
+// builds from such a variable (`https://api-${region}.acme.io`). Derived are
+// the members implied by the family that weren't seen, as candidates to
+// resolve and probe.
+type HostFamily struct {
+	Pattern  string   `json:"pattern"` // e.g. api-{region}.acme.io
+	Kind     string   `json:"kind"`    // env or region
+	Observed []string `json:"observed"`
+	Template bool     `json:"template"` // Built from a variable in code
+	Derived  []string `json:"derived"`
+}
+
+// Environment tokens recognized in hostnames, and those derived for a family
+var (
+	hostEnvTokens = map[string]bool{
+		"dev": true, "development": true, "staging": true, "stage": true, "stg": true,
+		"qa": true, "test": true, "uat": true, "sandbox": true, "preprod": true,
+		"prod": true, "production": true, "demo": true, "beta": true, "int": true,
+	}
+	derivedEnvs = []string{"dev", "test", "qa", "uat", "staging", "preprod", "sandbox", "prod"}
+)
+
+// Region tokens recognized in hostnames, and those derived for a family.
+// Cloud-style regions (eu-west-1) are derived from their own list.
+var (
+	hostRegionTokens = map[string]bool{
+		"eu": true, "us": true, "uk": true, "ap": true, "apac": true, "emea": true,
+		"na": true, "latam": true, "asia": true, "au": true, "ca": true, "jp": true,
+		"sg": true, "br": true, "in": true,
+	}
+	derivedRegions      = []string{"us", "eu", "uk", "ap", "au", "ca", "jp", "sg", "br", "in"}
+	derivedCloudRegions = []string{
+		"us-east-1", "us-east-2", "us-west-1", "us-west-2", "ca-central-1", "sa-east-1",
+		"eu-west-1", "eu-west-2", "eu-west-3", "eu-central-1", "eu-north-1",
+		"ap-south-1", "ap-southeast-1", "ap-southeast-2", "ap-northeast-1", "ap-northeast-2",
+	}
+	cloudRegion = regexp.MustCompile(`\b(?:us|eu|ap|ca|sa|me|af|il|mx)-(?:east|west|north|south|central|northeast|southeast|northwest|southwest)-\d\b`)
+)
+
+// Variable names that hold an environment or a region
+var (
+	envVariable    = regexp.MustCompile(`(?i)env|stage|stg|tier`)
+	regionVariable = regexp.MustCompile(`(?i)region|zone|geo|locale|datacenter`)
+)
+
+// extractHostTemplates returns the hostnames built from an environment or
+// region variable, with the variable replaced by {env} or {region}
+func (e *Extractor) extractHostTemplates(run *extraction, content string) []string {
+	var templates []string
+	seen := make(map[string]bool)
+
+	for _, match := range run.findAllSubmatch("hostTemplate", e.patterns.HostTemplate, content) {
+		prefix, variable, suffix := strings.ToLower(match[1]), firstGroup(match[2:4]), strings.ToLower(match[4])
+		// The last name segment: config.region -> region
+		variable = variable[strings.LastIndex(variable, ".")+1:]
+
+		var placeholder string
+		switch {
+		case regionVariable.MatchString(variable):
+			placeholder = "{region}"
+		case envVariable.MatchString(variable):
+			placeholder = "{env}"
+		default:
+			continue
+		}
+		template := prefix + placeholder + suffix
+		if !seen[template] {
+			templates = append(templates, template)
+			seen[template] = true
+		}
+	}
+
+	return templates
+}
+
+// hostFamilies groups the hostnames of URLs and WebSocket URLs into
+// families and adds the families of host templates
+func (a *AggregatedResults) hostFamilies() []HostFamily {
+	members := make(map[string][]string) // Pattern -> observed hosts
+	var hosts []string
+	for _, url := range append(slices.Clone(a.URLs), a.WebSockets...) {
+		host := strings.ToLower(hostOf(url))
+		if host != "" && !slices.Contains(hosts, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	for _, host := range hosts {
+		for _, pattern := range hostPatterns(host) {
+			members[pattern] = append(members[pattern], host)
+		}
+	}
+
+	var families []HostFamily
+	for pattern, observed := range members {
+		// One host alone is no family
+		if len(observed) > 1 || slices.Contains(a.HostTemplates, pattern) {
+			families = append(families, newHostFamily(pattern, observed, slices.Contains(a.HostTemplates, pattern)))
+		}
+	}
+	for _, template := range a.HostTemplates {
+		if _, ok := members[template]; !ok {
+			families = append(families, newHostFamily(template, nil, true))
+		}
+	}
+
+	sort.Slice(families, func(i, j int) bool { return families[i].Pattern < families[j].Pattern })
+	return families
+}
+
+// hostPatterns returns the family patterns host belongs to: one per
+// environment or region token, outside the top-level domain
+func hostPatterns(host string) []string {
+	var patterns []string
+	cloud := cloudRegion.FindStringIndex(host)
+	if cloud != nil {
+		patterns = append(patterns, host[:cloud[0]]+"{region}"+host[cloud[1]:])
+	}
+
+	tld := strings.LastIndex(host, ".")
+	if tld < 0 {
+		return patterns
+	}
+	start := 0
+	for i := 0; i <= tld; i++ {
+		if i < tld && host[i] != '.' && host[i] != '-' {
+			continue
+		}
+		token := host[start:i]
+		switch {
+		case cloud != nil && start >= cloud[0] && i <= cloud[1]:
+			// Part of eu-west-1
+		case hostEnvTokens[token]:
+			patterns = append(patterns, host[:start]+"{env}"+host[i:])
+		case hostRegionTokens[token]:
+			patterns = append(patterns, host[:start]+"{region}"+host[i:])
+		}
+		start = i + 1
+	}
+	return patterns
+}
+
+func newHostFamily(pattern string, observed []string, template bool) HostFamily {
+	family := HostFamily{Pattern: pattern, Kind: "env", Observed: observed, Template: template}
+	candidates := derivedEnvs
+	if strings.Contains(pattern, "{region}") {
+		family.Kind = "region"
+		candidates = derivedRegions
+		if len(observed) > 0 && cloudRegion.MatchString(observed[0]) || strings.Contains(pattern, "amazonaws.com") {
+			candidates = derivedCloudRegions
+		}
+	}
+	if family.Observed == nil {
+		family.Observed = []string{}
+	}
+	sort.Strings(family.Observed)
+
+	family.Derived = []string{}
+	for _, candidate := range candidates {
+		host := strings.Replace(pattern, "{"+family.Kind+"}", candidate, 1)
+		if !slices.Contains(observed, host) {
+			family.Derived = append(family.Derived, host)
+		}
+	}
+	return family
+}
+
+// FormatDerivedHosts lists the derived hosts of all families, once each
+func (a *AggregatedResults) FormatDerivedHosts() []string {
+	var lines []string
+	seen := make(map[string]bool)
+	for _, family := range a.HostFamilies {
+		for _, host := range family.Derived {
+			if !seen[host] {
+				lines = append(lines, host)
+				seen[host] = true
+			}
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func (a *AggregatedResults) WriteHostFamilies(filePath string) error {
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"jsdumper": CurrentBuildInfo(),
+		"families": a.HostFamilies,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode host families: %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write host families: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package jsdumper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InfraReference is a source-control, CI or artifact reference in bundle
+// strings (INFRA_REFERENCE): exposed .git/ and .env files, compose files,
+// CI servers and package or container registries, which often lead to
+// follow-up findings
+type InfraReference struct {
+	Kind  string // git, env, docker-compose, jenkins, gitlab-ci, teamcity or registry
+	Value string
+	File  string
+}
+
+// Public hosts every build talks to, not infrastructure of the target: package
+// registries and mirrors, and the Jenkins project site
+var publicInfraHosts = map[string]bool{
+	"registry.npmjs.org":      true,
+	"registry.npmjs.com":      true,
+	"registry.yarnpkg.com":    true,
+	"registry.npmmirror.com":  true,
+	"registry.hub.docker.com": true,
+	"jenkins.io":              true,
+	"www.jenkins.io":          true,
+	"plugins.jenkins.io":      true,
+}
+
+// Dotfiles that must be a path segment of their own: "app.git" is a
+// repository URL and "process.env" a variable
+var infraDotfiles = map[string]string{"git": ".git", "env": ".env"}
+
+func (e *Extractor) extractInfraReferences(run *extraction, content, fileName string) []InfraReference {
+	var refs []InfraReference
+	seen := make(map[string]bool)
+
+	for _, p := range e.patterns.Infra {
+		if run.canceled() {
+			break
+		}
+		for _, match := range run.findAllSubmatch(p.name, p.pattern, content) {
+			value := strings.TrimRight(match[1], ".,;:)]}")
+			if dotfile, ok := infraDotfiles[p.kind]; ok && !strings.Contains(value, "/"+dotfile) && !strings.HasPrefix(value, dotfile) {
+				continue
+			}
+			if publicInfraHosts[strings.ToLower(hostOf(value))] {
+				continue
+			}
+
+			key := p.kind + ":" + value
+			if seen[key] {
+				continue
+			}
+			refs = append(refs, InfraReference{Kind: p.kind, Value: value, File: fileName})
+			seen[key] = true
+		}
+	}
+
+	return refs
+}
+
+// hostOf returns the host of a URL or host[:port]/path reference
+func hostOf(value string) string {
+	if _, rest, ok := strings.Cut(value, "://"); ok {
+		value = rest
+	}
+	host, _, _ := strings.Cut(value, "/")
+	host, _, _ = strings.Cut(host, ":")
+	return host
+}
+
+func (a *AggregatedResults) FormatInfraReferences() []string {
+	var lines []string
+	for _, ref := range a.InfraReferences {
+		lines = append(lines, fmt.Sprintf("%s | %s | %s", ref.Kind, ref.Value, ref.File))
+	}
+	return lines
+}
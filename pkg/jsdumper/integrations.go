@@ -0,0 +1,99 @@
+package jsdumper
+
+import (
+	"encoding/json"
+	"fmt"
+	urlpkg "net/url"
+	"os"
+	"strings"
+)
+
+// Integration is an embedded third-party surface: an iframe, a sign-in or
+// payment widget, or a CAPTCHA/bot protection service, with the IDs it is
+// configured with
+type Integration struct {
+	Kind     string `json:"-"` // iframe, oauth, payment or antibot
+	Provider string `json:"provider"`
+	Value    string `json:"value"`             // Frame URL, script URL, client/app ID or publishable key
+	Account  string `json:"account,omitempty"` // Linked account, e.g. a Stripe Connect acct_ ID
+	File     string `json:"file"`
+}
+
+func (e *Extractor) extractIntegrations(run *extraction, content, fileName string) []Integration {
+	var integrations []Integration
+	seen := make(map[string]bool)
+
+	for _, p := range e.patterns.Integrations {
+		if run.canceled() {
+			break
+		}
+		for _, match := range run.findAllSubmatch(p.name, p.pattern, content) {
+			value := match[0]
+			if len(match) > 1 {
+				value = match[1]
+			}
+			account := ""
+			if p.account > 0 && p.account < len(match) {
+				account = match[p.account]
+			}
+
+			provider := p.provider
+			if provider == "" {
+				provider = "self"
+				if parsed, err := urlpkg.Parse(value); err == nil && parsed.Host != "" {
+					provider = strings.ToLower(parsed.Hostname())
+				}
+			}
+
+			key := p.kind + ":" + provider + ":" + value + ":" + account
+			if value == "" || seen[key] {
+				continue
+			}
+			integrations = append(integrations, Integration{
+				Kind:     p.kind,
+				Provider: provider,
+				Value:    value,
+				Account:  account,
+				File:     fileName,
+			})
+			seen[key] = true
+		}
+	}
+
+	return integrations
+}
+
+// Write integrations.json, grouping integrations by kind
+func (a *AggregatedResults) WriteIntegrations(filePath string) error {
+	groups := map[string][]Integration{
+		"iframes":  {},
+		"oauth":    {},
+		"payments": {},
+		"antibot":  {},
+	}
+	for _, integration := range a.Integrations {
+		switch integration.Kind {
+		case "iframe":
+			groups["iframes"] = append(groups["iframes"], integration)
+		case "oauth":
+			groups["oauth"] = append(groups["oauth"], integration)
+		case "payment":
+			groups["payments"] = append(groups["payments"], integration)
+		case "antibot":
+			groups["antibot"] = append(groups["antibot"], integration)
+		}
+	}
+
+	output := map[string]interface{}{"jsdumper": CurrentBuildInfo()}
+	for kind, group := range groups {
+		output[kind] = group
+	}
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode integrations: %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write integrations: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package jsdumper
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// IPAddress is an IP literal found in the code
+type IPAddress struct {
+	Value string
+	Scope string // private, loopback, link-local, shared (CGNAT) or public
+	File  string
+}
+
+// Scopes in reporting order: internal infrastructure first
+var ipScopeOrder = map[string]int{"private": 0, "loopback": 1, "link-local": 2, "shared": 3, "public": 4}
+
+var (
+	sharedAddressSpace = mustParseCIDR("100.64.0.0/10") // RFC 6598 carrier-grade NAT
+	// Documentation ranges (RFC 5737, RFC 3849): examples, never infrastructure
+	documentationNets = []*net.IPNet{
+		mustParseCIDR("192.0.2.0/24"),
+		mustParseCIDR("198.51.100.0/24"),
+		mustParseCIDR("203.0.113.0/24"),
+		mustParseCIDR("2001:db8::/32"),
+	}
+)
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return ipNet
+}
+
+func (e *Extractor) extractIPs(run *extraction, content, fileName string) []IPAddress {
+	var ips []IPAddress
+	seen := make(map[string]bool)
+
+	matches := run.findAllSubmatch("ipv4", e.patterns.IPv4, content)
+	for _, match := range run.findAllSubmatch("ipv6", e.patterns.IPv6, content) {
+		// Compressed or full addresses only, so times (12:30:45) don't qualify
+		if strings.Contains(match[1], "::") || strings.Count(match[1], ":") == 7 {
+			matches = append(matches, match)
+		}
+	}
+
+	for _, match := range matches {
+		ip := net.ParseIP(match[1])
+		scope := ipScope(ip)
+		if scope == "" || seen[ip.String()] {
+			continue
+		}
+		ips = append(ips, IPAddress{Value: ip.String(), Scope: scope, File: fileName})
+		seen[ip.String()] = true
+	}
+
+	return ips
+}
+
+// Classify an address; "" for addresses that are not worth reporting
+// (unspecified, broadcast and masks, multicast, documentation examples)
+func ipScope(ip net.IP) string {
+	if ip == nil || ip.IsUnspecified() || ip.IsMulticast() || ip.Equal(net.IPv4bcast) {
+		return ""
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		// Netmasks such as 255.255.255.0 and versions such as 0.9.1.2
+		if ip4[0] == 0 || ip4[0] == 255 {
+			return ""
+		}
+	}
+	for _, ipNet := range documentationNets {
+		if ipNet.Contains(ip) {
+			return ""
+		}
+	}
+
+	switch {
+	case ip.IsPrivate():
+		return "private"
+	case ip.IsLoopback():
+		return "loopback"
+	case ip.IsLinkLocalUnicast():
+		return "link-local"
+	case sharedAddressSpace.Contains(ip):
+		return "shared"
+	}
+	return "public"
+}
+
+// Internal addresses: anything but public
+func (a *AggregatedResults) InternalIPs() int {
+	count := 0
+	for _, ip := range a.IPs {
+		if ip.Scope != "public" {
+			count++
+		}
+	}
+	return count
+}
+
+func (a *AggregatedResults) FormatIPs() []string {
+	sorted := append([]IPAddress(nil), a.IPs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Scope != sorted[j].Scope {
+			return ipScopeOrder[sorted[i].Scope] < ipScopeOrder[sorted[j].Scope]
+		}
+		return bytes.Compare(net.ParseIP(sorted[i].Value).To16(), net.ParseIP(sorted[j].Value).To16()) < 0
+	})
+
+	var lines []string
+	for _, ip := range sorted {
+		lines = append(lines, fmt.Sprintf("%s | %s | %s", ip.Scope, ip.Value, ip.File))
+	}
+	return lines
+}
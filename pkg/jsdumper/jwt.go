@@ -0,0 +1,122 @@
+package jsdumper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JWTInfo is what a JWT found in the code says about itself. Nothing is
+// verified: the claims are only decoded.
+type JWTInfo struct {
+	File      string     `json:"file"`
+	Algorithm string     `json:"alg"`
+	Issuer    string     `json:"iss,omitempty"`
+	Subject   string     `json:"sub,omitempty"`
+	Audience  []string   `json:"aud,omitempty"`
+	ExpiresAt *time.Time `json:"exp,omitempty"`
+	Expired   bool       `json:"expired"`
+	Unsigned  bool       `json:"unsigned"` // alg "none": servers accepting it skip verification
+}
+
+// decodeJWT decodes the header and claims of token; ok is false when the
+// token isn't valid base64url-encoded JSON
+func decodeJWT(token string, now time.Time) (*JWTInfo, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+
+	var header struct {
+		Algorithm string `json:"alg"`
+	}
+	var claims struct {
+		Issuer    string          `json:"iss"`
+		Subject   string          `json:"sub"`
+		Audience  json.RawMessage `json:"aud"` // A string or an array of strings
+		ExpiresAt *float64        `json:"exp"`
+	}
+	if !decodeJWTPart(parts[0], &header) || !decodeJWTPart(parts[1], &claims) {
+		return nil, false
+	}
+
+	info := &JWTInfo{
+		Algorithm: header.Algorithm,
+		Issuer:    claims.Issuer,
+		Subject:   claims.Subject,
+		Unsigned:  strings.EqualFold(header.Algorithm, "none") || parts[2] == "",
+	}
+	var audience string
+	if json.Unmarshal(claims.Audience, &audience) == nil && audience != "" {
+		info.Audience = []string{audience}
+	} else if err := json.Unmarshal(claims.Audience, &info.Audience); err != nil {
+		info.Audience = nil // Absent or malformed
+	}
+	if claims.ExpiresAt != nil {
+		expiresAt := time.Unix(int64(*claims.ExpiresAt), 0).UTC()
+		info.ExpiresAt = &expiresAt
+		info.Expired = expiresAt.Before(now)
+	}
+	return info, true
+}
+
+func decodeJWTPart(part string, v interface{}) bool {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(data, v) == nil
+}
+
+// String summarizes the token for keys.txt
+func (j *JWTInfo) String() string {
+	fields := []string{"alg=" + j.Algorithm}
+	if j.Unsigned {
+		fields[0] += " (unsigned)"
+	}
+	if j.Issuer != "" {
+		fields = append(fields, "iss="+j.Issuer)
+	}
+	if j.Subject != "" {
+		fields = append(fields, "sub="+j.Subject)
+	}
+	if len(j.Audience) > 0 {
+		fields = append(fields, "aud="+strings.Join(j.Audience, ","))
+	}
+	switch {
+	case j.ExpiresAt == nil:
+		fields = append(fields, "no expiry")
+	case j.Expired:
+		fields = append(fields, fmt.Sprintf("exp=%s (expired)", j.ExpiresAt.Format(time.RFC3339)))
+	default:
+		fields = append(fields, "exp="+j.ExpiresAt.Format(time.RFC3339))
+	}
+	return strings.Join(fields, " ")
+}
+
+// analyzeJWTs decodes the JWTs among secrets, describing each in its Detail
+func analyzeJWTs(secrets []Secret, now time.Time) {
+	for i := range secrets {
+		if secrets[i].Type != "JWT" {
+			continue
+		}
+		if info, ok := decodeJWT(secrets[i].Value, now); ok {
+			info.File = secrets[i].File
+			secrets[i].JWT = info
+			secrets[i].Detail = info.String()
+		}
+	}
+}
+
+// jwtInfos lists the decoded JWTs for summary.json
+func (a *AggregatedResults) jwtInfos() []*JWTInfo {
+	infos := []*JWTInfo{}
+	for _, secret := range a.Secrets {
+		if secret.JWT != nil {
+			infos = append(infos, secret.JWT)
+		}
+	}
+	return infos
+}
@@ -0,0 +1,163 @@
+package jsdumper
+
+import (
+	urlpkg "net/url"
+	"strings"
+)
+
+// Longest loader expression considered
+const maxLoaderExpr = 300
+
+// extractScriptLoaders finds scripts loaded at runtime through
+// createElement("script") + src and import()/importScripts(). Literal URLs
+// are resolved (against fileName when relative); computed ones, and relative
+// ones that can't be resolved, are returned as interesting hits for review.
+func (e *Extractor) extractScriptLoaders(run *extraction, content, fileName string) ([]string, []Interesting) {
+	var urls []string
+	var computed []Interesting
+	seen := make(map[string]bool)
+
+	add := func(kind string, start, exprStart int) {
+		if exprStart < len(content) && content[exprStart] == '=' {
+			return // A comparison (src == ...), not an assignment
+		}
+		expr, exprEnd := jsExpression(content, exprStart)
+		if expr == "" {
+			return
+		}
+		end := exprEnd
+		if end < len(content) && content[end] == ')' {
+			end++
+		}
+
+		if value, ok := stringLiteral(expr); ok {
+			resolved, ok := resolveScriptURL(value, fileName, run.opts.URLs)
+			if ok {
+				if !seen[resolved] {
+					urls = append(urls, resolved)
+					seen[resolved] = true
+				}
+				return
+			}
+			if !isRelativePath(value) {
+				return // A bare module name, e.g. import("lodash")
+			}
+			// A relative path, but no page URL to resolve it against: review it too
+		}
+
+		context := strings.Join(strings.Fields(strings.ToValidUTF8(content[start:end], "")), " ")
+		if !seen[kind+":"+context] {
+			computed = append(computed, Interesting{Keyword: kind, File: fileName, Context: context})
+			seen[kind+":"+context] = true
+		}
+	}
+
+	for _, loc := range run.findAllSubmatchIndex("scriptSrc", e.patterns.ScriptSrc, content) {
+		add("dynamic-script", loc[2], loc[3])
+	}
+	if run.canceled() {
+		return urls, computed
+	}
+	for _, loc := range run.findAllSubmatchIndex("dynamicImport", e.patterns.DynamicImport, content) {
+		add("dynamic-import", loc[0], loc[1])
+	}
+
+	return urls, computed
+}
+
+// jsExpression returns the JavaScript expression starting at content[start:],
+// up to the first top-level , ; ) ] } or newline, and where it ends
+func jsExpression(content string, start int) (string, int) {
+	for start < len(content) && (content[start] == ' ' || content[start] == '\t') {
+		start++
+	}
+
+	depth := 0
+	var quote byte
+	i := start
+	for ; i < len(content) && i-start < maxLoaderExpr; i++ {
+		c := content[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth == 0 {
+				return strings.TrimSpace(content[start:i]), i
+			}
+			depth--
+		case ',', ';', '\n':
+			if depth == 0 {
+				return strings.TrimSpace(content[start:i]), i
+			}
+		}
+	}
+	if i-start >= maxLoaderExpr || quote != 0 || depth != 0 {
+		return "", i
+	}
+	return strings.TrimSpace(content[start:i]), i
+}
+
+// stringLiteral returns the value of expr when it is a single string literal
+// (a template literal without substitutions counts)
+func stringLiteral(expr string) (string, bool) {
+	if len(expr) < 2 {
+		return "", false
+	}
+	quote := expr[0]
+	if (quote != '"' && quote != '\'' && quote != '`') || expr[len(expr)-1] != quote {
+		return "", false
+	}
+	inner := expr[1 : len(expr)-1]
+	for i := 0; i < len(inner); i++ {
+		if inner[i] == '\\' {
+			i++
+		} else if inner[i] == quote {
+			return "", false
+		}
+	}
+	if quote == '`' && strings.Contains(inner, "${") {
+		return "", false
+	}
+	return inner, true
+}
+
+func isRelativePath(value string) bool {
+	return strings.HasPrefix(value, "/") || strings.HasPrefix(value, "./") || strings.HasPrefix(value, "../")
+}
+
+// Resolve a loaded script URL; relative paths need fileName to be a URL
+// (inline page scripts), and bare module names aren't URLs at all
+func resolveScriptURL(value, fileName string, normalization URLNormalization) (string, bool) {
+	if strings.HasPrefix(value, "//") {
+		value = "https:" + value
+	}
+	if canonical, ok := canonicalURL(value); ok {
+		return normalizeURL(canonical, normalization), true
+	}
+
+	if !isRelativePath(value) {
+		return "", false
+	}
+	base, err := urlpkg.Parse(fileName)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") {
+		return "", false
+	}
+	ref, err := urlpkg.Parse(value)
+	if err != nil {
+		return "", false
+	}
+	if canonical, ok := canonicalURL(base.ResolveReference(ref).String()); ok {
+		return normalizeURL(canonical, normalization), true
+	}
+	return "", false
+}
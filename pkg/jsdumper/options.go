@@ -0,0 +1,134 @@
+package jsdumper
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Detector categories that can be enabled in Options
+const (
+	DetectorSecrets      = "secrets"
+	DetectorEndpoints    = "endpoints"
+	DetectorURLs         = "urls"
+	DetectorInteresting  = "interesting"
+	DetectorIntegrations = "integrations"
+	DetectorBuckets      = "buckets"
+	DetectorConfig       = "config"
+	DetectorBindings     = "bindings"
+	DetectorIPs          = "ips"
+	DetectorGraphQL      = "graphql"
+	DetectorPersisted    = "persisted-queries"
+	DetectorDOMSinks     = "dom-sinks"
+	DetectorWebSockets   = "websockets"
+	DetectorHTTPClients  = "http-clients"
+	DetectorInfra        = "infra"
+	DetectorChunks       = "chunks"
+)
+
+// EntropyConfig holds the minimum Shannon entropy a candidate needs before
+// the matching context-based detector reports it
+type EntropyConfig struct {
+	ClientID     float64 // client_id and authorization server IDs
+	ClientSecret float64
+	Token        float64 // bearer/token assignments
+	APIKey       float64
+	Password     float64
+}
+
+// URLNormalization selects the rewrites applied to URLs before they are
+// deduplicated, so cosmetically different duplicates collapse into one
+type URLNormalization struct {
+	LowercaseHost    bool // HTTPS://API.Example.com -> https://api.example.com
+	StripDefaultPort bool // :80 on http, :443 on https
+	SortQuery        bool // ?b=2&a=1 -> ?a=1&b=2
+	StripTracking    bool // utm_*, gclid, fbclid, ...
+}
+
+// Default URL normalizations; sorting the query is opt-in since parameter
+// order can matter to the server
+var defaultURLNormalization = URLNormalization{LowercaseHost: true, StripDefaultPort: true, StripTracking: true}
+
+// Parse a -normalize-urls list: host, port, query, tracking, or none
+func ParseURLNormalization(names []string) (URLNormalization, error) {
+	var n URLNormalization
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case "host":
+			n.LowercaseHost = true
+		case "port":
+			n.StripDefaultPort = true
+		case "query":
+			n.SortQuery = true
+		case "tracking":
+			n.StripTracking = true
+		case "none":
+		default:
+			return n, fmt.Errorf("unknown URL normalization %q (expected host, port, query, tracking or none)", name)
+		}
+	}
+	return n, nil
+}
+
+// Options bounds and tunes a single ExtractAll call
+type Options struct {
+	// MaxMatches caps the matches taken from each pattern (0 = unlimited)
+	MaxMatches int
+	// PatternTimeout caps the time spent on each pattern (0 = unlimited)
+	PatternTimeout time.Duration
+	// Detectors lists the enabled categories (empty = all)
+	Detectors []string
+	// Fast runs only the prefix-based secret and main endpoint patterns, in
+	// one combined pass (-fast); other detectors are skipped
+	Fast    bool
+	Entropy EntropyConfig
+	URLs    URLNormalization
+	// Tags label the results and every finding, e.g. the environment or
+	// business unit of the target (-tag)
+	Tags []string
+	// Now is the clock JWT expiry is checked against (nil = time.Now); set
+	// it to a fixed time for reproducible results
+	Now func() time.Time
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Entropy: EntropyConfig{
+			ClientID:     3.5,
+			ClientSecret: 4.0,
+			Token:        4.5,
+			APIKey:       4.5,
+			Password:     3.0,
+		},
+		URLs: defaultURLNormalization,
+	}
+}
+
+// Check whether a detector category is enabled
+func (o *Options) enabled(detector string) bool {
+	if len(o.Detectors) == 0 {
+		return true
+	}
+	for _, d := range o.Detectors {
+		if d == detector {
+			return true
+		}
+	}
+	return false
+}
+
+// Time to check JWT expiry against
+func (o *Options) now() time.Time {
+	if o.Now == nil {
+		return time.Now()
+	}
+	return o.Now()
+}
+
+// Match limit in the form regexp's FindAll functions expect
+func (o *Options) limit() int {
+	if o.MaxMatches <= 0 {
+		return -1
+	}
+	return o.MaxMatches
+}
@@ -0,0 +1,331 @@
+package jsdumper
+
+import (
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// PDF actions run JavaScript from a /JS entry: a string in the action's
+// dictionary, or a reference to a stream holding the script. Dictionaries
+// may sit in compressed object streams, and names may be written with #xx
+// escapes (/J#53) to hide them from scanners.
+
+var (
+	pdfObjectHeader = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	pdfNameEscape   = regexp.MustCompile(`/[^\s/<>\[\]()]*#[0-9A-Fa-f]{2}[^\s/<>\[\]()]*`)
+	pdfJSKey        = regexp.MustCompile(`/(?:J|#4[Aa])(?:S|#53)\s*`)
+	pdfStreamStart  = regexp.MustCompile(`>>\s*stream(\r\n|\n|\r)`)
+	pdfReference    = regexp.MustCompile(`^(\d+)\s+\d+\s+R\b`)
+	pdfFilter       = regexp.MustCompile(`/Filter\s*(\[[^\]]*\]|/[A-Za-z0-9]+)`)
+	pdfInteger      = regexp.MustCompile(`/(N|First)\s+(\d+)`)
+)
+
+// pdfObject is an indirect object: its dictionary or value, and its decoded
+// stream when it has one
+type pdfObject struct {
+	body      string
+	stream    string
+	hasStream bool
+}
+
+// PDFScripts returns the scripts of the /JS entries of a PDF, named by the
+// object holding the script (obj12)
+func PDFScripts(content string) ([]EmbeddedScript, error) {
+	if !IsPDF(content) {
+		return nil, fmt.Errorf("not a PDF")
+	}
+	objects := pdfObjects(content)
+
+	var scripts []EmbeddedScript
+	seen := make(map[string]bool)
+	add := func(name, script string) {
+		script = strings.TrimSpace(script)
+		if script == "" || seen[name] {
+			return
+		}
+		seen[name] = true
+		scripts = append(scripts, EmbeddedScript{Name: name, Content: script})
+	}
+
+	for _, num := range sortedKeys(objects) {
+		body := objects[num].body
+		for i, loc := range pdfJSKey.FindAllStringIndex(body, -1) {
+			value := body[loc[1]:]
+			switch {
+			case strings.HasPrefix(value, "("):
+				add(pdfScriptName(num, i), pdfDecodeText(pdfLiteralString(value)))
+			case strings.HasPrefix(value, "<") && !strings.HasPrefix(value, "<<"):
+				add(pdfScriptName(num, i), pdfDecodeText(pdfHexString(value)))
+			default:
+				ref := pdfReference.FindStringSubmatch(value)
+				if ref == nil {
+					continue
+				}
+				target, ok := objects[ref[1]]
+				if !ok {
+					continue
+				}
+				// Several actions can run the same script object
+				if target.hasStream {
+					add("obj"+ref[1], pdfDecodeText(target.stream))
+				} else if body := strings.TrimSpace(target.body); strings.HasPrefix(body, "(") {
+					add("obj"+ref[1], pdfDecodeText(pdfLiteralString(body)))
+				}
+			}
+		}
+	}
+
+	if len(scripts) == 0 && strings.Contains(content, "/Encrypt") {
+		return nil, fmt.Errorf("encrypted PDF: embedded scripts can't be read")
+	}
+	return scripts, nil
+}
+
+// IsPDF reports whether content is a PDF file
+func IsPDF(content string) bool {
+	return strings.HasPrefix(content, "%PDF-")
+}
+
+func pdfScriptName(num string, i int) string {
+	if i == 0 {
+		return "obj" + num
+	}
+	return fmt.Sprintf("obj%s_%d", num, i+1)
+}
+
+// pdfObjects indexes the indirect objects of a PDF by number, including the
+// objects packed in object streams. Incremental updates append new versions
+// of objects, so a later definition replaces an earlier one.
+func pdfObjects(content string) map[string]*pdfObject {
+	objects := make(map[string]*pdfObject)
+	headers := pdfObjectHeader.FindAllStringSubmatchIndex(content, -1)
+	for i, header := range headers {
+		end := len(content)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		raw := content[header[1]:end]
+		if j := strings.LastIndex(raw, "endobj"); j != -1 {
+			raw = raw[:j]
+		}
+
+		// Only stream dictionaries are normalized: other objects hold strings,
+		// where # is a character like any other
+		object := &pdfObject{body: raw}
+		if loc := pdfStreamStart.FindStringIndex(raw); loc != nil {
+			object.body = pdfNormalizeNames(raw[:loc[0]+2])
+			data := raw[loc[1]:]
+			if k := strings.LastIndex(data, "endstream"); k != -1 {
+				data = data[:k]
+			}
+			object.stream = pdfDecodeStream(object.body, data)
+			object.hasStream = true
+		}
+		objects[content[header[2]:header[3]]] = object
+	}
+
+	for _, object := range objects {
+		if object.hasStream && strings.Contains(object.body, "/ObjStm") {
+			for num, packed := range pdfObjectStream(object) {
+				if _, ok := objects[num]; !ok {
+					objects[num] = packed
+				}
+			}
+		}
+	}
+	return objects
+}
+
+// pdfObjectStream unpacks an object stream: a header of object number and
+// offset pairs, then the objects from offset /First
+func pdfObjectStream(object *pdfObject) map[string]*pdfObject {
+	values := make(map[string]int)
+	for _, match := range pdfInteger.FindAllStringSubmatch(object.body, -1) {
+		values[match[1]], _ = strconv.Atoi(match[2])
+	}
+	count, first := values["N"], values["First"]
+	if first <= 0 || first > len(object.stream) {
+		return nil
+	}
+	fields := strings.Fields(object.stream[:first])
+	if count > len(fields)/2 {
+		count = len(fields) / 2
+	}
+
+	packed := make(map[string]*pdfObject)
+	for i := 0; i < count; i++ {
+		start, err := strconv.Atoi(fields[i*2+1])
+		if err != nil || first+start > len(object.stream) {
+			continue
+		}
+		end := len(object.stream)
+		if i+1 < count {
+			if next, err := strconv.Atoi(fields[i*2+3]); err == nil && next >= start && first+next <= end {
+				end = first + next
+			}
+		}
+		packed[fields[i*2]] = &pdfObject{body: object.stream[first+start : end]}
+	}
+	return packed
+}
+
+// pdfDecodeStream applies the filters of a stream's dictionary in order.
+// Filters that aren't supported leave the data as it is; a damaged Flate
+// stream keeps what could be inflated.
+func pdfDecodeStream(dict, data string) string {
+	match := pdfFilter.FindStringSubmatch(dict)
+	if match == nil {
+		return data
+	}
+	for _, filter := range strings.Fields(strings.NewReplacer("[", " ", "]", " ", "/", " /").Replace(match[1])) {
+		switch filter {
+		case "/FlateDecode", "/Fl":
+			reader, err := zlib.NewReader(strings.NewReader(data))
+			if err != nil {
+				return data
+			}
+			inflated, _ := io.ReadAll(io.LimitReader(reader, maxDecompressedSize))
+			data = string(inflated)
+		case "/ASCIIHexDecode", "/AHx":
+			data = pdfHexString("<" + data)
+		case "/ASCII85Decode", "/A85":
+			encoded := strings.TrimSuffix(strings.TrimSpace(data), "~>")
+			decoded := make([]byte, len(encoded))
+			n, _, _ := ascii85.Decode(decoded, []byte(strings.TrimPrefix(encoded, "<~")), true)
+			data = string(decoded[:n])
+		default:
+			return data
+		}
+	}
+	return data
+}
+
+// pdfNormalizeNames decodes the #xx escapes of the names in a dictionary
+func pdfNormalizeNames(dict string) string {
+	return pdfNameEscape.ReplaceAllStringFunc(dict, func(name string) string {
+		var b strings.Builder
+		for i := 0; i < len(name); i++ {
+			if name[i] == '#' && i+2 < len(name) {
+				if decoded, err := hex.DecodeString(name[i+1 : i+3]); err == nil {
+					b.Write(decoded)
+					i += 2
+					continue
+				}
+			}
+			b.WriteByte(name[i])
+		}
+		return b.String()
+	})
+}
+
+// pdfLiteralString decodes the (...) string starting value: parentheses
+// nest, and backslash escapes a character, an octal code or a line break
+func pdfLiteralString(value string) string {
+	var b strings.Builder
+	depth := 0
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch c {
+		case '(':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				return b.String()
+			}
+		case '\\':
+			i++
+			if i >= len(value) {
+				return b.String()
+			}
+			switch e := value[i]; e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				if e == '\r' && i+1 < len(value) && value[i+1] == '\n' {
+					i++
+				}
+				continue
+			default:
+				if e < '0' || e > '7' {
+					c = e
+					break
+				}
+				code := 0
+				for j := 0; j < 3 && i < len(value) && value[i] >= '0' && value[i] <= '7'; j++ {
+					code = code*8 + int(value[i]-'0')
+					i++
+				}
+				i--
+				c = byte(code)
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// pdfHexString decodes the <...> string starting value; whitespace is
+// ignored and a missing final digit is 0
+func pdfHexString(value string) string {
+	end := strings.IndexByte(value, '>')
+	if end == -1 {
+		end = len(value)
+	}
+	digits := strings.Join(strings.Fields(value[1:end]), "")
+	if len(digits)%2 == 1 {
+		digits += "0"
+	}
+	decoded, err := hex.DecodeString(digits)
+	if err != nil {
+		return ""
+	}
+	return string(decoded)
+}
+
+// pdfDecodeText decodes text strings, which are UTF-16BE when they start
+// with a byte order mark
+func pdfDecodeText(s string) string {
+	if !strings.HasPrefix(s, "\xFE\xFF") {
+		return strings.TrimPrefix(s, "\xEF\xBB\xBF")
+	}
+	data := []byte(s[2:])
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = uint16(data[i*2])<<8 | uint16(data[i*2+1])
+	}
+	return string(utf16.Decode(units))
+}
+
+// sortedKeys returns the object numbers of objects in numeric order
+func sortedKeys(objects map[string]*pdfObject) []string {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := strconv.Atoi(keys[i])
+		b, _ := strconv.Atoi(keys[j])
+		return a < b
+	})
+	return keys
+}
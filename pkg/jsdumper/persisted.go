@@ -0,0 +1,92 @@
+package jsdumper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PersistedQuery is a GraphQL operation the client sends by hash instead of
+// by document. Servers that only accept persisted queries run it when sent
+// the hash, so the map from hash to operation is what a tester needs to
+// replay it.
+type PersistedQuery struct {
+	Hash   string `json:"hash"`
+	Type   string `json:"type"` // query, mutation or subscription
+	Name   string `json:"name,omitempty"`
+	Query  string `json:"query,omitempty"` // The document, when bundled with its hash
+	Source string `json:"source"`          // apollo-manifest, relay, codegen or map
+	File   string `json:"file"`
+}
+
+func (q PersistedQuery) key() string {
+	return q.Hash + "|" + q.File
+}
+
+func (e *Extractor) extractPersistedQueries(run *extraction, content, fileName string) []PersistedQuery {
+	var queries []PersistedQuery
+	index := make(map[string]int) // Hash -> position in queries
+
+	// The same hash can appear in several forms, e.g. a Relay artifact and
+	// the persisted map: keep one entry, with the document when any has it
+	add := func(query PersistedQuery) {
+		query.File = fileName
+		if i, ok := index[query.Hash]; ok {
+			if queries[i].Query == "" && query.Query != "" {
+				queries[i].Query = query.Query
+			}
+			if queries[i].Name == "" {
+				queries[i].Name = query.Name
+			}
+			return
+		}
+		index[query.Hash] = len(queries)
+		queries = append(queries, query)
+	}
+
+	// Apollo persisted query manifests (generate-persisted-query-manifest)
+	for _, match := range run.findAllSubmatch("persistedManifest", e.patterns.PersistedManifest, content) {
+		add(PersistedQuery{Hash: match[1], Name: match[2], Type: match[3], Query: unescapeJSString(match[4]), Source: "apollo-manifest"})
+	}
+
+	// Hash -> document maps; the operation is the document's first one that
+	// isn't a fragment
+	for _, match := range run.findAllSubmatch("persistedMap", e.patterns.PersistedMap, content) {
+		document := unescapeJSString(firstGroup(match[2:]))
+		for _, operation := range parseGraphQL(document, false) {
+			if operation.Type != "fragment" {
+				add(PersistedQuery{Hash: match[1], Name: operation.Name, Type: operation.Type, Query: strings.TrimSpace(document), Source: "map"})
+				break
+			}
+		}
+	}
+
+	// Relay artifacts compiled with persisted queries: the id replaces the text
+	for _, match := range run.findAllSubmatch("persistedRelay", e.patterns.PersistedRelay, content) {
+		add(PersistedQuery{Hash: match[1], Name: match[2], Type: match[3], Source: "relay"})
+	}
+
+	// graphql-codegen documents carrying their persisted hash
+	for _, match := range run.findAllSubmatch("persistedCodegen", e.patterns.PersistedCodegen, content) {
+		add(PersistedQuery{Hash: match[1], Type: match[2], Name: match[3], Source: "codegen"})
+	}
+
+	return queries
+}
+
+// Write persisted-queries.json
+func (a *AggregatedResults) WritePersistedQueries(filePath string) error {
+	output := map[string]interface{}{
+		"jsdumper": CurrentBuildInfo(),
+		"queries":  a.PersistedQueries,
+	}
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode persisted queries: %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write persisted queries: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package jsdumper
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+)
+
+// Transformer rewrites content before extraction, e.g. a deobfuscator or
+// unpacker. Returning an error skips the remaining transformers; extraction
+// then runs on the content as it was before the failing stage.
+type Transformer func(ctx context.Context, fileName, content string) (string, error)
+
+// ResultStage filters or enriches the results of one file after extraction.
+// Stages run in order and may modify results in place.
+type ResultStage func(ctx context.Context, results *Results) error
+
+// DetectStage adds findings of its own to the results of one file, e.g. an
+// external Plugin. It sees the content extraction ran on.
+type DetectStage func(ctx context.Context, fileName, content string, results *Results) error
+
+// Pipeline wraps an Extractor with middleware stages, so consumers can
+// extend the scan without reimplementing it:
+//
+//	pipeline := NewPipeline(NewExtractor()).
+//		Transform(unpack).
+//		Detect(plugin.Detect).
+//		Use(dropVendorURLs)
+//	results, err := pipeline.Run(ctx, content, "app.js", DefaultOptions())
+type Pipeline struct {
+	extractor    *Extractor
+	transformers []Transformer
+	detectors    []DetectStage
+	stages       []ResultStage
+}
+
+func NewPipeline(extractor *Extractor) *Pipeline {
+	return &Pipeline{extractor: extractor}
+}
+
+// Transform appends a pre-extraction stage
+func (p *Pipeline) Transform(t Transformer) *Pipeline {
+	p.transformers = append(p.transformers, t)
+	return p
+}
+
+// Extractor is the extractor the pipeline runs
+func (p *Pipeline) Extractor() *Extractor {
+	return p.extractor
+}
+
+// Detect appends a detector, run after the built-in ones and before the
+// result stages
+func (p *Pipeline) Detect(d DetectStage) *Pipeline {
+	p.detectors = append(p.detectors, d)
+	return p
+}
+
+// Use appends a post-extraction stage
+func (p *Pipeline) Use(stage ResultStage) *Pipeline {
+	p.stages = append(p.stages, stage)
+	return p
+}
+
+// Run transforms content, extracts from it and passes the results through
+// the stages. As with ExtractAll, partial results are returned with the error.
+func (p *Pipeline) Run(ctx context.Context, content, fileName string, opts Options) (*Results, error) {
+	var transformErr error
+	for i, transform := range p.transformers {
+		transformed, err := transform(ctx, fileName, content)
+		if err != nil {
+			transformErr = fmt.Errorf("transformer %d failed: %w", i+1, err)
+			break
+		}
+		content = transformed
+	}
+
+	results, err := p.extractor.ExtractAll(ctx, content, fileName, opts)
+	if err != nil {
+		return results, err
+	}
+
+	// A failing detector only loses its own findings
+	var detectErr error
+	for i, detect := range p.detectors {
+		if err := detect(ctx, fileName, content, results); err != nil && detectErr == nil {
+			detectErr = fmt.Errorf("detector %d failed: %w", i+1, err)
+		}
+	}
+	if len(p.detectors) > 0 {
+		results.ImportantEndpoints = p.extractor.extractImportantEndpoints(results.Endpoints)
+	}
+
+	for i, stage := range p.stages {
+		if err := stage(ctx, results); err != nil {
+			return results, fmt.Errorf("result stage %d failed: %w", i+1, err)
+		}
+	}
+	return results, cmp.Or(transformErr, detectErr)
+}
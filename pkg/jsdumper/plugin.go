@@ -0,0 +1,113 @@
+package jsdumper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Time a plugin gets per file before it is killed
+const pluginTimeout = time.Minute
+
+// Plugin is an external detector (-plugin): a command that receives the
+// content of each file on stdin, with its name in JSDUMPER_FILE, and prints
+// its findings as JSON on stdout:
+//
+//	{
+//	  "secrets": [{"type": "ACME_TOKEN", "value": "...", "severity": "HIGH", "line": 12}],
+//	  "endpoints": ["/internal/v2/export"],
+//	  "urls": ["https://billing.acme.internal/"]
+//	}
+//
+// Every key is optional; secrets without a severity are MEDIUM.
+type Plugin struct {
+	command string
+}
+
+type pluginOutput struct {
+	Secrets []struct {
+		Type     string `json:"type"`
+		Value    string `json:"value"`
+		Severity string `json:"severity"`
+		Line     int    `json:"line"`
+		Detail   string `json:"detail"`
+	} `json:"secrets"`
+	Endpoints []string `json:"endpoints"`
+	URLs      []string `json:"urls"`
+}
+
+func NewPlugin(command string) (*Plugin, error) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return nil, fmt.Errorf("plugin needs a command")
+	}
+	return &Plugin{command: command}, nil
+}
+
+// Detect runs the plugin over content and adds its findings to results; it
+// is meant for Pipeline.Detect
+func (p *Plugin) Detect(ctx context.Context, fileName, content string, results *Results) error {
+	ctx, cancel := context.WithTimeout(ctx, pluginTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", p.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", p.command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), "JSDUMPER_FILE="+fileName)
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			err = fmt.Errorf("%w: %s", err, message)
+		}
+		return fmt.Errorf("plugin %q failed: %w", p.command, err)
+	}
+
+	var output pluginOutput
+	if stdout.Len() > 0 {
+		if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+			return fmt.Errorf("plugin %q printed invalid JSON: %w", p.command, err)
+		}
+	}
+
+	for _, secret := range output.Secrets {
+		if secret.Type == "" || secret.Value == "" {
+			continue
+		}
+		severity := strings.ToUpper(secret.Severity)
+		if !validSeverities[severity] {
+			severity = "MEDIUM"
+		}
+		results.Secrets = append(results.Secrets, Secret{
+			Type:     secret.Type,
+			File:     fileName,
+			Value:    secret.Value,
+			Severity: severity,
+			Line:     max(secret.Line, 0),
+			Detail:   secret.Detail,
+		})
+	}
+	for _, endpoint := range output.Endpoints {
+		if endpoint != "" && !slices.Contains(results.Endpoints, endpoint) {
+			results.Endpoints = append(results.Endpoints, endpoint)
+		}
+	}
+	for _, url := range output.URLs {
+		if url != "" && !slices.Contains(results.URLs, url) {
+			results.URLs = append(results.URLs, url)
+		}
+	}
+	return nil
+}
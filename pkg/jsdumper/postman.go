@@ -0,0 +1,232 @@
+package jsdumper
+
+import (
+	"encoding/json"
+	"fmt"
+	urlpkg "net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Minimal Postman Collection v2.1 object model (https://schema.postman.com/)
+
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Auth     postmanAuth       `json:"auth"`
+	Variable []postmanKeyValue `json:"variable"`
+	Item     []postmanFolder   `json:"item"`
+}
+
+type postmanInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+	Schema      string `json:"schema"`
+}
+
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []postmanKeyValue `json:"bearer"`
+}
+
+type postmanKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+type postmanFolder struct {
+	Name string        `json:"name"`
+	Item []postmanItem `json:"item"`
+}
+
+type postmanItem struct {
+	Name    string         `json:"name"`
+	Request postmanRequest `json:"request"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Header []postmanKeyValue `json:"header"`
+	URL    postmanURL        `json:"url"`
+}
+
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Protocol string            `json:"protocol,omitempty"`
+	Host     []string          `json:"host"`
+	Port     string            `json:"port,omitempty"`
+	Path     []string          `json:"path"`
+	Query    []postmanKeyValue `json:"query,omitempty"`
+	Variable []postmanKeyValue `json:"variable,omitempty"`
+}
+
+// Folder of the endpoints found without a host, requested against {{baseUrl}}
+const postmanBaseURLFolder = "{{baseUrl}}"
+
+// Path segments that are values rather than names: numeric IDs, UUIDs and hashes
+var (
+	numericSegment = regexp.MustCompile(`^[0-9]+$`)
+	uuidSegment    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hashSegment    = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+)
+
+// postmanPath splits a path into segments, turning route parameters
+// (:id, {id}) and literal IDs into Postman path variables
+func postmanPath(path string) ([]string, []postmanKeyValue) {
+	var segments []string
+	var variables []postmanKeyValue
+	used := make(map[string]int)
+
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		name, value := "", ""
+		switch {
+		case strings.HasPrefix(segment, ":") && len(segment) > 1:
+			name = segment[1:]
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") && len(segment) > 2:
+			name = segment[1 : len(segment)-1]
+		case numericSegment.MatchString(segment):
+			name, value = "id", segment
+		case uuidSegment.MatchString(segment):
+			name, value = "uuid", segment
+		case hashSegment.MatchString(segment):
+			name, value = "hash", segment
+		default:
+			segments = append(segments, segment)
+			continue
+		}
+		// The same name twice (/users/1/posts/2) gets a numbered suffix
+		used[name]++
+		if used[name] > 1 {
+			name = fmt.Sprintf("%s%d", name, used[name])
+		}
+		segments = append(segments, ":"+name)
+		variables = append(variables, postmanKeyValue{Key: name, Value: value})
+	}
+	return segments, variables
+}
+
+// postmanQuery keeps the query parameters of a URL in their original order
+func postmanQuery(rawQuery string) []postmanKeyValue {
+	var query []postmanKeyValue
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		query = append(query, postmanKeyValue{Key: key, Value: value})
+	}
+	return query
+}
+
+// postmanItems builds one request per method the code uses (GET when unknown)
+func postmanItems(methods []string, url postmanURL) []postmanItem {
+	if len(methods) == 0 {
+		methods = []string{"GET"}
+	}
+	display := "/" + strings.Join(url.Path, "/")
+	var items []postmanItem
+	for _, method := range methods {
+		items = append(items, postmanItem{
+			Name:    method + " " + display,
+			Request: postmanRequest{Method: method, Header: []postmanKeyValue{}, URL: url},
+		})
+	}
+	return items
+}
+
+// buildPostman groups the URLs by host, and the endpoints that no URL
+// covers under {{baseUrl}}, set to the scanned origin when there is one
+func (a *AggregatedResults) buildPostman(name, baseURL string) postmanCollection {
+	folders := make(map[string][]postmanItem)
+	covered := make(map[string]bool)
+
+	for _, raw := range a.URLs {
+		u, err := urlpkg.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+		endpoint := normalizeEndpoint(u.EscapedPath())
+		covered[endpoint] = true
+
+		path, variables := postmanPath(u.EscapedPath())
+		url := postmanURL{
+			Protocol: u.Scheme,
+			Host:     strings.Split(u.Hostname(), "."),
+			Port:     u.Port(),
+			Path:     path,
+			Query:    postmanQuery(u.RawQuery),
+			Variable: variables,
+		}
+		url.Raw = u.Scheme + "://" + u.Host + "/" + strings.Join(path, "/")
+		if u.RawQuery != "" {
+			url.Raw += "?" + u.RawQuery
+		}
+		folders[u.Host] = append(folders[u.Host], postmanItems(a.EndpointMethods[endpoint], url)...)
+	}
+
+	for _, endpoint := range a.Endpoints {
+		if covered[endpoint] {
+			continue
+		}
+		path, variables := postmanPath(endpoint)
+		url := postmanURL{
+			Raw:      postmanBaseURLFolder + "/" + strings.Join(path, "/"),
+			Host:     []string{postmanBaseURLFolder},
+			Path:     path,
+			Variable: variables,
+		}
+		folders[postmanBaseURLFolder] = append(folders[postmanBaseURLFolder], postmanItems(a.EndpointMethods[endpoint], url)...)
+	}
+
+	hosts := make([]string, 0, len(folders))
+	for host := range folders {
+		if host != postmanBaseURLFolder {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	if folders[postmanBaseURLFolder] != nil {
+		hosts = append(hosts, postmanBaseURLFolder)
+	}
+
+	collection := postmanCollection{
+		Info: postmanInfo{
+			Name:        name,
+			Description: fmt.Sprintf("Endpoints found by jsdumper %s (patterns %s). Methods default to GET where the code doesn't tell; set the token variable for authenticated requests.", version, CurrentBuildInfo().Patterns),
+			Version:     version,
+			Schema:      "https://schema.getpostman.com/json/collection/v2.1.0/collection.json",
+		},
+		Auth: postmanAuth{
+			Type:   "bearer",
+			Bearer: []postmanKeyValue{{Key: "token", Value: "{{token}}", Type: "string"}},
+		},
+		Variable: []postmanKeyValue{
+			{Key: "baseUrl", Value: baseURL},
+			{Key: "token", Value: ""},
+		},
+		Item: []postmanFolder{},
+	}
+	for _, host := range hosts {
+		collection.Item = append(collection.Item, postmanFolder{Name: host, Item: folders[host]})
+	}
+	return collection
+}
+
+// Write a Postman collection of the endpoints; baseURL is the origin of the
+// scanned target, if it is a URL
+func (a *AggregatedResults) WritePostman(filePath, name, baseURL string) error {
+	data, err := json.MarshalIndent(a.buildPostman(name, baseURL), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal Postman collection: %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write Postman collection: %w", err)
+	}
+	return nil
+}
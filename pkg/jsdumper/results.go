@@ -0,0 +1,641 @@
+package jsdumper
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type AggregatedResults struct {
+	Secrets            []Secret
+	Endpoints          []string
+	EndpointMethods    map[string][]string
+	ImportantEndpoints []string
+	URLs               []string
+	HostTemplates      []string
+	HostFamilies       []HostFamily
+	WebSockets         []string
+	Interesting        []Interesting
+	Integrations       []Integration
+	Buckets            []Bucket
+	InfraReferences    []InfraReference
+	ConfigExposures    []ConfigExposure
+	Bindings           []Binding
+	IPs                []IPAddress
+	GraphQL            []GraphQLOperation
+	PersistedQueries   []PersistedQuery
+	DOMSinks           []DOMSink
+	HTTPClients        []HTTPClient
+	ChunkNames         []ChunkName
+	Base64Encoded      []string // Endpoints and URLs only found in base64 literals
+	Suppressed         int
+	OverBudget         []string
+	Warnings           []Warning
+	Tags               []string // Tags of every scanned input, run-wide and per input
+	Targets            []TargetRisk
+	RiskScore          int
+	Coverage           string // "full", or "fast" when only the -fast detectors ran
+
+	// Files each endpoint and URL was found in, in input order
+	endpointFiles map[string][]string
+	urlFiles      map[string][]string
+	// Tags of each tagged input
+	fileTags map[string][]string
+}
+
+// TagGroup is the share of a run's findings that came from inputs with a tag
+type TagGroup struct {
+	Tag       string `json:"tag"`
+	Files     int    `json:"files"`
+	RiskScore int    `json:"riskScore"` // Of the riskiest input with the tag
+	Secrets   int    `json:"secrets"`
+	Endpoints int    `json:"endpoints"`
+	URLs      int    `json:"urls"`
+}
+
+// Aggregator merges the results of inputs one at a time, so they don't all
+// have to be in memory when outputs are written (-max-memory spills them)
+type Aggregator struct {
+	aggregated *AggregatedResults
+	targets    map[string]int // Risk score per target, summed over its chunks
+
+	endpointSet          map[string]bool
+	importantEndpointSet map[string]bool
+	urlSet               map[string]bool
+	webSocketSet         map[string]bool
+	secretSet            map[string]bool
+	interestingSet       map[string]bool
+	integrationSet       map[string]bool
+	bucketSet            map[string]bool
+	infraSet             map[string]bool
+	exposureSet          map[string]bool
+	bindingSet           map[string]bool
+	ipSet                map[string]bool
+	graphQLSet           map[string]bool
+	persistedSet         map[string]bool
+	domSinkSet           map[string]bool
+	httpClientSet        map[string]bool
+	chunkNameSet         map[string]bool
+	base64Set            map[string]bool
+	overBudgetSet        map[string]bool
+	warningSet           map[string]bool
+	tagSet               map[string]bool
+}
+
+// NewAggregator returns an Aggregator with no results yet
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		aggregated: &AggregatedResults{
+			Secrets:            []Secret{},
+			Endpoints:          []string{},
+			EndpointMethods:    make(map[string][]string),
+			ImportantEndpoints: []string{},
+			URLs:               []string{},
+			Interesting:        []Interesting{},
+			Warnings:           []Warning{},
+			Tags:               []string{},
+			Coverage:           "full",
+			endpointFiles:      make(map[string][]string),
+			urlFiles:           make(map[string][]string),
+			fileTags:           make(map[string][]string),
+		},
+		targets:              make(map[string]int),
+		endpointSet:          make(map[string]bool),
+		importantEndpointSet: make(map[string]bool),
+		urlSet:               make(map[string]bool),
+		webSocketSet:         make(map[string]bool),
+		secretSet:            make(map[string]bool),
+		interestingSet:       make(map[string]bool),
+		integrationSet:       make(map[string]bool),
+		bucketSet:            make(map[string]bool),
+		infraSet:             make(map[string]bool),
+		exposureSet:          make(map[string]bool),
+		bindingSet:           make(map[string]bool),
+		ipSet:                make(map[string]bool),
+		graphQLSet:           make(map[string]bool),
+		persistedSet:         make(map[string]bool),
+		domSinkSet:           make(map[string]bool),
+		httpClientSet:        make(map[string]bool),
+		chunkNameSet:         make(map[string]bool),
+		base64Set:            make(map[string]bool),
+		overBudgetSet:        make(map[string]bool),
+		warningSet:           make(map[string]bool),
+		tagSet:               make(map[string]bool),
+	}
+}
+
+// Add merges the results of an input; a nil result is ignored
+func (g *Aggregator) Add(result *Results) {
+	if result == nil {
+		return
+	}
+	g.targets[result.File] += riskScore(result)
+	for _, endpoint := range result.Endpoints {
+		if !slices.Contains(g.aggregated.endpointFiles[endpoint], result.File) {
+			g.aggregated.endpointFiles[endpoint] = append(g.aggregated.endpointFiles[endpoint], result.File)
+		}
+	}
+	for _, url := range result.URLs {
+		if !slices.Contains(g.aggregated.urlFiles[url], result.File) {
+			g.aggregated.urlFiles[url] = append(g.aggregated.urlFiles[url], result.File)
+		}
+	}
+
+	g.aggregated.Suppressed += result.Suppressed
+
+	for _, name := range result.OverBudget {
+		if !g.overBudgetSet[name] {
+			g.aggregated.OverBudget = append(g.aggregated.OverBudget, name)
+			g.overBudgetSet[name] = true
+		}
+	}
+
+	if len(result.Tags) > 0 {
+		g.aggregated.fileTags[result.File] = unionTags(g.aggregated.fileTags[result.File], result.Tags)
+	}
+	for _, tag := range result.Tags {
+		if !g.tagSet[tag] {
+			g.aggregated.Tags = append(g.aggregated.Tags, tag)
+			g.tagSet[tag] = true
+		}
+	}
+
+	for _, warning := range result.Warnings {
+		if !g.warningSet[warning.key()] {
+			g.aggregated.Warnings = append(g.aggregated.Warnings, warning)
+			g.warningSet[warning.key()] = true
+		}
+	}
+
+	// Aggregate secrets
+	for _, secret := range result.Secrets {
+		key := secret.Type + ":" + secret.Value
+		if !g.secretSet[key] {
+			g.aggregated.Secrets = append(g.aggregated.Secrets, secret)
+			g.secretSet[key] = true
+		}
+	}
+
+	// Aggregate endpoints
+	for _, endpoint := range result.Endpoints {
+		if !g.endpointSet[endpoint] {
+			g.aggregated.Endpoints = append(g.aggregated.Endpoints, endpoint)
+			g.endpointSet[endpoint] = true
+		}
+	}
+	for endpoint, methods := range result.EndpointMethods {
+		for _, method := range methods {
+			addMethod(g.aggregated.EndpointMethods, endpoint, method)
+		}
+	}
+
+	// Aggregate important endpoints
+	for _, endpoint := range result.ImportantEndpoints {
+		if !g.importantEndpointSet[endpoint] {
+			g.aggregated.ImportantEndpoints = append(g.aggregated.ImportantEndpoints, endpoint)
+			g.importantEndpointSet[endpoint] = true
+		}
+	}
+
+	// Aggregate URLs
+	for _, url := range result.URLs {
+		if !g.urlSet[url] {
+			g.aggregated.URLs = append(g.aggregated.URLs, url)
+			g.urlSet[url] = true
+		}
+	}
+
+	// Aggregate hostname templates
+	for _, template := range result.HostTemplates {
+		if !slices.Contains(g.aggregated.HostTemplates, template) {
+			g.aggregated.HostTemplates = append(g.aggregated.HostTemplates, template)
+		}
+	}
+
+	// Aggregate WebSocket URLs
+	for _, url := range result.WebSockets {
+		if !g.webSocketSet[url] {
+			g.aggregated.WebSockets = append(g.aggregated.WebSockets, url)
+			g.webSocketSet[url] = true
+		}
+	}
+
+	// Aggregate interesting strings
+	for _, hit := range result.Interesting {
+		key := hit.Keyword + ":" + hit.File + ":" + hit.Context
+		if !g.interestingSet[key] {
+			g.aggregated.Interesting = append(g.aggregated.Interesting, hit)
+			g.interestingSet[key] = true
+		}
+	}
+
+	// Aggregate integrations
+	for _, integration := range result.Integrations {
+		key := integration.Kind + ":" + integration.Provider + ":" + integration.Value + ":" + integration.Account
+		if !g.integrationSet[key] {
+			g.aggregated.Integrations = append(g.aggregated.Integrations, integration)
+			g.integrationSet[key] = true
+		}
+	}
+
+	// Aggregate buckets
+	for _, bucket := range result.Buckets {
+		key := bucket.Provider + ":" + bucket.Name
+		if !g.bucketSet[key] {
+			g.aggregated.Buckets = append(g.aggregated.Buckets, bucket)
+			g.bucketSet[key] = true
+		}
+	}
+
+	// Aggregate infrastructure references
+	for _, ref := range result.InfraReferences {
+		key := ref.Kind + ":" + ref.Value
+		if !g.infraSet[key] {
+			g.aggregated.InfraReferences = append(g.aggregated.InfraReferences, ref)
+			g.infraSet[key] = true
+		}
+	}
+
+	// Aggregate config exposures
+	for _, exposure := range result.ConfigExposures {
+		key := exposure.Kind + ":" + exposure.Header + ":" + exposure.Value
+		if !g.exposureSet[key] {
+			g.aggregated.ConfigExposures = append(g.aggregated.ConfigExposures, exposure)
+			g.exposureSet[key] = true
+		}
+	}
+
+	// Aggregate worker bindings
+	for _, binding := range result.Bindings {
+		key := binding.Kind + ":" + binding.Name + ":" + binding.ID
+		if !g.bindingSet[key] {
+			g.aggregated.Bindings = append(g.aggregated.Bindings, binding)
+			g.bindingSet[key] = true
+		}
+	}
+
+	// Aggregate IP addresses
+	for _, ip := range result.IPs {
+		if !g.ipSet[ip.Value] {
+			g.aggregated.IPs = append(g.aggregated.IPs, ip)
+			g.ipSet[ip.Value] = true
+		}
+	}
+
+	// Aggregate GraphQL operations
+	for _, operation := range result.GraphQL {
+		key := operation.Type + ":" + operation.Name + ":" + strings.Join(operation.Fields, ",")
+		if !g.graphQLSet[key] {
+			g.aggregated.GraphQL = append(g.aggregated.GraphQL, operation)
+			g.graphQLSet[key] = true
+		}
+	}
+
+	// Aggregate persisted queries
+	for _, query := range result.PersistedQueries {
+		if !g.persistedSet[query.key()] {
+			g.aggregated.PersistedQueries = append(g.aggregated.PersistedQueries, query)
+			g.persistedSet[query.key()] = true
+		}
+	}
+
+	// Aggregate DOM XSS sinks
+	for _, sink := range result.DOMSinks {
+		key := sink.Sink + ":" + sink.File + ":" + sink.Context
+		if !g.domSinkSet[key] {
+			g.aggregated.DOMSinks = append(g.aggregated.DOMSinks, sink)
+			g.domSinkSet[key] = true
+		}
+	}
+
+	// Aggregate axios instances
+	for _, client := range result.HTTPClients {
+		key := client.key()
+		if !g.httpClientSet[key] {
+			g.aggregated.HTTPClients = append(g.aggregated.HTTPClients, client)
+			g.httpClientSet[key] = true
+		}
+	}
+
+	// Aggregate chunk names
+	for _, chunk := range result.ChunkNames {
+		if !g.chunkNameSet[chunk.key()] {
+			g.aggregated.ChunkNames = append(g.aggregated.ChunkNames, chunk)
+			g.chunkNameSet[chunk.key()] = true
+		}
+	}
+
+	// Aggregate base64-encoded endpoints and URLs
+	for _, value := range result.Base64Encoded {
+		if !g.base64Set[value] {
+			g.aggregated.Base64Encoded = append(g.aggregated.Base64Encoded, value)
+			g.base64Set[value] = true
+		}
+	}
+}
+
+// Aggregated returns the merged results, sorted; the Aggregator must not be
+// used afterwards
+func (g *Aggregator) Aggregated() *AggregatedResults {
+	aggregated := g.aggregated
+
+	// Rank targets by risk; the overall score is that of the riskiest one
+	var targets []TargetRisk
+	for target, score := range g.targets {
+		targets = append(targets, TargetRisk{Target: target, Score: score})
+	}
+	aggregated.Targets = rankTargets(targets)
+	if len(aggregated.Targets) > 0 {
+		aggregated.RiskScore = aggregated.Targets[0].Score
+	}
+
+	aggregated.sortFindings()
+	// Grouped before a baseline hides known URLs, so families stay whole
+	aggregated.HostFamilies = aggregated.hostFamilies()
+	return aggregated
+}
+
+// unionTags returns the tags of a and b, sorted and without duplicates
+func unionTags(a, b []string) []string {
+	tags := slices.Concat(a, b)
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
+
+// TagsOf returns the tags of the inputs a finding came from: those of file,
+// or for endpoints and URLs, which carry no file once aggregated, those of
+// every file value was found in
+func (a *AggregatedResults) TagsOf(file, value string) []string {
+	files := []string{file}
+	if file == "" {
+		files = slices.Concat(a.endpointFiles[value], a.urlFiles[value])
+	}
+	var tags []string
+	for _, name := range files {
+		tags = unionTags(tags, a.fileTags[name])
+	}
+	return tags
+}
+
+// TagGroups counts the findings of each tag, for slicing a run by business
+// unit or environment. Findings of inputs with several tags count in each.
+func (a *AggregatedResults) TagGroups() []TagGroup {
+	groups := make(map[string]*TagGroup)
+	group := func(tag string) *TagGroup {
+		if groups[tag] == nil {
+			groups[tag] = &TagGroup{Tag: tag}
+		}
+		return groups[tag]
+	}
+	for _, target := range a.Targets {
+		for _, tag := range a.fileTags[target.Target] {
+			g := group(tag)
+			g.Files++
+			g.RiskScore = max(g.RiskScore, target.Score)
+		}
+	}
+	for _, secret := range a.Secrets {
+		for _, tag := range a.TagsOf(secret.File, secret.Value) {
+			group(tag).Secrets++
+		}
+	}
+	for _, endpoint := range a.Endpoints {
+		for _, tag := range a.TagsOf("", endpoint) {
+			group(tag).Endpoints++
+		}
+	}
+	for _, url := range a.URLs {
+		for _, tag := range a.TagsOf("", url) {
+			group(tag).URLs++
+		}
+	}
+
+	tagGroups := []TagGroup{}
+	for _, tag := range a.Tags {
+		tagGroups = append(tagGroups, *group(tag))
+	}
+	return tagGroups
+}
+
+// Aggregate merges the results of every input
+func Aggregate(results []*Results) *AggregatedResults {
+	aggregator := NewAggregator()
+	for _, result := range results {
+		aggregator.Add(result)
+	}
+	return aggregator.Aggregated()
+}
+
+// sortFindings orders every output independently of processing order and
+// thread scheduling, so diffs between runs only show real changes. Secrets
+// come most severe first.
+func (a *AggregatedResults) sortFindings() {
+	sort.Strings(a.Endpoints)
+	sort.Strings(a.ImportantEndpoints)
+	sort.Strings(a.URLs)
+	sort.Strings(a.WebSockets)
+	sort.Strings(a.Base64Encoded)
+	sort.Strings(a.OverBudget)
+	sort.Strings(a.Tags)
+	for _, methods := range a.EndpointMethods {
+		sort.Strings(methods)
+	}
+
+	sort.SliceStable(a.Secrets, func(i, j int) bool {
+		x, y := a.Secrets[i], a.Secrets[j]
+		return cmp.Or(
+			cmp.Compare(severityWeights[y.Severity], severityWeights[x.Severity]),
+			strings.Compare(x.Type, y.Type),
+			strings.Compare(x.File, y.File),
+			cmp.Compare(x.Line, y.Line),
+			strings.Compare(x.Value, y.Value),
+		) < 0
+	})
+	sort.SliceStable(a.Interesting, func(i, j int) bool {
+		x, y := a.Interesting[i], a.Interesting[j]
+		return cmp.Or(strings.Compare(x.Keyword, y.Keyword), strings.Compare(x.File, y.File), strings.Compare(x.Context, y.Context)) < 0
+	})
+	sort.SliceStable(a.Integrations, func(i, j int) bool {
+		x, y := a.Integrations[i], a.Integrations[j]
+		return cmp.Or(strings.Compare(x.Kind, y.Kind), strings.Compare(x.Provider, y.Provider), strings.Compare(x.Value, y.Value), strings.Compare(x.File, y.File)) < 0
+	})
+	sort.SliceStable(a.ConfigExposures, func(i, j int) bool {
+		x, y := a.ConfigExposures[i], a.ConfigExposures[j]
+		return cmp.Or(strings.Compare(x.Kind, y.Kind), strings.Compare(x.Header, y.Header), strings.Compare(x.Value, y.Value), strings.Compare(x.File, y.File)) < 0
+	})
+	sort.SliceStable(a.InfraReferences, func(i, j int) bool {
+		x, y := a.InfraReferences[i], a.InfraReferences[j]
+		return cmp.Or(strings.Compare(x.Kind, y.Kind), strings.Compare(x.Value, y.Value), strings.Compare(x.File, y.File)) < 0
+	})
+	sort.SliceStable(a.Bindings, func(i, j int) bool {
+		x, y := a.Bindings[i], a.Bindings[j]
+		return cmp.Or(strings.Compare(x.Kind, y.Kind), strings.Compare(x.Name, y.Name), strings.Compare(x.ID, y.ID), strings.Compare(x.File, y.File)) < 0
+	})
+	sort.SliceStable(a.PersistedQueries, func(i, j int) bool {
+		x, y := a.PersistedQueries[i], a.PersistedQueries[j]
+		return cmp.Or(strings.Compare(x.Name, y.Name), strings.Compare(x.Hash, y.Hash), strings.Compare(x.File, y.File)) < 0
+	})
+	sort.SliceStable(a.DOMSinks, func(i, j int) bool {
+		x, y := a.DOMSinks[i], a.DOMSinks[j]
+		return cmp.Or(strings.Compare(x.File, y.File), cmp.Compare(x.Line, y.Line), strings.Compare(x.Sink, y.Sink), strings.Compare(x.Context, y.Context)) < 0
+	})
+	sort.SliceStable(a.HTTPClients, func(i, j int) bool {
+		x, y := a.HTTPClients[i], a.HTTPClients[j]
+		return cmp.Or(strings.Compare(x.Name, y.Name), strings.Compare(x.key(), y.key()), strings.Compare(x.File, y.File)) < 0
+	})
+	sort.SliceStable(a.ChunkNames, func(i, j int) bool {
+		x, y := a.ChunkNames[i], a.ChunkNames[j]
+		return cmp.Or(strings.Compare(x.Name, y.Name), strings.Compare(x.Route, y.Route), strings.Compare(x.Chunk, y.Chunk), strings.Compare(x.File, y.File)) < 0
+	})
+	sort.SliceStable(a.Warnings, func(i, j int) bool {
+		x, y := a.Warnings[i], a.Warnings[j]
+		return cmp.Or(strings.Compare(x.File, y.File), strings.Compare(x.Kind, y.Kind), strings.Compare(x.Detail, y.Detail)) < 0
+	})
+}
+
+func (a *AggregatedResults) FormatSecrets() []string {
+	var lines []string
+	for _, secret := range a.Secrets {
+		// Multi-line values (PEM blocks) stay on one line
+		value := strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(secret.Value)
+		line := fmt.Sprintf("%s | %s | %s", secret.Type, secret.File, value)
+		if secret.Encoding != "" {
+			line += " | " + secret.Encoding + "-encoded"
+		}
+		if secret.Detail != "" {
+			line += " | " + secret.Detail
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (a *AggregatedResults) FormatEndpoints() []string {
+	return a.Endpoints
+}
+
+func (a *AggregatedResults) FormatImportantEndpoints() []string {
+	return a.ImportantEndpoints
+}
+
+func (a *AggregatedResults) FormatURLs() []string {
+	return a.URLs
+}
+
+func (a *AggregatedResults) FormatInteresting() []string {
+	var lines []string
+	for _, hit := range a.Interesting {
+		lines = append(lines, fmt.Sprintf("%s | %s | %s", hit.Keyword, hit.File, hit.Context))
+	}
+	return lines
+}
+
+func (a *AggregatedResults) WriteJSON(filePath string) error {
+	// Count secrets by type and severity
+	byType := make(map[string]int)
+	criticalCount := 0
+	highCount := 0
+	mediumCount := 0
+	lowCount := 0
+
+	for _, secret := range a.Secrets {
+		byType[secret.Type]++
+		switch secret.Severity {
+		case "CRITICAL":
+			criticalCount++
+		case "HIGH":
+			highCount++
+		case "MEDIUM":
+			mediumCount++
+		case "LOW":
+			lowCount++
+		}
+	}
+
+	summary := map[string]interface{}{
+		"jsdumper":  CurrentBuildInfo(),
+		"timestamp": time.Now().Format(time.RFC3339),
+		"coverage":  a.Coverage,
+		"tags":      a.Tags,
+		"byTag":     a.TagGroups(),
+		"risk": map[string]interface{}{
+			"score":   a.RiskScore,
+			"targets": a.Targets,
+		},
+		"secrets": map[string]interface{}{
+			"total":      len(a.Secrets),
+			"suppressed": a.Suppressed,
+			"byType":     byType,
+			"bySeverity": map[string]int{
+				"CRITICAL": criticalCount,
+				"HIGH":     highCount,
+				"MEDIUM":   mediumCount,
+				"LOW":      lowCount,
+			},
+		},
+		"jwts": a.jwtInfos(),
+		"endpoints": map[string]int{
+			"total":     len(a.Endpoints),
+			"important": len(a.ImportantEndpoints),
+		},
+		"accountEndpoints": a.accountEndpointCounts(),
+		"urls": map[string]int{
+			"total": len(a.URLs),
+		},
+		"websockets": map[string]int{
+			"total": len(a.WebSockets),
+		},
+		"interesting": map[string]int{
+			"total": len(a.Interesting),
+		},
+		"buckets": map[string]int{
+			"total": len(a.Buckets),
+		},
+		"infraReferences": map[string]int{
+			"total": len(a.InfraReferences),
+		},
+		"hostFamilies": map[string]int{
+			"total":   len(a.HostFamilies),
+			"derived": len(a.FormatDerivedHosts()),
+		},
+		"ips": map[string]int{
+			"total":    len(a.IPs),
+			"internal": a.InternalIPs(),
+		},
+		"graphql": map[string]int{
+			"total": len(a.GraphQL),
+		},
+		"persistedQueries": map[string]int{
+			"total": len(a.PersistedQueries),
+		},
+		"domSinks": map[string]int{
+			"total": len(a.DOMSinks),
+		},
+		"httpClients": map[string]int{
+			"total": len(a.HTTPClients),
+		},
+		"chunkNames": map[string]int{
+			"total": len(a.ChunkNames),
+		},
+		"stats": map[string]interface{}{
+			"overBudget": a.OverBudget,
+		},
+		"warnings": a.Warnings,
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write JSON file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package jsdumper
+
+import (
+	"sort"
+	"strings"
+)
+
+// Risk score weights per finding
+var severityWeights = map[string]int{
+	"CRITICAL": 40,
+	"HIGH":     20,
+	"MEDIUM":   5,
+	"LOW":      1,
+}
+
+const (
+	adminEndpointWeight     = 3 // /admin, /internal, ... endpoints
+	interestingStringWeight = 2 // keyword hits such as "internal use only"
+	accountEndpointWeight   = 5 // password reset, magic link, ... endpoints
+	internalIPWeight        = 3 // private, loopback, link-local and CGNAT addresses
+	infraReferenceWeight    = 4 // .git/, .env, CI servers, registries
+)
+
+// SeverityWeight is the risk score weight of a secret of severity, 0 for an
+// unknown severity
+func SeverityWeight(severity string) int {
+	return severityWeights[severity]
+}
+
+// TargetRisk ranks a scanned target (file or URL) for triage
+type TargetRisk struct {
+	Target string `json:"target"`
+	Score  int    `json:"score"`
+}
+
+// Compute a composite risk score for one target's results
+func riskScore(result *Results) int {
+	score := 0
+	for _, secret := range result.Secrets {
+		score += severityWeights[secret.Severity]
+	}
+	for _, endpoint := range result.Endpoints {
+		if isAdminEndpoint(endpoint) {
+			score += adminEndpointWeight
+		}
+		if AccountFlow(endpoint) != "" {
+			score += accountEndpointWeight
+		}
+	}
+	for _, ip := range result.IPs {
+		if ip.Scope != "public" {
+			score += internalIPWeight
+		}
+	}
+	score += len(result.InfraReferences) * infraReferenceWeight
+	score += len(result.Interesting) * interestingStringWeight
+	return score
+}
+
+func isAdminEndpoint(endpoint string) bool {
+	lower := strings.ToLower(endpoint)
+	for _, indicator := range []string{"/admin", "/internal", "/debug", "/manage", "/superuser"} {
+		if strings.Contains(lower, indicator) {
+			return true
+		}
+	}
+	return false
+}
+
+// Order scored targets, highest risk first
+func rankTargets(ranked []TargetRisk) []TargetRisk {
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Target < ranked[j].Target
+	})
+	return ranked
+}
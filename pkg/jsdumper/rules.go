@@ -0,0 +1,135 @@
+package jsdumper
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule is a user-defined secret pattern loaded from a -rules YAML file:
+//
+//	rules:
+//	  - name: ACME_TOKEN
+//	    regex: 'acme[_-]?token\s*[:=]\s*["'']([a-z0-9]{32})["'']'
+//	    group: 1         # capture group holding the value (default: 1 if present, else 0)
+//	    severity: HIGH   # CRITICAL, HIGH, MEDIUM or LOW (default: MEDIUM)
+//	    entropy: 3.5     # optional minimum Shannon entropy
+//	    examples:        # checked by "jsdumper patterns verify"
+//	      positive: ['acme_token = "9f8e7d6c5b4a39281706f5e4d3c2b1a0"']
+//	      negative: ['acme_token = "<token>"']
+type Rule struct {
+	Name     string       `yaml:"name"`
+	Regex    string       `yaml:"regex"`
+	Group    *int         `yaml:"group"`
+	Severity string       `yaml:"severity"`
+	Entropy  float64      `yaml:"entropy"`
+	Examples RuleExamples `yaml:"examples"`
+
+	pattern *regexp.Regexp
+}
+
+// RuleExamples are snippets a rule must and must not match
+type RuleExamples struct {
+	Positive []string `yaml:"positive"`
+	Negative []string `yaml:"negative"`
+}
+
+type rulesFile struct {
+	Rules   []Rule            `yaml:"rules"`
+	Filters map[string]Filter `yaml:"filters"` // -filter presets, see Filter
+}
+
+var validSeverities = map[string]bool{"CRITICAL": true, "HIGH": true, "MEDIUM": true, "LOW": true}
+
+func loadRulesFile(path string) (*rulesFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var file rulesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file %s: %w", path, err)
+	}
+	return &file, nil
+}
+
+// LoadRules reads and compiles the rules of a YAML rules file
+func LoadRules(path string) ([]Rule, error) {
+	file, err := loadRulesFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+		if rule.Name == "" || rule.Regex == "" {
+			return nil, fmt.Errorf("rule %d in %s needs a name and a regex", i+1, path)
+		}
+		rule.pattern, err = regexp.Compile(rule.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: invalid regex: %w", rule.Name, err)
+		}
+
+		rule.Severity = strings.ToUpper(rule.Severity)
+		if rule.Severity == "" {
+			rule.Severity = "MEDIUM"
+		}
+		if !validSeverities[rule.Severity] {
+			return nil, fmt.Errorf("rule %s: invalid severity %q", rule.Name, rule.Severity)
+		}
+
+		if rule.Group == nil {
+			group := 0
+			if rule.pattern.NumSubexp() > 0 {
+				group = 1
+			}
+			rule.Group = &group
+		}
+		if *rule.Group < 0 || *rule.Group > rule.pattern.NumSubexp() {
+			return nil, fmt.Errorf("rule %s: regex has no capture group %d", rule.Name, *rule.Group)
+		}
+	}
+
+	return file.Rules, nil
+}
+
+// LoadFilters reads the filter presets of a YAML rules file
+func LoadFilters(path string) (map[string]Filter, error) {
+	file, err := loadRulesFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for name, filter := range file.Filters {
+		filter.Severity = strings.ToUpper(filter.Severity)
+		if err := filter.validate(name); err != nil {
+			return nil, err
+		}
+		file.Filters[name] = filter
+	}
+	return file.Filters, nil
+}
+
+// Run a user-defined rule over content
+func (r *Rule) extract(run *extraction, content, fileName string) []Secret {
+	var secrets []Secret
+	for _, match := range run.findAllSubmatch(r.Name, r.pattern, content) {
+		value := match[*r.Group]
+		if value == "" {
+			continue
+		}
+		if r.Entropy > 0 && !hasHighEntropy(value, r.Entropy) {
+			continue
+		}
+		secrets = append(secrets, Secret{
+			Type:     r.Name,
+			File:     fileName,
+			Value:    value,
+			Severity: r.Severity,
+		})
+	}
+	return secrets
+}
@@ -0,0 +1,264 @@
+package jsdumper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Minimal SARIF 2.1.0 object model (https://docs.oasis-open.org/sarif/sarif/v2.1.0/)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool      `json:"tool"`
+	Results    []sarifResult  `json:"results"`
+	Properties map[string]any `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+	Properties     BuildInfo   `json:"properties"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations"`
+	Properties map[string]any  `json:"properties,omitempty"` // Tags of the inputs it came from
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// Map jsdumper severities to SARIF levels
+func sarifLevel(severity string) string {
+	switch severity {
+	case "CRITICAL", "HIGH":
+		return "error"
+	case "MEDIUM":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifLocator builds the locations of results. GitHub code scanning needs
+// a file and line for every result: file names are resolved to the paths
+// they were read from, and the line of a finding that has none is the first
+// one holding its value.
+type sarifLocator struct {
+	paths    map[string]string // File name -> repo-relative path
+	contents map[string]lineIndexed
+}
+
+type lineIndexed struct {
+	content string
+	index   lineIndex
+}
+
+func (l *sarifLocator) location(file string, line int, value string) sarifLocation {
+	uri := file
+	if path, ok := l.paths[file]; ok {
+		uri = path
+		if line <= 0 {
+			line = l.find(path, value)
+		}
+	}
+	return sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: uri},
+			Region:           &sarifRegion{StartLine: max(line, 1)},
+		},
+	}
+}
+
+// find returns the line of the first occurrence of value in the file at
+// path, 0 when it isn't there (decoded or transformed content)
+func (l *sarifLocator) find(path, value string) int {
+	file, ok := l.contents[path]
+	if !ok {
+		if data, err := os.ReadFile(filepath.FromSlash(path)); err == nil {
+			file = lineIndexed{content: string(data), index: newLineIndex(string(data))}
+		}
+		l.contents[path] = file
+	}
+	if pos := strings.Index(file.content, value); pos != -1 && value != "" {
+		return file.index.line(pos)
+	}
+	return 0
+}
+
+// redactSecret keeps the first characters of a secret, enough to tell it
+// apart in the code-scanning UI without publishing it
+func redactSecret(value string) string {
+	runes := []rune(value)
+	shown := 0
+	if len(runes) >= 12 {
+		shown = 4
+	}
+	return string(runes[:shown]) + fmt.Sprintf("… (%d characters)", len(runes))
+}
+
+// WriteSARIF writes findings as a SARIF log. paths maps the file names of
+// results to their paths relative to the repository root; other files are
+// located by name. Secret values are redacted in messages.
+func (a *AggregatedResults) WriteSARIF(filePath string, paths map[string]string) error {
+	rules := make(map[string]sarifRule)
+	addRule := func(id, description, level string) {
+		if _, ok := rules[id]; !ok {
+			rules[id] = sarifRule{
+				ID:                   id,
+				ShortDescription:     sarifMessage{Text: description},
+				DefaultConfiguration: sarifConfiguration{Level: level},
+			}
+		}
+	}
+	locator := &sarifLocator{paths: paths, contents: make(map[string]lineIndexed)}
+	// Endpoints and URLs are aggregated across files: they are located in
+	// every file they were found in
+	locations := func(files []string, value string) []sarifLocation {
+		var list []sarifLocation
+		for _, file := range files {
+			list = append(list, locator.location(file, 0, value))
+		}
+		return list
+	}
+
+	sarifResults := []sarifResult{}
+	for _, secret := range a.Secrets {
+		level := sarifLevel(secret.Severity)
+		addRule(secret.Type, fmt.Sprintf("%s secret", secret.Type), level)
+		message := fmt.Sprintf("%s (%s): %s", secret.Type, secret.Severity, redactSecret(secret.Value))
+		if secret.Encoding != "" {
+			message += fmt.Sprintf(" (%s-encoded)", secret.Encoding)
+		}
+		sarifResults = append(sarifResults, sarifResult{
+			RuleID:     secret.Type,
+			Level:      level,
+			Message:    sarifMessage{Text: message},
+			Locations:  []sarifLocation{locator.location(secret.File, secret.Line, secret.Value)},
+			Properties: a.tagProperties(secret.File, secret.Value),
+		})
+	}
+
+	for _, endpoint := range a.Endpoints {
+		addRule("ENDPOINT", "API endpoint referenced in JavaScript", "note")
+		sarifResults = append(sarifResults, sarifResult{
+			RuleID:     "ENDPOINT",
+			Level:      "note",
+			Message:    sarifMessage{Text: endpoint},
+			Locations:  locations(a.endpointFiles[endpoint], endpoint),
+			Properties: a.tagProperties("", endpoint),
+		})
+	}
+	for _, url := range a.URLs {
+		addRule("URL", "Absolute URL referenced in JavaScript", "note")
+		sarifResults = append(sarifResults, sarifResult{
+			RuleID:     "URL",
+			Level:      "note",
+			Message:    sarifMessage{Text: url},
+			Locations:  locations(a.urlFiles[url], url),
+			Properties: a.tagProperties("", url),
+		})
+	}
+	for _, ref := range a.InfraReferences {
+		addRule("INFRA_REFERENCE", "Source-control, CI or artifact reference", "note")
+		sarifResults = append(sarifResults, sarifResult{
+			RuleID:     "INFRA_REFERENCE",
+			Level:      "note",
+			Message:    sarifMessage{Text: fmt.Sprintf("%s: %s", ref.Kind, ref.Value)},
+			Locations:  []sarifLocation{locator.location(ref.File, 0, ref.Value)},
+			Properties: a.tagProperties(ref.File, ref.Value),
+		})
+	}
+
+	var ruleList []sarifRule
+	for _, rule := range rules {
+		ruleList = append(ruleList, rule)
+	}
+	sort.Slice(ruleList, func(i, j int) bool { return ruleList[i].ID < ruleList[j].ID })
+
+	// "tags" is the standard SARIF property for labels
+	properties := map[string]any{"coverage": a.Coverage}
+	if len(a.Tags) > 0 {
+		properties["tags"] = a.Tags
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "jsdumper",
+				Version:        version,
+				InformationURI: "https://github.com/d0xng/jsdumper",
+				Rules:          ruleList,
+				Properties:     CurrentBuildInfo(),
+			}},
+			Results:    sarifResults,
+			Properties: properties,
+		}},
+	}
+
+	data, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal SARIF: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write SARIF file: %w", err)
+	}
+	return nil
+}
+
+// tagProperties are the properties of a SARIF result from tagged inputs
+func (a *AggregatedResults) tagProperties(file, value string) map[string]any {
+	tags := a.TagsOf(file, value)
+	if len(tags) == 0 {
+		return nil
+	}
+	return map[string]any{"tags": tags}
+}
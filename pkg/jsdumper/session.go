@@ -0,0 +1,164 @@
+package jsdumper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/cookiejar"
+	urlpkg "net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sessionJar is the cookie jar shared by all downloads of a run, so cookies a
+// target sets on the first request (anti-bot challenges, sessions) are sent
+// with the following ones. Cookies are scoped per host like in a browser.
+type sessionJar struct {
+	http.CookieJar
+
+	mu      sync.Mutex
+	cookies map[string]sessionCookie // Cookies as set, by URL host, domain, path and name, to save their attributes
+}
+
+// sessionCookie is a cookie as persisted in a -session file: the URL that set
+// it and its attributes, which the jar doesn't give back
+type sessionCookie struct {
+	URL      string `json:"url"`
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Domain   string `json:"domain,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Expires  string `json:"expires,omitempty"` // RFC 3339, none for session cookies
+	Secure   bool   `json:"secure,omitempty"`
+	HttpOnly bool   `json:"httpOnly,omitempty"`
+}
+
+func newSessionJar() *sessionJar {
+	jar, _ := cookiejar.New(nil) // Never fails without options
+	return &sessionJar{CookieJar: jar, cookies: make(map[string]sessionCookie)}
+}
+
+func (j *sessionJar) SetCookies(u *urlpkg.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	origin := (&urlpkg.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}).String()
+	for _, cookie := range cookies {
+		key := strings.Join([]string{u.Hostname(), cookie.Domain, cookie.Path, cookie.Name}, " ")
+		if cookie.MaxAge < 0 {
+			delete(j.cookies, key)
+			continue
+		}
+		saved := sessionCookie{
+			URL:      origin,
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+		}
+		// Max-Age wins over Expires, as in the jar
+		if cookie.MaxAge > 0 {
+			saved.Expires = time.Now().Add(time.Duration(cookie.MaxAge) * time.Second).UTC().Format(time.RFC3339)
+		} else if !cookie.Expires.IsZero() {
+			saved.Expires = cookie.Expires.UTC().Format(time.RFC3339)
+		}
+		j.cookies[key] = saved
+	}
+	j.mu.Unlock()
+	j.CookieJar.SetCookies(u, cookies)
+}
+
+// Load cookies saved by a previous run; a missing file starts an empty session
+func (j *sessionJar) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read session file: %w", err)
+	}
+
+	var saved []sessionCookie
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse session file %s: %w", path, err)
+	}
+	for _, cookie := range saved {
+		u, err := urlpkg.Parse(cookie.URL)
+		if err != nil {
+			continue
+		}
+		httpCookie := &http.Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   cookie.Domain,
+			Path:     cookie.Path,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+		}
+		if cookie.Expires != "" {
+			if httpCookie.Expires, err = time.Parse(time.RFC3339, cookie.Expires); err != nil || httpCookie.Expires.Before(time.Now()) {
+				continue
+			}
+		}
+		j.SetCookies(u, []*http.Cookie{httpCookie})
+	}
+	return nil
+}
+
+// Save the cookies that are still valid, with the URL that set them
+func (j *sessionJar) Save(path string) error {
+	j.mu.Lock()
+	keys := make([]string, 0, len(j.cookies))
+	for key := range j.cookies {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	cookies := make([]sessionCookie, 0, len(keys))
+	for _, key := range keys {
+		cookies = append(cookies, j.cookies[key])
+	}
+	j.mu.Unlock()
+
+	saved := []sessionCookie{}
+	for _, cookie := range cookies {
+		if j.valid(cookie) {
+			saved = append(saved, cookie)
+		}
+	}
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Session cookies are credentials
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+	return nil
+}
+
+// valid reports whether the jar still holds cookie: it may have expired, or
+// been replaced or rejected (a Domain the URL can't set)
+func (j *sessionJar) valid(cookie sessionCookie) bool {
+	u, err := urlpkg.Parse(cookie.URL)
+	if err != nil {
+		return false
+	}
+	if cookie.Path != "" {
+		u.Path = cookie.Path
+	}
+	if cookie.Secure {
+		u.Scheme = "https"
+	}
+	for _, current := range j.Cookies(u) {
+		if current.Name == cookie.Name && current.Value == cookie.Value {
+			return true
+		}
+	}
+	return false
+}
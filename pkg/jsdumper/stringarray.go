@@ -0,0 +1,773 @@
+package jsdumper
+
+import (
+	"context"
+	"encoding/base64"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// javascript-obfuscator (obfuscator.io) moves the string literals of a script
+// into one array and replaces each with a call to a decoder taking the
+// string's index plus an offset: fetch(_0x4b2c(0x1e3)). At startup the array
+// is rotated until a checksum of parsed strings matches, and its strings may
+// be base64 or RC4 encoded. DecodeStringArrays undoes all three, so the
+// detectors see the strings again.
+
+// Shortest array of an obfuscator older than 2.0 (var _0x1234=['...',...]),
+// which unlike later ones doesn't wrap it in a function
+const minStringArrayLength = 5
+
+// Largest array rotated to find the checksum's rotation
+const maxStringArrayRotations = 100000
+
+// stringArrayAlphabet is the base64 alphabet of the obfuscator's decoders,
+// lowercase first
+const stringArrayAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789+/="
+
+var (
+	// What precedes a string array: its declaration, in a function returning
+	// it (2.0 and later) or not
+	stringArrayStart = regexp.MustCompile(`(?:function\s+([\w$]+)\s*\(\s*\)\s*\{\s*|^|[^\w$])(?:var|const|let)\s+([\w$]+)\s*=\s*$`)
+	// What precedes a decoder's read of the array: the decoder's head, as it
+	// takes the index and, for RC4, the key
+	stringArrayDecoder = regexp.MustCompile(`(?:function\s+([\w$]+)|([\w$]+)\s*=\s*function)\s*\(\s*[\w$]+\s*,\s*[\w$]+\s*\)\s*\{[^{}]*$`)
+	// The index shift at the start of a decoder: index = index - offset
+	stringArrayShift = regexp.MustCompile(`([\w$]+)\s*=\s*([\w$]+)\s*-\s*([^;,]+)`)
+	// The checksum compared to the rotation's target, in an if that breaks
+	stringArrayChecksum = regexp.MustCompile(`=\s*([^;=]*parseInt\s*\([^;]*);\s*if\s*\(\s*[\w$]+\s*===\s*[\w$]+\s*\)\s*break`)
+)
+
+type stringArrayEncoding int
+
+const (
+	stringArrayPlain stringArrayEncoding = iota
+	stringArrayBase64
+	stringArrayRC4
+)
+
+// stringArray is an obfuscator's string array and its decoders
+type stringArray struct {
+	strings  []string
+	ref      string // What decoders read it through: the function's call, or the array's name
+	decoders []stringArrayDecoderFunc
+	decoded  map[string]string // Decoded strings by index and key
+}
+
+type stringArrayDecoderFunc struct {
+	names    []string // The decoder and its aliases
+	offset   int
+	encoding stringArrayEncoding
+}
+
+// DecodeStringArrays is a Transformer that replaces the decoder calls of
+// javascript-obfuscator string arrays with the strings they return, and
+// appends the decoded strings one per line, like DecompileHermes. Content
+// without a string array is returned as it is.
+func DecodeStringArrays(ctx context.Context, fileName, content string) (string, error) {
+	arrays := findStringArrays(content)
+	if len(arrays) == 0 {
+		return content, nil
+	}
+
+	var tail strings.Builder
+	for _, array := range arrays {
+		if err := ctx.Err(); err != nil {
+			return content, err
+		}
+		// Calls are only inlined once the array is in the order they expect
+		if array.rotate(ctx, content) {
+			content = array.inline(content)
+		}
+		// RC4 strings can't be decoded without the keys of their calls
+		encoding := array.decoders[0].encoding
+		if encoding == stringArrayRC4 {
+			continue
+		}
+		for _, s := range array.strings {
+			if encoding == stringArrayBase64 {
+				s = decodeStringArrayBase64(s)
+			}
+			if s != "" {
+				tail.WriteString(strconv.Quote(s))
+				tail.WriteString(";\n")
+			}
+		}
+	}
+	return content + "\n" + tail.String(), nil
+}
+
+// findStringArrays returns the string arrays of content that have a decoder
+func findStringArrays(content string) []*stringArray {
+	// Regular expressions only look behind the arrays of string literals
+	// assigned to a name, which are few
+	var candidates []*stringArray
+	for open := 0; ; open++ {
+		next := strings.IndexByte(content[open:], '[')
+		if next == -1 {
+			break
+		}
+		open += next
+		quote := open + 1
+		for quote < len(content) && isBlank(content[quote]) {
+			quote++
+		}
+		if quote == len(content) || content[quote] != '"' && content[quote] != '\'' {
+			continue
+		}
+		before := strings.TrimRight(content[max(0, open-100):open], " \t\r\n")
+		if !strings.HasSuffix(before, "=") || strings.HasSuffix(before, "==") {
+			continue
+		}
+		window := content[max(0, open-100):open]
+		loc := stringArrayStart.FindStringSubmatchIndex(window)
+		if loc == nil {
+			continue
+		}
+		values, end := parseStringArray(content, open)
+		if values == nil {
+			continue
+		}
+		array := &stringArray{strings: values, decoded: make(map[string]string)}
+		if loc[2] != -1 {
+			// The function must return the array: function f(){var a=[...];f=function(){return a;};return f();}
+			name, arrayName := window[loc[2]:loc[3]], window[loc[4]:loc[5]]
+			returns := regexp.MustCompile(`^\s*;\s*` + regexp.QuoteMeta(name) + `\s*=\s*function\s*\(\s*\)\s*\{\s*return\s+` + regexp.QuoteMeta(arrayName) + `\b`)
+			if !returns.MatchString(content[end:min(len(content), end+200)]) {
+				continue
+			}
+			array.ref = name + "()"
+		} else {
+			if len(values) < minStringArrayLength {
+				continue
+			}
+			array.ref = window[loc[4]:loc[5]] + "["
+		}
+		candidates = append(candidates, array)
+		open = end - 1
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// Decoders read the array first thing: var a=f(); or var s=a[i];
+	var arrays []*stringArray
+	for _, array := range candidates {
+		for from := 0; ; {
+			next := strings.Index(content[from:], array.ref)
+			if next == -1 {
+				break
+			}
+			read := from + next
+			from = read + len(array.ref)
+			if read > 0 && isIdentByte(content[read-1]) {
+				continue
+			}
+			start := max(0, read-300)
+			loc := stringArrayDecoder.FindStringSubmatchIndex(content[start:read])
+			if loc == nil {
+				continue
+			}
+			var name string
+			if loc[2] != -1 {
+				name = content[start+loc[2] : start+loc[3]]
+			} else {
+				name = content[start+loc[4] : start+loc[5]]
+			}
+			open := start + strings.LastIndexByte(content[start:read], '{')
+			if decoder, ok := newStringArrayDecoder(content, name, open); ok {
+				array.decoders = append(array.decoders, decoder)
+			}
+		}
+		if len(array.decoders) > 0 {
+			arrays = append(arrays, array)
+		}
+	}
+	return arrays
+}
+
+// parseStringArray returns the strings of the array literal opening at open,
+// and the index after it, or nil unless every element is a string literal
+func parseStringArray(content string, open int) ([]string, int) {
+	var values []string
+	i := open + 1
+	for i < len(content) {
+		for i < len(content) && isBlank(content[i]) {
+			i++
+		}
+		if i >= len(content) || content[i] != '"' && content[i] != '\'' {
+			return nil, 0
+		}
+		end := skipJSString(content, i)
+		if end <= i+1 || content[end-1] != content[i] {
+			return nil, 0
+		}
+		values = append(values, unquoteJS(content[i:end]))
+		for i = end; i < len(content) && isBlank(content[i]); i++ {
+		}
+		if i < len(content) && content[i] == ']' {
+			return values, i + 1
+		}
+		if i >= len(content) || content[i] != ',' {
+			return nil, 0
+		}
+		i++
+	}
+	return nil, 0
+}
+
+// newStringArrayDecoder reads the index shift and encoding of the decoder
+// whose body opens at open, and finds the names it is aliased to. Functions
+// that don't shift their index first aren't decoders.
+func newStringArrayDecoder(content, name string, open int) (stringArrayDecoderFunc, bool) {
+	body := content[open:skipExpression(content, open+1)]
+	decoder := stringArrayDecoderFunc{names: []string{name}}
+	shifted := false
+	for _, shift := range stringArrayShift.FindAllStringSubmatch(body, -1) {
+		if shift[1] != shift[2] {
+			continue
+		}
+		offset, ok := evalStringArrayExpr(shift[3], nil)
+		if ok && !offset.isString && offset.number == math.Trunc(offset.number) {
+			decoder.offset, shifted = int(offset.number), true
+		}
+		break
+	}
+	if !shifted {
+		return decoder, false
+	}
+	if strings.Contains(body, stringArrayAlphabet) {
+		decoder.encoding = stringArrayBase64
+		if strings.Contains(body, "0x100") || strings.Contains(body, "256") {
+			decoder.encoding = stringArrayRC4
+		}
+	}
+
+	// Call sites go through aliases: const _0x3f1a=_0x4b2c;
+	known := map[string]bool{name: true}
+	for i := 0; i < len(decoder.names); i++ {
+		for _, alias := range stringArrayAliases(content, decoder.names[i]) {
+			if !known[alias] {
+				known[alias] = true
+				decoder.names = append(decoder.names, alias)
+			}
+		}
+	}
+	return decoder, true
+}
+
+// stringArrayAliases returns the names name is assigned to as it is:
+// alias=name followed by , ; ) } or a line end
+func stringArrayAliases(content, name string) []string {
+	var aliases []string
+	for _, at := range identOccurrences(content, name) {
+		after := at + len(name)
+		for after < len(content) && (content[after] == ' ' || content[after] == '\t') {
+			after++
+		}
+		if after == len(content) || strings.IndexByte(",;)}\n", content[after]) == -1 {
+			continue
+		}
+		eq := at - 1
+		for eq >= 0 && isBlank(content[eq]) {
+			eq--
+		}
+		if eq < 1 || content[eq] != '=' || strings.IndexByte("=!<>+-*/%&|^", content[eq-1]) != -1 {
+			continue
+		}
+		end := eq
+		for end > 0 && isBlank(content[end-1]) {
+			end--
+		}
+		start := end
+		for start > 0 && isIdentByte(content[start-1]) {
+			start--
+		}
+		if start < end && (start == 0 || content[start-1] != '.') {
+			aliases = append(aliases, content[start:end])
+		}
+	}
+	return aliases
+}
+
+// identOccurrences returns the indexes of name in content as a whole
+// identifier rather than part of one or a property
+func identOccurrences(content, name string) []int {
+	var at []int
+	for from := 0; ; {
+		next := strings.Index(content[from:], name)
+		if next == -1 {
+			return at
+		}
+		i := from + next
+		from = i + len(name)
+		if i > 0 && (isIdentByte(content[i-1]) || content[i-1] == '.') {
+			continue
+		}
+		if from < len(content) && isIdentByte(content[from]) {
+			continue
+		}
+		at = append(at, i)
+	}
+}
+
+// rotate turns the array the way the obfuscated script does at startup:
+// until the checksum matches the target, or the target number of times for
+// obfuscators older than 2.0. It reports whether the rotation is known.
+func (a *stringArray) rotate(ctx context.Context, content string) bool {
+	name := strings.TrimSuffix(strings.TrimSuffix(a.ref, "()"), "[")
+	call := regexp.MustCompile(`\}\s*\)?\s*\(\s*` + regexp.QuoteMeta(name) + `\s*,\s*(0x[0-9a-fA-F]+|\d+)\s*\)`)
+	loc := call.FindStringSubmatchIndex(content)
+	if loc == nil {
+		return true // Not rotated
+	}
+	target, err := strconv.ParseInt(content[loc[2]:loc[3]], 0, 64)
+	if err != nil {
+		return false
+	}
+	rotation := content[max(0, loc[0]-4000):loc[0]]
+
+	if strings.Contains(rotation, "while(--") || strings.Contains(rotation, "while (--") {
+		a.rotateBy(int(target % int64(len(a.strings))))
+		return true
+	}
+	checksums := stringArrayChecksum.FindAllStringSubmatch(rotation, -1)
+	if len(checksums) == 0 || len(a.strings) > maxStringArrayRotations {
+		return false
+	}
+	checksum := checksums[len(checksums)-1][1]
+	for turn := 0; turn < len(a.strings); turn++ {
+		if turn%1000 == 0 && ctx.Err() != nil {
+			return false
+		}
+		value, ok := evalStringArrayExpr(checksum, a.call)
+		if ok && !value.isString && value.number == float64(target) {
+			return true
+		}
+		a.rotateBy(1)
+	}
+	return false
+}
+
+// rotateBy moves the first n strings to the end, like n push(shift())
+func (a *stringArray) rotateBy(n int) {
+	if n <= 0 || len(a.strings) == 0 {
+		return
+	}
+	n %= len(a.strings)
+	a.strings = append(a.strings[n:], a.strings[:n]...)
+	a.decoded = make(map[string]string)
+}
+
+// call decodes the string at index (before the shift), with the key for RC4,
+// the way the first decoder does
+func (a *stringArray) call(args []stringArrayValue) (string, bool) {
+	return a.decode(&a.decoders[0], args)
+}
+
+func (a *stringArray) decode(decoder *stringArrayDecoderFunc, args []stringArrayValue) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+	index := args[0].number
+	if args[0].isString {
+		// Obfuscators older than 2.0 pass hexadecimal strings: _0x5678('0x1f')
+		n, err := strconv.ParseInt(args[0].str, 0, 64)
+		if err != nil {
+			return "", false
+		}
+		index = float64(n)
+	}
+	i := int(index) - decoder.offset
+	if index != math.Trunc(index) || i < 0 || i >= len(a.strings) {
+		return "", false
+	}
+	key := ""
+	if len(args) > 1 {
+		key = args[1].str
+	}
+	cacheKey := strconv.Itoa(int(decoder.encoding)) + ":" + strconv.Itoa(i) + ":" + key
+	if s, ok := a.decoded[cacheKey]; ok {
+		return s, true
+	}
+	s := a.strings[i]
+	switch decoder.encoding {
+	case stringArrayBase64:
+		s = decodeStringArrayBase64(s)
+	case stringArrayRC4:
+		s = decodeStringArrayRC4(s, key)
+	}
+	a.decoded[cacheKey] = s
+	return s, true
+}
+
+// stringArrayCall matches the arguments of a decoder call: a literal index
+// and key
+var stringArrayCall = regexp.MustCompile(`^\(\s*(-?0x[0-9a-fA-F]+|-?\d+|'[^'\\\n]*'|"[^"\\\n]*")\s*(?:,\s*('(?:[^'\\\n]|\\.)*'|"(?:[^"\\\n]|\\.)*"))?\s*\)`)
+
+// inline replaces the decoder calls of content with their strings
+func (a *stringArray) inline(content string) string {
+	type inlined struct {
+		start, end int
+		value      string
+	}
+	var calls []inlined
+	for d := range a.decoders {
+		decoder := &a.decoders[d]
+		for _, name := range decoder.names {
+			for _, at := range identOccurrences(content, name) {
+				args := at + len(name)
+				loc := stringArrayCall.FindStringSubmatchIndex(content[args:min(len(content), args+300)])
+				if loc == nil {
+					continue
+				}
+				values := []stringArrayValue{literalStringArrayValue(content[args+loc[2] : args+loc[3]])}
+				if loc[4] != -1 {
+					values = append(values, literalStringArrayValue(content[args+loc[4]:args+loc[5]]))
+				}
+				if s, ok := a.decode(decoder, values); ok {
+					calls = append(calls, inlined{at, args + loc[1], s})
+				}
+			}
+		}
+	}
+	if len(calls) == 0 {
+		return content
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].start < calls[j].start })
+
+	var out strings.Builder
+	last := 0
+	for _, call := range calls {
+		if call.start < last {
+			continue
+		}
+		out.WriteString(content[last:call.start])
+		out.WriteString(strconv.Quote(call.value))
+		last = call.end
+	}
+	out.WriteString(content[last:])
+	return out.String()
+}
+
+func literalStringArrayValue(token string) stringArrayValue {
+	if token[0] == '\'' || token[0] == '"' {
+		return stringArrayValue{isString: true, str: unquoteJS(token)}
+	}
+	n, _ := strconv.ParseInt(strings.TrimPrefix(token, "-"), 0, 64)
+	if token[0] == '-' {
+		n = -n
+	}
+	return stringArrayValue{number: float64(n)}
+}
+
+// decodeStringArrayBase64 decodes a string of a base64 decoder: base64 with
+// the obfuscator's alphabet, characters out of it skipped, then UTF-8
+func decodeStringArrayBase64(s string) string {
+	// Swapping the case of letters gives the standard alphabet
+	var std strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			std.WriteByte(c - 'a' + 'A')
+		case c >= 'A' && c <= 'Z':
+			std.WriteByte(c - 'A' + 'a')
+		case c >= '0' && c <= '9' || c == '+' || c == '/':
+			std.WriteByte(c)
+		}
+	}
+	encoded := std.String()
+	if len(encoded)%4 == 1 {
+		encoded = encoded[:len(encoded)-1]
+	}
+	decoded, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return ""
+	}
+	return string(decoded)
+}
+
+// decodeStringArrayRC4 decodes a string of an RC4 decoder: base64, then RC4
+// with the key of the call over UTF-16 code units
+func decodeStringArrayRC4(s, key string) string {
+	if key == "" {
+		return ""
+	}
+	data := utf16.Encode([]rune(decodeStringArrayBase64(s)))
+	keyUnits := utf16.Encode([]rune(key))
+
+	var state [256]int
+	for i := range state {
+		state[i] = i
+	}
+	for i, j := 0, 0; i < 256; i++ {
+		j = (j + state[i] + int(keyUnits[i%len(keyUnits)])) % 256
+		state[i], state[j] = state[j], state[i]
+	}
+	out := make([]uint16, len(data))
+	for y, i, j := 0, 0, 0; y < len(data); y++ {
+		i = (i + 1) % 256
+		j = (j + state[i]) % 256
+		state[i], state[j] = state[j], state[i]
+		out[y] = data[y] ^ uint16(state[(state[i]+state[j])%256])
+	}
+	return string(utf16.Decode(out))
+}
+
+// unquoteJS returns the value of a JavaScript string literal, quotes
+// included; escapes it doesn't know are left as they are
+func unquoteJS(literal string) string {
+	body := literal[1 : len(literal)-1]
+	if !strings.ContainsRune(body, '\\') {
+		return body
+	}
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' || i+1 >= len(body) {
+			b.WriteByte(body[i])
+			continue
+		}
+		i++
+		switch c := body[i]; c {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		case '0':
+			b.WriteByte(0)
+		case 'x', 'u':
+			r, next, ok := escapeAt(body, i-1)
+			if !ok {
+				b.WriteByte('\\')
+				b.WriteByte(c)
+				continue
+			}
+			b.WriteRune(r)
+			i = next - 1
+		case '\n':
+			// Line continuation
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// stringArrayValue is a value of a checksum expression
+type stringArrayValue struct {
+	number   float64
+	str      string
+	isString bool
+}
+
+// evalStringArrayExpr evaluates a rotation checksum: numbers, + - * / %,
+// parentheses and parseInt of decoder calls, made through call
+func evalStringArrayExpr(expr string, call func([]stringArrayValue) (string, bool)) (stringArrayValue, bool) {
+	p := &checksumParser{src: expr, call: call, ok: true}
+	value := p.expr()
+	p.space()
+	return value, p.ok && p.pos == len(p.src)
+}
+
+type checksumParser struct {
+	src  string
+	pos  int
+	call func([]stringArrayValue) (string, bool)
+	ok   bool
+}
+
+func (p *checksumParser) space() {
+	for p.pos < len(p.src) && isBlank(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *checksumParser) peek() byte {
+	p.space()
+	if p.pos < len(p.src) {
+		return p.src[p.pos]
+	}
+	return 0
+}
+
+func (p *checksumParser) expr() stringArrayValue {
+	left := p.term()
+	for p.ok {
+		switch p.peek() {
+		case '+':
+			p.pos++
+			right := p.term()
+			if left.isString || right.isString {
+				left = stringArrayValue{isString: true, str: left.String() + right.String()}
+			} else {
+				left.number += right.number
+			}
+		case '-':
+			p.pos++
+			left.number = left.toNumber() - p.term().toNumber()
+			left.isString = false
+		default:
+			return left
+		}
+	}
+	return left
+}
+
+func (p *checksumParser) term() stringArrayValue {
+	left := p.unary()
+	for p.ok {
+		op := p.peek()
+		if op != '*' && op != '/' && op != '%' {
+			return left
+		}
+		p.pos++
+		x, y := left.toNumber(), p.unary().toNumber()
+		switch op {
+		case '*':
+			left = stringArrayValue{number: x * y}
+		case '/':
+			left = stringArrayValue{number: x / y}
+		default:
+			left = stringArrayValue{number: math.Mod(x, y)}
+		}
+	}
+	return left
+}
+
+func (p *checksumParser) unary() stringArrayValue {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		return stringArrayValue{number: -p.unary().toNumber()}
+	case '+':
+		p.pos++
+		return stringArrayValue{number: p.unary().toNumber()}
+	}
+	return p.primary()
+}
+
+func (p *checksumParser) primary() stringArrayValue {
+	c := p.peek()
+	switch {
+	case c == '(':
+		p.pos++
+		value := p.expr()
+		if p.peek() != ')' {
+			p.ok = false
+		}
+		p.pos++
+		return value
+	case c == '\'' || c == '"':
+		end := skipJSString(p.src, p.pos)
+		value := stringArrayValue{isString: true, str: unquoteJS(p.src[p.pos:end])}
+		p.pos = end
+		return value
+	case c >= '0' && c <= '9':
+		start := p.pos
+		for p.pos < len(p.src) && (isIdentByte(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		n, err := strconv.ParseFloat(p.src[start:p.pos], 64)
+		if err != nil {
+			i, err := strconv.ParseInt(p.src[start:p.pos], 0, 64)
+			if err != nil {
+				p.ok = false
+			}
+			n = float64(i)
+		}
+		return stringArrayValue{number: n}
+	case isIdentByte(c):
+		start := p.pos
+		for p.pos < len(p.src) && isIdentByte(p.src[p.pos]) {
+			p.pos++
+		}
+		name := p.src[start:p.pos]
+		if p.peek() != '(' {
+			p.ok = false
+			return stringArrayValue{}
+		}
+		p.pos++
+		var args []stringArrayValue
+		for p.ok && p.peek() != ')' {
+			args = append(args, p.expr())
+			if p.peek() == ',' {
+				p.pos++
+			}
+		}
+		p.pos++
+		if name == "parseInt" && len(args) > 0 {
+			return stringArrayValue{number: jsParseInt(args[0].String())}
+		}
+		if p.call == nil {
+			p.ok = false
+			return stringArrayValue{}
+		}
+		s, ok := p.call(args)
+		if !ok {
+			p.ok = false
+		}
+		return stringArrayValue{isString: true, str: s}
+	}
+	p.ok = false
+	return stringArrayValue{}
+}
+
+func (v stringArrayValue) String() string {
+	if v.isString {
+		return v.str
+	}
+	return strconv.FormatFloat(v.number, 'f', -1, 64)
+}
+
+func (v stringArrayValue) toNumber() float64 {
+	if !v.isString {
+		return v.number
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(v.str), 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return n
+}
+
+// jsParseInt parses the leading integer of s like JavaScript's parseInt
+func jsParseInt(s string) float64 {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	sign := 1.0
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+	}
+	base := 10.0
+	if len(s) > 1 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		base, s = 16, s[2:]
+	}
+	n, digits := 0.0, 0
+	for ; digits < len(s); digits++ {
+		d := strings.IndexByte("0123456789abcdef", s[digits]|0x20)
+		if d == -1 || float64(d) >= base {
+			break
+		}
+		n = n*base + float64(d)
+	}
+	if digits == 0 {
+		return math.NaN()
+	}
+	return sign * n
+}
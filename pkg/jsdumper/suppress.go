@@ -0,0 +1,96 @@
+package jsdumper
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Inline suppression annotations:
+//
+//	// jsdumper-ignore-next-line               all findings on the following line
+//	// jsdumper-ignore-next-line: JWT          only the listed types
+//	/* jsdumper-ignore: API_KEY */             listed types (or all) on the same line
+var suppressionPattern = regexp.MustCompile(`jsdumper-ignore(-next-line)?(?:\s*:\s*([A-Za-z0-9_, ]+))?`)
+
+// suppressions maps a 0-based line number to the suppressed secret types
+// (an empty list suppresses every type on that line)
+type suppressions struct {
+	lines map[int][]string
+	index lineIndex
+}
+
+func parseSuppressions(content string) *suppressions {
+	if !strings.Contains(content, "jsdumper-ignore") {
+		return nil
+	}
+
+	s := &suppressions{lines: make(map[int][]string)}
+	for i, line := range strings.Split(content, "\n") {
+		for _, match := range suppressionPattern.FindAllStringSubmatch(line, -1) {
+			target := i
+			if match[1] != "" {
+				target = i + 1
+			}
+			types := splitList(match[2])
+			if existing, ok := s.lines[target]; ok && (len(existing) == 0 || len(types) == 0) {
+				s.lines[target] = nil
+			} else {
+				s.lines[target] = append(existing, types...)
+			}
+		}
+	}
+
+	s.index = newLineIndex(content)
+	return s
+}
+
+func (s *suppressions) suppressed(line int, secretType string) bool {
+	types, ok := s.lines[line]
+	if !ok {
+		return false
+	}
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if strings.EqualFold(t, secretType) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter drops secrets whose every occurrence in content sits on a
+// suppressed line, returning the kept and the dropped secrets
+func (s *suppressions) filter(content string, secrets []Secret) ([]Secret, []Secret) {
+	if s == nil {
+		return secrets, nil
+	}
+
+	var kept, dropped []Secret
+	for _, secret := range secrets {
+		allSuppressed := true
+		found := false
+		for offset := 0; ; {
+			idx := strings.Index(content[offset:], secret.Value)
+			if idx == -1 {
+				break
+			}
+			found = true
+			pos := offset + idx
+			line := s.index.line(pos) - 1
+			if !s.suppressed(line, secret.Type) {
+				allSuppressed = false
+				break
+			}
+			offset = pos + len(secret.Value)
+		}
+
+		if found && allSuppressed {
+			dropped = append(dropped, secret)
+		} else {
+			kept = append(kept, secret)
+		}
+	}
+	return kept, dropped
+}
@@ -0,0 +1,92 @@
+package jsdumper
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Account flows: password reset, recovery, email verification, magic links
+// and second factors. A flaw in any of them (a guessable token, a reset link
+// sent to an attacker-supplied address, a skippable step) is an account
+// takeover, so their endpoints are the first a tester looks at.
+const (
+	FlowPasswordReset = "password-reset"
+	FlowRecovery      = "recovery"
+	FlowVerification  = "verification"
+	FlowMagicLink     = "magic-link"
+	FlowMFA           = "mfa"
+)
+
+// accountFlows classify endpoints, lowercased, first match wins: a
+// password reset confirmation is a password reset, not a confirmation
+var accountFlows = []struct {
+	flow    string
+	pattern *regexp.Regexp
+}{
+	{FlowPasswordReset, regexp.MustCompile(`(?:forgot|reset|lost|change|update|new|set)[-_]?(?:password|passwd|pwd)|(?:password|passwd|pwd)[-_/]?(?:reset|forgot|recover|change|update)`)},
+	{FlowMagicLink, regexp.MustCompile(`magic[-_]?link|passwordless|(?:login|signin|sign-in|email)[-_]?(?:link|code|token)|one[-_]?time[-_]?(?:login|link)`)},
+	{FlowMFA, regexp.MustCompile(`(?:^|[/_.-])(?:2fa|mfa|totp|otp|2sv)(?:$|[/_.-])|two[-_]?(?:factor|step)|(?:backup|recovery)[-_]?codes?|authenticator|webauthn|passkeys?`)},
+	{FlowRecovery, regexp.MustCompile(`(?:^|[/_.-])(?:recover|recovery|unlock|forgot)(?:$|[/_.-]|[-_]?(?:account|username|email))|account[-_]?recovery`)},
+	{FlowVerification, regexp.MustCompile(`verif(?:y|ication)|confirm(?:ation)?(?:$|[/?]|[-_]?(?:email|account|phone|registration))|(?:^|[/_.-])activat(?:e|ion)|(?:change|update)[-_]?(?:email|phone)|(?:email|phone)[-_]?(?:change|update)`)},
+}
+
+// AccountEndpoint is an endpoint of an account flow
+type AccountEndpoint struct {
+	Endpoint string
+	Flow     string
+}
+
+// AccountFlow returns the account flow an endpoint belongs to, "" if none
+func AccountFlow(endpoint string) string {
+	lower := strings.ToLower(endpoint)
+	for _, flow := range accountFlows {
+		if flow.pattern.MatchString(lower) {
+			return flow.flow
+		}
+	}
+	return ""
+}
+
+// AccountEndpoints lists the endpoints of account flows by flow. It is
+// derived from Endpoints, so a baseline hides known ones too.
+func (a *AggregatedResults) AccountEndpoints() []AccountEndpoint {
+	var endpoints []AccountEndpoint
+	for _, endpoint := range a.Endpoints {
+		if flow := AccountFlow(endpoint); flow != "" {
+			endpoints = append(endpoints, AccountEndpoint{Endpoint: endpoint, Flow: flow})
+		}
+	}
+	slices.SortStableFunc(endpoints, func(x, y AccountEndpoint) int {
+		return strings.Compare(x.Flow, y.Flow)
+	})
+	return endpoints
+}
+
+// accountEndpointCounts counts account flow endpoints for summary.json
+func (a *AggregatedResults) accountEndpointCounts() map[string]interface{} {
+	endpoints := a.AccountEndpoints()
+	byFlow := make(map[string]int)
+	for _, endpoint := range endpoints {
+		byFlow[endpoint.Flow]++
+	}
+	return map[string]interface{}{
+		"total":  len(endpoints),
+		"byFlow": byFlow,
+	}
+}
+
+// FormatAccountEndpoints lists account flow endpoints as "flow | endpoint",
+// followed by their HTTP methods when known
+func (a *AggregatedResults) FormatAccountEndpoints() []string {
+	var lines []string
+	for _, endpoint := range a.AccountEndpoints() {
+		line := fmt.Sprintf("%s | %s", endpoint.Flow, endpoint.Endpoint)
+		if methods := a.EndpointMethods[endpoint.Endpoint]; len(methods) > 0 {
+			line += " | " + strings.Join(methods, ",")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,390 @@
+package jsdumper
+
+import (
+	"math"
+	"net"
+	urlpkg "net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Calculate Shannon entropy of a string
+func calculateEntropy(str string) float64 {
+	if len(str) == 0 {
+		return 0
+	}
+
+	freq := make(map[rune]int)
+	for _, char := range str {
+		freq[char]++
+	}
+
+	entropy := 0.0
+	length := float64(len(str))
+
+	for _, count := range freq {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// Check if a string has high entropy (likely to be a secret)
+func hasHighEntropy(str string, threshold float64) bool {
+	return calculateEntropy(str) >= threshold
+}
+
+// lineIndex maps byte offsets of a text to line numbers
+type lineIndex []int
+
+func newLineIndex(content string) lineIndex {
+	starts := lineIndex{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// 1-based line number of the byte at offset pos
+func (l lineIndex) line(pos int) int {
+	return sort.Search(len(l), func(i int) bool { return l[i] > pos })
+}
+
+// Get the path of an endpoint target that may be an absolute URL or a path
+func endpointPath(target string) string {
+	if strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "//") {
+		return target
+	}
+	if i := strings.Index(target, "://"); i != -1 {
+		rest := target[i+3:]
+		if slash := strings.Index(rest, "/"); slash != -1 {
+			return rest[slash:]
+		}
+	}
+	return ""
+}
+
+// Normalize endpoint path
+func normalizeEndpoint(endpoint string) string {
+	if endpoint == "" {
+		return ""
+	}
+
+	// Remove query strings and fragments
+	endpoint = strings.Split(endpoint, "?")[0]
+	endpoint = strings.Split(endpoint, "#")[0]
+
+	// Ensure starts with /
+	if !strings.HasPrefix(endpoint, "/") {
+		endpoint = "/" + endpoint
+	}
+
+	// Remove trailing slash unless it's the root
+	if len(endpoint) > 1 && strings.HasSuffix(endpoint, "/") {
+		endpoint = endpoint[:len(endpoint)-1]
+	}
+
+	return endpoint
+}
+
+var apiVersionPattern = regexp.MustCompile(`^/v[0-9]+`)
+
+var hostnamePattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// Validate an http(s) or ws(s) URL candidate and canonicalize its scheme case (see
+// normalizeURL for the host). Trailing punctuation from the surrounding code
+// is trimmed first.
+func canonicalURL(candidate string) (string, bool) {
+	// Drop trailing punctuation, keeping a closing paren only when balanced
+	for len(candidate) > 0 {
+		last := candidate[len(candidate)-1]
+		if strings.IndexByte(".,;:!?]", last) != -1 ||
+			(last == ')' && strings.Count(candidate, ")") > strings.Count(candidate, "(")) {
+			candidate = candidate[:len(candidate)-1]
+			continue
+		}
+		break
+	}
+
+	parsed, err := urlpkg.Parse(candidate)
+	if err != nil {
+		return "", false
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	if scheme != "http" && scheme != "https" && scheme != "ws" && scheme != "wss" {
+		return "", false
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if host == "" {
+		return "", false
+	}
+	if net.ParseIP(host) == nil {
+		// Real hostnames have a dot (or are localhost); anything else is
+		// usually a concatenation fragment like "http://" + host
+		if !hostnamePattern.MatchString(host) || (!strings.Contains(host, ".") && host != "localhost") {
+			return "", false
+		}
+	}
+
+	// Rebuild with the canonical scheme, keeping the rest verbatim
+	rest := candidate[len(parsed.Scheme)+3:]
+	if slash := strings.IndexAny(rest, "/?#"); slash != -1 {
+		rest = rest[slash:]
+	} else {
+		rest = ""
+	}
+	hostPort := parsed.Host
+	if parsed.User != nil {
+		hostPort = parsed.User.String() + "@" + hostPort
+	}
+	return scheme + "://" + hostPort + rest, true
+}
+
+// Query parameters that only track the click, never select content
+var trackingParams = map[string]bool{
+	"gclid": true, "gclsrc": true, "dclid": true, "gbraid": true, "wbraid": true,
+	"fbclid": true, "msclkid": true, "yclid": true, "twclid": true, "ttclid": true,
+	"mc_cid": true, "mc_eid": true, "_ga": true, "_gl": true, "igshid": true,
+}
+
+// Normalize a canonical URL for deduplication
+func normalizeURL(url string, n URLNormalization) string {
+	if url == "" {
+		return ""
+	}
+
+	// Split into scheme://authority, path, query and fragment, keeping the
+	// original encoding of each part
+	if sep := strings.Index(url, "://"); sep != -1 {
+		scheme := url[:sep]
+		rest := url[sep+3:]
+		authority, path := rest, ""
+		if i := strings.IndexAny(rest, "/?#"); i != -1 {
+			authority, path = rest[:i], rest[i:]
+		}
+		fragment := ""
+		if i := strings.IndexByte(path, '#'); i != -1 {
+			path, fragment = path[:i], path[i:]
+		}
+		query := ""
+		if i := strings.IndexByte(path, '?'); i != -1 {
+			path, query = path[:i], path[i+1:]
+		}
+
+		userinfo := ""
+		if i := strings.LastIndexByte(authority, '@'); i != -1 {
+			userinfo, authority = authority[:i+1], authority[i+1:]
+		}
+		if n.LowercaseHost {
+			authority = strings.ToLower(authority)
+		}
+		if n.StripDefaultPort {
+			if scheme == "http" || scheme == "ws" {
+				authority = strings.TrimSuffix(authority, ":80")
+			} else if scheme == "https" || scheme == "wss" {
+				authority = strings.TrimSuffix(authority, ":443")
+			}
+		}
+
+		if query != "" && (n.StripTracking || n.SortQuery) {
+			var params []string
+			for _, param := range strings.Split(query, "&") {
+				name, _, _ := strings.Cut(param, "=")
+				name = strings.ToLower(name)
+				if n.StripTracking && (strings.HasPrefix(name, "utm_") || trackingParams[name]) {
+					continue
+				}
+				params = append(params, param)
+			}
+			if n.SortQuery {
+				sort.SliceStable(params, func(i, j int) bool {
+					a, _, _ := strings.Cut(params[i], "=")
+					b, _, _ := strings.Cut(params[j], "=")
+					return a < b
+				})
+			}
+			query = strings.Join(params, "&")
+		}
+		if query != "" {
+			path += "?" + query
+		}
+
+		url = scheme + "://" + userinfo + authority + path + fragment
+	}
+
+	// Remove trailing slashes
+	url = strings.TrimSuffix(url, "/")
+
+	return url
+}
+
+// Check if URL should be excluded (media files, common CDNs, etc.)
+func isExcludedURL(url string) bool {
+	if url == "" {
+		return true
+	}
+
+	lowerURL := strings.ToLower(url)
+
+	// Exclude common media file extensions
+	mediaExtensions := []string{
+		".jpg", ".jpeg", ".png", ".gif", ".svg", ".webp", ".ico",
+		".woff", ".woff2", ".ttf", ".eot", ".otf",
+		".mp4", ".mp3", ".avi", ".mov", ".wmv",
+		".pdf", ".zip", ".tar", ".gz",
+	}
+
+	for _, ext := range mediaExtensions {
+		if strings.HasSuffix(lowerURL, ext) || strings.Contains(lowerURL, ext+"?") {
+			return true
+		}
+	}
+
+	// Exclude common CDN domains (unless they have API-like paths)
+	cdnDomains := []string{
+		"cdnjs.cloudflare.com",
+		"cdn.jsdelivr.net",
+		"unpkg.com",
+		"gstatic.com",
+		"fonts.googleapis.com",
+		"fonts.gstatic.com",
+	}
+
+	for _, domain := range cdnDomains {
+		if strings.Contains(lowerURL, domain) {
+			// But include if it has API-like paths
+			apiIndicators := []string{"/api/", "/v1/", "/v2/", "/v3/", "/v4/", "/graphql", "/rest/"}
+			hasApiPath := false
+			for _, indicator := range apiIndicators {
+				if strings.Contains(lowerURL, indicator) {
+					hasApiPath = true
+					break
+				}
+			}
+			if !hasApiPath {
+				return true
+			}
+		}
+	}
+
+	// Exclude data URLs and javascript: URLs
+	if strings.HasPrefix(lowerURL, "data:") || strings.HasPrefix(lowerURL, "javascript:") {
+		return true
+	}
+
+	// Exclude W3C namespace URLs
+	if strings.Contains(lowerURL, "w3.org") {
+		return true
+	}
+
+	return false
+}
+
+// Check if path is an asset path (to exclude)
+func isAssetPath(path string) bool {
+	if path == "" {
+		return false
+	}
+
+	lowerPath := strings.ToLower(path)
+
+	// Exclude W3C namespaces and documentation
+	if strings.Contains(lowerPath, "w3.org") || strings.Contains(lowerPath, "www.w3.org") {
+		return true
+	}
+
+	// Exclude library documentation
+	if strings.Contains(lowerPath, "/docs/") || strings.Contains(lowerPath, "/documentation/") {
+		return false // Keep docs paths, user wants them
+	}
+
+	return false
+}
+
+// Check if endpoint is important (high-value API endpoint)
+func isImportantEndpoint(endpoint string) bool {
+	if endpoint == "" {
+		return false
+	}
+
+	lowerEndpoint := strings.ToLower(endpoint)
+
+	// API indicators - expanded to include v4, v5, etc.
+	apiIndicators := []string{
+		"/api/",
+		"/v1/",
+		"/v2/",
+		"/v3/",
+		"/v4/",
+		"/v5/",
+		"/v6/",
+		"/v7/",
+		"/v8/",
+		"/v9/",
+		"/auth/",
+		"/login",
+		"/logout",
+		"/signin",
+		"/signup",
+		"/sign-in",
+		"/sign-up",
+		"/register",
+		"/admin/",
+		"/internal/",
+		"/graphql",
+		"/rest/",
+		"/tmfbsn",
+		"/urm",
+		"/service",
+		"/guest/",
+		"/tokens",
+		"/createaccount",
+		"/create-account",
+		"/hub/",
+		"/hubs/",
+		"/signalr",
+		"/sockjs",
+		"/stomp",
+	}
+
+	for _, indicator := range apiIndicators {
+		if strings.Contains(lowerEndpoint, indicator) {
+			return true
+		}
+	}
+
+	// Also check if it starts with /v followed by a number
+	if apiVersionPattern.MatchString(lowerEndpoint) {
+		return true
+	}
+
+	return AccountFlow(endpoint) != ""
+}
+
+// splitList splits a comma-separated list in the scanned code, such as the
+// types of a suppression comment or the origins of a CORS header, dropping
+// empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// toSet indexes values for lookups while flattening findings
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
@@ -0,0 +1,57 @@
+package jsdumper
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var versionSegmentPattern = regexp.MustCompile(`/v([0-9]+)(/|$)`)
+
+// Generate likely sibling paths for endpoints (with/without an /api prefix,
+// neighbouring API versions) that aren't already known
+func EndpointVariants(endpoints []string) []string {
+	known := make(map[string]bool)
+	for _, endpoint := range endpoints {
+		known[endpoint] = true
+	}
+
+	variantSet := make(map[string]bool)
+	add := func(variant string) {
+		variant = normalizeEndpoint(variant)
+		if variant != "" && variant != "/" && !known[variant] {
+			variantSet[variant] = true
+		}
+	}
+
+	for _, endpoint := range endpoints {
+		// Toggle the /api prefix
+		if endpoint == "/api" || strings.HasPrefix(endpoint, "/api/") {
+			add(strings.TrimPrefix(endpoint, "/api"))
+		} else {
+			add("/api" + endpoint)
+		}
+
+		// Bump the version segment up (and down, when above v1)
+		if loc := versionSegmentPattern.FindStringSubmatchIndex(endpoint); loc != nil {
+			n, err := strconv.Atoi(endpoint[loc[2]:loc[3]])
+			if err != nil {
+				continue
+			}
+			prefix, suffix := endpoint[:loc[2]], endpoint[loc[3]:]
+			add(prefix + fmt.Sprint(n+1) + suffix)
+			if n > 1 {
+				add(prefix + fmt.Sprint(n-1) + suffix)
+			}
+		}
+	}
+
+	var variants []string
+	for variant := range variantSet {
+		variants = append(variants, variant)
+	}
+	sort.Strings(variants)
+	return variants
+}
@@ -0,0 +1,347 @@
+package jsdumper
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// Office documents keep their VBA macros in an OLE compound file: the
+// document itself for .doc, .xls and .ppt, vbaProject.bin inside the zip of
+// .docm, .xlsm and .pptm. The project's dir stream lists the modules, and
+// each module's stream holds its source, compressed, after the p-code.
+
+// oleMagic starts every OLE compound file
+const oleMagic = "\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1"
+
+const (
+	oleHeaderSize     = 512
+	oleDirEntrySize   = 128
+	oleEndOfChain     = 0xFFFFFFFE
+	oleHeaderDIFAT    = 109
+	oleTypeStorage    = 1
+	oleTypeStream     = 2
+	oleTypeRoot       = 5
+	vbaChunkSize      = 4096
+	vbaModuleName     = 0x0019
+	vbaModuleStream   = 0x001A
+	vbaModuleOffset   = 0x0031
+	vbaModuleEnd      = 0x002B
+	vbaProjectVersion = 0x0009
+)
+
+// IsOLE reports whether content is an OLE compound file
+func IsOLE(content string) bool {
+	return strings.HasPrefix(content, oleMagic)
+}
+
+// oleEntry is a storage or stream of a compound file
+type oleEntry struct {
+	name     string
+	kind     byte
+	left     uint32
+	right    uint32
+	child    uint32
+	start    uint32
+	size     uint64
+	children []int // Entries of a storage
+}
+
+type oleFile struct {
+	data       []byte
+	sectorSize int
+	fat        []uint32
+	miniFAT    []uint32
+	miniStream []byte
+	cutoff     uint64
+	entries    []oleEntry
+}
+
+// VBAScripts returns the source of the VBA modules of an OLE compound file,
+// named by module
+func VBAScripts(content string) ([]EmbeddedScript, error) {
+	ole, err := parseOLE([]byte(content))
+	if err != nil {
+		return nil, err
+	}
+	var scripts []EmbeddedScript
+	for i := range ole.entries {
+		// The VBA storage holds the dir stream and a stream per module
+		entry := &ole.entries[i]
+		if entry.kind != oleTypeStorage || !strings.EqualFold(entry.name, "VBA") {
+			continue
+		}
+		streams := make(map[string][]byte)
+		for _, child := range entry.children {
+			if ole.entries[child].kind == oleTypeStream {
+				streams[strings.ToLower(ole.entries[child].name)] = ole.stream(&ole.entries[child])
+			}
+		}
+		dir, ok := streams["dir"]
+		if !ok {
+			continue
+		}
+		for _, module := range vbaModules(vbaDecompress(dir)) {
+			stream, ok := streams[strings.ToLower(module.stream)]
+			if !ok || module.offset >= len(stream) {
+				continue
+			}
+			if source := strings.TrimSpace(string(vbaDecompress(stream[module.offset:]))); source != "" {
+				scripts = append(scripts, EmbeddedScript{Name: module.name, Content: source})
+			}
+		}
+	}
+	return scripts, nil
+}
+
+func parseOLE(data []byte) (*oleFile, error) {
+	if len(data) < oleHeaderSize || !IsOLE(string(data[:len(oleMagic)])) {
+		return nil, fmt.Errorf("not an OLE compound file")
+	}
+	u32 := func(b []byte, offset int) uint32 {
+		return binary.LittleEndian.Uint32(b[offset:])
+	}
+	shift := binary.LittleEndian.Uint16(data[0x1E:])
+	if shift != 9 && shift != 12 {
+		return nil, fmt.Errorf("OLE compound file: unsupported sector size 2^%d", shift)
+	}
+	ole := &oleFile{data: data, sectorSize: 1 << shift, cutoff: uint64(u32(data, 0x38))}
+
+	// The FAT's sectors are listed in the header, then in a chain of DIFAT
+	// sectors ending in a pointer to the next one
+	var fatSectors []uint32
+	for i := 0; i < oleHeaderDIFAT; i++ {
+		fatSectors = append(fatSectors, u32(data, 0x4C+i*4))
+	}
+	perSector := ole.sectorSize / 4
+	for sector, n := u32(data, 0x44), 0; sector < oleEndOfChain && n < int(u32(data, 0x48)); n++ {
+		block := ole.sector(sector)
+		if block == nil {
+			break
+		}
+		for i := 0; i < perSector-1; i++ {
+			fatSectors = append(fatSectors, u32(block, i*4))
+		}
+		sector = u32(block, (perSector-1)*4)
+	}
+	for i, sector := range fatSectors {
+		if i >= int(u32(data, 0x2C)) || sector >= oleEndOfChain {
+			break
+		}
+		block := ole.sector(sector)
+		if block == nil {
+			return nil, fmt.Errorf("OLE compound file: FAT sector %d out of bounds", sector)
+		}
+		for j := 0; j < perSector; j++ {
+			ole.fat = append(ole.fat, u32(block, j*4))
+		}
+	}
+
+	dir := ole.chain(u32(data, 0x30), 0)
+	for offset := 0; offset+oleDirEntrySize <= len(dir); offset += oleDirEntrySize {
+		raw := dir[offset : offset+oleDirEntrySize]
+		nameSize := int(binary.LittleEndian.Uint16(raw[0x40:]))
+		units := make([]uint16, 0, 32)
+		for i := 0; i+1 < nameSize && i < 64; i += 2 {
+			if unit := binary.LittleEndian.Uint16(raw[i:]); unit != 0 {
+				units = append(units, unit)
+			}
+		}
+		ole.entries = append(ole.entries, oleEntry{
+			name:  string(utf16.Decode(units)),
+			kind:  raw[0x42],
+			left:  u32(raw, 0x44),
+			right: u32(raw, 0x48),
+			child: u32(raw, 0x4C),
+			start: u32(raw, 0x74),
+			size:  binary.LittleEndian.Uint64(raw[0x78:]),
+		})
+	}
+	if ole.sectorSize == 512 {
+		// Version 3 files only set the low 32 bits of stream sizes
+		for i := range ole.entries {
+			ole.entries[i].size &= 0xFFFFFFFF
+		}
+	}
+	if len(ole.entries) == 0 || ole.entries[0].kind != oleTypeRoot {
+		return nil, fmt.Errorf("OLE compound file: no root storage")
+	}
+	for i := range ole.entries {
+		if kind := ole.entries[i].kind; kind == oleTypeStorage || kind == oleTypeRoot {
+			ole.entries[i].children = ole.siblings(ole.entries[i].child, make(map[uint32]bool))
+		}
+	}
+
+	// Streams under the cutoff size live in the mini stream, the root's
+	// stream, in 64-byte sectors chained by the mini FAT
+	root := &ole.entries[0]
+	ole.miniStream = ole.chain(root.start, root.size)
+	miniFAT := ole.chain(u32(data, 0x3C), 0)
+	for i := 0; i+4 <= len(miniFAT); i += 4 {
+		ole.miniFAT = append(ole.miniFAT, u32(miniFAT, i))
+	}
+	return ole, nil
+}
+
+// siblings walks the tree of a storage's children from entry id
+func (o *oleFile) siblings(id uint32, seen map[uint32]bool) []int {
+	if id >= uint32(len(o.entries)) || seen[id] {
+		return nil
+	}
+	seen[id] = true
+	entry := o.entries[id]
+	children := o.siblings(entry.left, seen)
+	children = append(children, int(id))
+	return append(children, o.siblings(entry.right, seen)...)
+}
+
+func (o *oleFile) sector(id uint32) []byte {
+	start := (int(id) + 1) * o.sectorSize
+	if id >= oleEndOfChain || start+o.sectorSize > len(o.data) {
+		return nil
+	}
+	return o.data[start : start+o.sectorSize]
+}
+
+// chain reads the sectors chained by the FAT from start, at most size bytes
+// (0 = the whole chain)
+func (o *oleFile) chain(start uint32, size uint64) []byte {
+	var data []byte
+	seen := make(map[uint32]bool)
+	for sector := start; sector < oleEndOfChain && !seen[sector] && int(sector) < len(o.fat); sector = o.fat[sector] {
+		seen[sector] = true
+		block := o.sector(sector)
+		if block == nil {
+			break
+		}
+		data = append(data, block...)
+	}
+	if size > 0 && size < uint64(len(data)) {
+		data = data[:size]
+	}
+	return data
+}
+
+// stream reads a stream entry, from the mini stream when it is small
+func (o *oleFile) stream(entry *oleEntry) []byte {
+	if entry.size >= o.cutoff {
+		return o.chain(entry.start, entry.size)
+	}
+	const miniSectorSize = 64
+	var data []byte
+	seen := make(map[uint32]bool)
+	for sector := entry.start; sector < oleEndOfChain && !seen[sector] && int(sector) < len(o.miniFAT); sector = o.miniFAT[sector] {
+		seen[sector] = true
+		start := int(sector) * miniSectorSize
+		if start+miniSectorSize > len(o.miniStream) {
+			break
+		}
+		data = append(data, o.miniStream[start:start+miniSectorSize]...)
+	}
+	if entry.size < uint64(len(data)) {
+		data = data[:entry.size]
+	}
+	return data
+}
+
+// vbaModule is a module of a VBA project: its source starts at offset in
+// its stream
+type vbaModule struct {
+	name   string
+	stream string
+	offset int
+}
+
+// vbaModules reads the modules from the records of a decompressed dir
+// stream: a 2-byte id and a 4-byte size before each record's data
+func vbaModules(dir []byte) []vbaModule {
+	var modules []vbaModule
+	var module vbaModule
+	for offset := 0; offset+6 <= len(dir); {
+		id := binary.LittleEndian.Uint16(dir[offset:])
+		size := int(binary.LittleEndian.Uint32(dir[offset+2:]))
+		offset += 6
+		if id == vbaProjectVersion {
+			size = 6 // The record's size field is wrong: always 4
+		}
+		if size < 0 || offset+size > len(dir) {
+			break
+		}
+		data := dir[offset : offset+size]
+		offset += size
+
+		switch id {
+		case vbaModuleName:
+			module = vbaModule{name: string(data)}
+		case vbaModuleStream:
+			module.stream = string(data)
+		case vbaModuleOffset:
+			if size == 4 {
+				module.offset = int(binary.LittleEndian.Uint32(data))
+			}
+		case vbaModuleEnd:
+			if module.name != "" && module.stream != "" {
+				modules = append(modules, module)
+			}
+			module = vbaModule{}
+		}
+	}
+	return modules
+}
+
+// vbaDecompress expands a compressed container: a signature byte, then
+// chunks of up to 4096 bytes, stored raw or as literal bytes and copy tokens
+// referring back into the chunk. A damaged container keeps what could be
+// expanded.
+func vbaDecompress(data []byte) []byte {
+	if len(data) == 0 || data[0] != 1 {
+		return nil
+	}
+	var out []byte
+	for pos := 1; pos+2 <= len(data) && len(out) < maxDecompressedSize; {
+		header := binary.LittleEndian.Uint16(data[pos:])
+		end := min(pos+int(header&0x0FFF)+3, len(data))
+		pos += 2
+		chunkStart := len(out)
+
+		if header&0x8000 == 0 {
+			end = min(pos+vbaChunkSize, len(data))
+			out = append(out, data[pos:end]...)
+			pos = end
+			continue
+		}
+		// A chunk decompresses to at most vbaChunkSize bytes (MS-OVBA 2.4.1)
+		for pos < end && len(out)-chunkStart < vbaChunkSize {
+			flags := data[pos]
+			pos++
+			for bit := 0; bit < 8 && pos < end && len(out)-chunkStart < vbaChunkSize; bit++ {
+				if flags&(1<<bit) == 0 {
+					out = append(out, data[pos])
+					pos++
+					continue
+				}
+				if pos+2 > end {
+					return out
+				}
+				token := int(binary.LittleEndian.Uint16(data[pos:]))
+				pos += 2
+				bitCount := 4
+				for 1<<bitCount < len(out)-chunkStart {
+					bitCount++
+				}
+				length := token&(0xFFFF>>bitCount) + 3
+				offset := token>>(16-bitCount) + 1
+				if offset > len(out)-chunkStart {
+					return out
+				}
+				length = min(length, vbaChunkSize-(len(out)-chunkStart))
+				for i := 0; i < length; i++ {
+					out = append(out, out[len(out)-offset])
+				}
+			}
+		}
+		pos = end
+	}
+	return out
+}
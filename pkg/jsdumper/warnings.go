@@ -0,0 +1,77 @@
+package jsdumper
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kinds of Warning
+const (
+	WarningHTML          = "html"          // HTML where JavaScript was expected, e.g. an error page
+	WarningBinary        = "binary"        // Binary content, not scanned
+	WarningDecompression = "decompression" // Response that could not be decompressed, or only in part
+	WarningTruncated     = "truncated"     // Response that ended before its Content-Length
+)
+
+// Warning is a data-quality issue with an input: its findings are missing or
+// incomplete for a reason other than there being none
+type Warning struct {
+	Kind   string `json:"kind"`
+	File   string `json:"file"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func (w Warning) key() string {
+	return w.Kind + "|" + w.File + "|" + w.Detail
+}
+
+func (a *AggregatedResults) FormatWarnings() []string {
+	var lines []string
+	for _, warning := range a.Warnings {
+		line := fmt.Sprintf("%s | %s", warning.Kind, warning.File)
+		if warning.Detail != "" {
+			line += " | " + warning.Detail
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// LooksLikeHTML reports whether content is an HTML document rather than
+// JavaScript, as when a server answers with an error or login page. Only the
+// start is looked at: scripts often hold "<html" in strings and comments.
+func LooksLikeHTML(content string) bool {
+	start := strings.ToLower(strings.TrimSpace(content))
+	if strings.HasPrefix(start, "<!doctype") ||
+		strings.HasPrefix(start, "<html") ||
+		strings.HasPrefix(start, "<?xml") {
+		return true
+	}
+	if len(start) <= 500 {
+		return false
+	}
+
+	// Many HTML tags and few JavaScript constructs in the first 500 characters
+	first500 := start[:500]
+	htmlTagCount := strings.Count(first500, "<html") +
+		strings.Count(first500, "<head") +
+		strings.Count(first500, "<body") +
+		strings.Count(first500, "<div") +
+		strings.Count(first500, "<script")
+	jsIndicators := strings.Count(first500, "function") +
+		strings.Count(first500, "var ") +
+		strings.Count(first500, "const ") +
+		strings.Count(first500, "let ") +
+		strings.Count(first500, "=>") +
+		strings.Count(first500, "()")
+	return htmlTagCount > 3 && htmlTagCount > jsIndicators*2
+}
+
+// Bytes LooksBinary looks at
+const binarySniffLength = 8000
+
+// LooksBinary reports whether content is binary (images, fonts, archives)
+// rather than text: like git, it looks for a NUL byte near the start
+func LooksBinary(content string) bool {
+	return strings.IndexByte(content[:min(len(content), binarySniffLength)], 0) != -1
+}
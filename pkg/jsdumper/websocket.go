@@ -0,0 +1,40 @@
+package jsdumper
+
+import "strings"
+
+// Extract ws:// and wss:// URLs, plus the relative or computed URLs passed to
+// new WebSocket(...), which the http(s) URL detector can't see
+func (e *Extractor) extractWebSockets(run *extraction, content string) []string {
+	var urls []string
+	seen := make(map[string]bool)
+
+	add := func(url string) {
+		if url != "" && !seen[url] {
+			urls = append(urls, url)
+			seen[url] = true
+		}
+	}
+
+	for _, match := range run.findAll("websocketURL", e.patterns.WebSocketURL, content) {
+		if canonical, ok := canonicalURL(match); ok {
+			add(normalizeURL(canonical, run.opts.URLs))
+		}
+	}
+
+	for _, match := range run.findAllSubmatch("websocketCall", e.patterns.WebSocketCall, content) {
+		value := match[1]
+		lower := strings.ToLower(value)
+		switch {
+		case strings.HasPrefix(lower, "ws://") || strings.HasPrefix(lower, "wss://"):
+			if canonical, ok := canonicalURL(value); ok {
+				add(normalizeURL(canonical, run.opts.URLs))
+			} else if strings.Contains(value, "${") {
+				add(value) // wss://${host}/socket
+			}
+		case strings.HasPrefix(value, "/") || strings.Contains(value, "${"):
+			add(value) // Resolved by the browser against the page
+		}
+	}
+
+	return urls
+}
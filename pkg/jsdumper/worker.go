@@ -0,0 +1,137 @@
+package jsdumper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Binding is a Cloudflare Worker binding the script uses or its bundled
+// wrangler configuration declares
+type Binding struct {
+	Kind string `json:"-"` // kv, r2, storage (kv or r2), service, d1, queue, durable_object, ai, analytics_engine, secret or var
+	Name string `json:"name"`
+	ID   string `json:"id,omitempty"` // KV namespace ID, R2 bucket or service name
+	File string `json:"file"`
+}
+
+// Wrangler configuration files scanned next to worker scripts
+var wranglerFiles = map[string]bool{"wrangler.toml": true, "wrangler.json": true, "wrangler.jsonc": true}
+
+// IsWranglerFile reports whether name is a Wrangler configuration file
+func IsWranglerFile(name string) bool {
+	return wranglerFiles[strings.ToLower(name)]
+}
+
+// Binding kinds by the first method called on a binding; KV and R2 share get/put/list/delete
+var bindingMethods = map[string]string{
+	"getWithMetadata":       "kv",
+	"head":                  "r2",
+	"createMultipartUpload": "r2",
+	"resumeMultipartUpload": "r2",
+	"get":                   "storage",
+	"put":                   "storage",
+	"list":                  "storage",
+	"delete":                "storage",
+	"fetch":                 "service",
+	"connect":               "service",
+	"prepare":               "d1",
+	"batch":                 "d1",
+	"exec":                  "d1",
+	"dump":                  "d1",
+	"send":                  "queue",
+	"sendBatch":             "queue",
+	"idFromName":            "durable_object",
+	"idFromString":          "durable_object",
+	"newUniqueId":           "durable_object",
+	"run":                   "ai",
+	"writeDataPoint":        "analytics_engine",
+}
+
+// Binding names that hold credentials rather than configuration
+var secretBindingName = regexp.MustCompile(`KEY|SECRET|TOKEN|PASSWORD|PASSWD|CREDENTIAL|PRIVATE|AUTH`)
+
+func (e *Extractor) extractBindings(run *extraction, content, fileName string) []Binding {
+	var bindings []Binding
+	seen := make(map[string]bool)
+
+	add := func(binding Binding) {
+		key := binding.Kind + ":" + binding.Name + ":" + binding.ID
+		if !seen[key] {
+			binding.File = fileName
+			bindings = append(bindings, binding)
+			seen[key] = true
+		}
+	}
+
+	// Declared bindings: wrangler configuration, also found inlined in bundles
+	for _, p := range e.patterns.WranglerBindings {
+		for _, match := range run.findAllSubmatch(p.name, p.pattern, content) {
+			add(Binding{Kind: p.kind, Name: match[1], ID: match[2]})
+		}
+	}
+
+	if !e.patterns.WorkerMarker.MatchString(content) {
+		return bindings
+	}
+
+	// Bindings read from the env parameter of the fetch/scheduled/queue
+	// handler (renamed by minifiers) or from a plain env object
+	envNames := map[string]bool{"env": true}
+	for _, match := range run.findAllSubmatch("workerHandler", e.patterns.WorkerHandler, content) {
+		envNames[match[1]] = true
+	}
+	for _, match := range run.findAllSubmatch("workerEnv", e.patterns.WorkerEnv, content) {
+		object, name, method := match[1], match[2], match[3]
+		if !envNames[object] {
+			continue
+		}
+		kind := bindingMethods[method]
+		if kind == "" {
+			kind = "var"
+			if secretBindingName.MatchString(name) {
+				kind = "secret"
+			}
+		}
+		add(Binding{Kind: kind, Name: name})
+	}
+
+	// A name also used through methods is that binding, not a plain variable
+	typed := make(map[string]bool)
+	for _, binding := range bindings {
+		if binding.Kind != "var" && binding.Kind != "secret" {
+			typed[binding.Name] = true
+		}
+	}
+	var kept []Binding
+	for _, binding := range bindings {
+		if typed[binding.Name] && (binding.Kind == "var" || binding.Kind == "secret") {
+			continue
+		}
+		kept = append(kept, binding)
+	}
+	return kept
+}
+
+// Write worker-bindings.json, grouping bindings by kind
+func (a *AggregatedResults) WriteBindings(filePath string) error {
+	groups := make(map[string][]Binding)
+	for _, binding := range a.Bindings {
+		groups[binding.Kind] = append(groups[binding.Kind], binding)
+	}
+
+	output := map[string]interface{}{"jsdumper": CurrentBuildInfo()}
+	for kind, group := range groups {
+		output[kind] = group
+	}
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode worker bindings: %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write worker bindings: %w", err)
+	}
+	return nil
+}
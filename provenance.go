@@ -0,0 +1,192 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// provenanceStatement is an in-toto Statement (https://in-toto.io/Statement/v1)
+// with a SLSA provenance predicate (https://slsa.dev/provenance/v1): the
+// subjects are the output files, the dependencies the scanned inputs
+type provenanceStatement struct {
+	Type          string              `json:"_type"`
+	Subject       []provenanceDigest  `json:"subject"`
+	PredicateType string              `json:"predicateType"`
+	Predicate     provenancePredicate `json:"predicate"`
+}
+
+type provenanceDigest struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest"`
+}
+
+type provenancePredicate struct {
+	BuildDefinition provenanceBuildDefinition `json:"buildDefinition"`
+	RunDetails      provenanceRunDetails      `json:"runDetails"`
+}
+
+type provenanceBuildDefinition struct {
+	BuildType            string                 `json:"buildType"`
+	ExternalParameters   map[string]interface{} `json:"externalParameters"`
+	InternalParameters   map[string]interface{} `json:"internalParameters"`
+	ResolvedDependencies []provenanceDigest     `json:"resolvedDependencies"`
+}
+
+type provenanceRunDetails struct {
+	Builder  provenanceBuilder  `json:"builder"`
+	Metadata provenanceMetadata `json:"metadata"`
+}
+
+type provenanceBuilder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version"`
+}
+
+type provenanceMetadata struct {
+	StartedOn  string `json:"startedOn"`
+	FinishedOn string `json:"finishedOn"`
+}
+
+// Flags whose value may hold credentials, replaced in the recorded arguments
+var redactedFlags = map[string]bool{"H": true, "cookie": true, "proxy": true, "sink": true}
+
+// recordInput remembers the digest of scanned content for provenance.json
+func (c *CLI) recordInput(content, fileName string) {
+	if c.config.Provenance {
+		c.recordDigest(fileName, sha256Digest([]byte(content)))
+	}
+}
+
+// recordDigest remembers an already computed digest, for content that was
+// never in memory whole
+func (c *CLI) recordDigest(fileName string, digest map[string]string) {
+	if !c.config.Provenance {
+		return
+	}
+	c.inputsMu.Lock()
+	defer c.inputsMu.Unlock()
+	c.inputs = append(c.inputs, provenanceDigest{URI: fileName, Digest: digest})
+}
+
+// writeProvenance writes provenance.json for the files written to the output
+// directory since the run started
+func (c *CLI) writeProvenance(filePath string) error {
+	// Truncated for file systems with coarse modification times
+	since := c.started.Truncate(time.Second)
+	entries, err := os.ReadDir(c.config.OutputDir)
+	if err != nil {
+		return fmt.Errorf("failed to list output directory: %w", err)
+	}
+	subjects := []provenanceDigest{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.ModTime().Before(since) || entry.Name() == filepath.Base(filePath) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(c.config.OutputDir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", entry.Name(), err)
+		}
+		subjects = append(subjects, provenanceDigest{Name: entry.Name(), Digest: sha256Digest(data)})
+	}
+
+	c.inputsMu.Lock()
+	inputs := append([]provenanceDigest{}, c.inputs...)
+	c.inputsMu.Unlock()
+	sort.SliceStable(inputs, func(i, j int) bool { return inputs[i].URI < inputs[j].URI })
+
+	internal := map[string]interface{}{
+		"patternSet": c.pipeline.Extractor().PatternSetHash(),
+		"environment": map[string]string{
+			"os":   runtime.GOOS,
+			"arch": runtime.GOARCH,
+			"go":   runtime.Version(),
+		},
+	}
+	if c.config.RulesFile != "" {
+		data, err := os.ReadFile(c.config.RulesFile)
+		if err != nil {
+			return fmt.Errorf("failed to hash rules file: %w", err)
+		}
+		internal["rules"] = provenanceDigest{URI: c.config.RulesFile, Digest: sha256Digest(data)}
+	}
+
+	statement := provenanceStatement{
+		Type:          "https://in-toto.io/Statement/v1",
+		Subject:       subjects,
+		PredicateType: "https://slsa.dev/provenance/v1",
+		Predicate: provenancePredicate{
+			BuildDefinition: provenanceBuildDefinition{
+				BuildType: "https://github.com/d0xng/jsdumper/provenance/v1",
+				ExternalParameters: map[string]interface{}{
+					"target": c.config.Target,
+					"args":   redactArgs(c.config.Args),
+				},
+				InternalParameters:   internal,
+				ResolvedDependencies: inputs,
+			},
+			RunDetails: provenanceRunDetails{
+				Builder: provenanceBuilder{
+					ID: "https://github.com/d0xng/jsdumper@" + jsdumper.Version(),
+					Version: map[string]string{
+						"jsdumper": jsdumper.Version(),
+						"commit":   jsdumper.CurrentBuildInfo().Commit,
+						"patterns": jsdumper.CurrentBuildInfo().Patterns,
+					},
+				},
+				Metadata: provenanceMetadata{
+					StartedOn:  c.started.UTC().Format(time.RFC3339),
+					FinishedOn: time.Now().UTC().Format(time.RFC3339),
+				},
+			},
+		},
+	}
+
+	data, err := json.MarshalIndent(statement, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode provenance: %w", err)
+	}
+	if err := os.WriteFile(filePath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write provenance: %w", err)
+	}
+	return nil
+}
+
+// redactArgs hides the values of flags that may carry credentials
+func redactArgs(args []string) []string {
+	redacted := make([]string, 0, len(args))
+	hideNext := false
+	for _, arg := range args {
+		if hideNext {
+			redacted = append(redacted, "<redacted>")
+			hideNext = false
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if strings.HasPrefix(arg, "-") && redactedFlags[name] {
+			if hasValue && value != "" {
+				arg = arg[:len(arg)-len(value)] + "<redacted>"
+			} else {
+				hideNext = true
+			}
+		}
+		redacted = append(redacted, arg)
+	}
+	return redacted
+}
+
+func sha256Digest(data []byte) map[string]string {
+	sum := sha256.Sum256(data)
+	return map[string]string{"sha256": hex.EncodeToString(sum[:])}
+}
@@ -0,0 +1,69 @@
+#!/bin/bash
+
+# jsdumper release build
+# Cross-compiles the binaries "jsdumper update" looks for into dist/, with
+# checksums.txt. Usage: ./release.sh v1.4.0
+#
+# UPDATE_PUBLIC_KEY (base64 ed25519 public key) makes the binaries refuse
+# unsigned updates; UPDATE_SIGNING_KEY (PEM private key file) then signs
+# checksums.txt into checksums.txt.sig.
+
+set -e
+
+VERSION="${1:?usage: ./release.sh <version>}"
+COMMIT="$(git rev-parse HEAD)"
+DATE="$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+# The pattern bundle is versioned by the last change to the detectors
+PATTERNS="${PATTERNS_VERSION:-$(git log -1 --format=%cd --date=format:%Y.%m.%d -- pkg/jsdumper/patterns.go pkg/jsdumper/rules.go)}"
+# Build metadata lives in the library package
+PKG="github.com/d0xng/jsdumper/pkg/jsdumper"
+PLATFORMS="linux/amd64 linux/arm64 linux/386 darwin/amd64 darwin/arm64 windows/amd64 windows/arm64 freebsd/amd64"
+
+LDFLAGS="-s -w -X $PKG.version=$VERSION -X $PKG.commit=$COMMIT -X $PKG.buildDate=$DATE -X $PKG.patternsVersion=$PATTERNS"
+if [ -n "$UPDATE_PUBLIC_KEY" ]; then
+    # Binaries checking signatures must not be released without one
+    if [ -z "$UPDATE_SIGNING_KEY" ]; then
+        echo "UPDATE_PUBLIC_KEY is set without UPDATE_SIGNING_KEY: updates of this release would be refused" >&2
+        exit 1
+    fi
+    if [ "$(openssl pkey -in "$UPDATE_SIGNING_KEY" -pubout -outform DER | tail -c 32 | base64)" != "$UPDATE_PUBLIC_KEY" ]; then
+        echo "UPDATE_SIGNING_KEY does not match UPDATE_PUBLIC_KEY" >&2
+        exit 1
+    fi
+    LDFLAGS="$LDFLAGS -X main.updatePublicKey=$UPDATE_PUBLIC_KEY"
+fi
+
+SCRIPT_DIR="$( cd "$( dirname "${BASH_SOURCE[0]}" )" && pwd )"
+cd "$SCRIPT_DIR"
+rm -rf dist
+mkdir -p dist
+
+for platform in $PLATFORMS; do
+    os="${platform%/*}"
+    arch="${platform#*/}"
+    name="jsdumper_${os}_${arch}"
+    if [ "$os" = "windows" ]; then
+        name="$name.exe"
+    fi
+    echo "Building $name..."
+    CGO_ENABLED=0 GOOS="$os" GOARCH="$arch" go build -trimpath -ldflags "$LDFLAGS" -o "dist/$name" .
+done
+
+# The C library needs cgo, so it is only built for the host platform
+if [ -n "$LIB" ]; then
+    case "$(go env GOOS)" in
+        darwin) lib="libjsdumper.dylib" ;;
+        windows) lib="libjsdumper.dll" ;;
+        *) lib="libjsdumper.so" ;;
+    esac
+    echo "Building $lib..."
+    CGO_ENABLED=1 go build -buildmode=c-shared -trimpath -ldflags "$LDFLAGS" -o "dist/$lib" ./cmd/libjsdumper
+fi
+
+cd dist
+sha256sum jsdumper_* ${lib:-} > checksums.txt
+if [ -n "$UPDATE_PUBLIC_KEY" ]; then
+    openssl pkeyutl -sign -inkey "$UPDATE_SIGNING_KEY" -rawin -in checksums.txt | base64 | tr -d '\n' > checksums.txt.sig
+fi
+echo ""
+echo "Release $VERSION (patterns $PATTERNS) written to dist/"
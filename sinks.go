@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// Sink receives every finding of a run, e.g. to forward it to a SIEM
+type Sink interface {
+	Send(finding jsdumper.Finding) error
+	Close() error
+}
+
+// SinkMessage is the data a -sink-template is rendered with, once per run
+type SinkMessage struct {
+	Target     string             // URL, list, file or directory that was scanned
+	Report     string             // Absolute path of the output directory
+	Findings   []jsdumper.Finding // Findings of the run, only new ones with -baseline
+	Severities map[string]int     // Number of findings per severity
+}
+
+// Load a Go text/template used as the body of http(s) sink requests
+func LoadSinkTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sink template: %w", err)
+	}
+
+	funcs := template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			encoded, err := json.Marshal(v)
+			return string(encoded), err
+		},
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+	tmpl, err := template.New(path).Funcs(funcs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sink template %s: %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// Create a sink from a -sink specification:
+//
+//	exec:<command>          run command per finding, JSON on stdin
+//	http(s)://host/path     POST each finding as JSON, or the run's findings
+//	                        rendered by message in one request
+//	syslog[://host:port]    local syslog, or a remote one over UDP
+//
+// base supplies the Target and Report fields of templated messages.
+func NewSink(spec string, message *template.Template, base SinkMessage) (Sink, error) {
+	switch {
+	case strings.HasPrefix(spec, "exec:"):
+		command := strings.TrimSpace(strings.TrimPrefix(spec, "exec:"))
+		if command == "" {
+			return nil, fmt.Errorf("exec sink needs a command")
+		}
+		return &execSink{command: command}, nil
+	case isURL(spec):
+		return &httpSink{url: spec, client: &http.Client{Timeout: 15 * time.Second}, message: message, base: base}, nil
+	case spec == "syslog" || strings.HasPrefix(spec, "syslog://"):
+		return newSyslogSink(strings.TrimPrefix(strings.TrimPrefix(spec, "syslog"), "://"))
+	}
+	return nil, fmt.Errorf("unknown sink %q (expected exec:<cmd>, an http(s) URL or syslog)", spec)
+}
+
+type execSink struct {
+	command string
+}
+
+func (s *execSink) Send(finding jsdumper.Finding) error {
+	data, err := json.Marshal(finding)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", s.command)
+	} else {
+		cmd = exec.Command("sh", "-c", s.command)
+	}
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"JSDUMPER_CATEGORY="+finding.Category,
+		"JSDUMPER_TYPE="+finding.Type,
+		"JSDUMPER_SEVERITY="+finding.Severity,
+		"JSDUMPER_FILE="+finding.File,
+		"JSDUMPER_VALUE="+finding.Value,
+		"JSDUMPER_FLOW="+finding.Flow,
+		"JSDUMPER_ENCODING="+finding.Encoding,
+		"JSDUMPER_TAGS="+strings.Join(finding.Tags, ","),
+	)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sink command failed: %w", err)
+	}
+	return nil
+}
+
+func (s *execSink) Close() error {
+	return nil
+}
+
+type httpSink struct {
+	url      string
+	client   *http.Client
+	message  *template.Template
+	base     SinkMessage
+	findings []jsdumper.Finding // Buffered for the templated message
+}
+
+// Send posts finding, or buffers it until Close when the sink has a template
+func (s *httpSink) Send(finding jsdumper.Finding) error {
+	if s.message != nil {
+		s.findings = append(s.findings, finding)
+		return nil
+	}
+	data, err := json.Marshal(finding)
+	if err != nil {
+		return err
+	}
+	return s.post(data)
+}
+
+func (s *httpSink) post(data []byte) error {
+	req, err := http.NewRequest("POST", s.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if json.Valid(data) {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	req.Header.Set("User-Agent", "jsdumper/"+jsdumper.Version())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to sink: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sink %s returned HTTP %d", s.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// Close posts the sink template rendered with the buffered findings; runs
+// without findings send nothing
+func (s *httpSink) Close() error {
+	if s.message == nil || len(s.findings) == 0 {
+		return nil
+	}
+
+	message := s.base
+	message.Findings = s.findings
+	message.Severities = make(map[string]int)
+	for _, finding := range s.findings {
+		if finding.Severity != "" {
+			message.Severities[finding.Severity]++
+		}
+	}
+	s.findings = nil
+
+	var buf bytes.Buffer
+	if err := s.message.Execute(&buf, message); err != nil {
+		return fmt.Errorf("failed to render sink template: %w", err)
+	}
+	return s.post(buf.Bytes())
+}
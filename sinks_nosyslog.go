@@ -0,0 +1,9 @@
+//go:build windows || plan9
+
+package main
+
+import "fmt"
+
+func newSyslogSink(addr string) (Sink, error) {
+	return nil, fmt.Errorf("syslog sink is not supported on this platform")
+}
@@ -0,0 +1,44 @@
+//go:build !windows && !plan9
+
+package main
+
+import (
+	"encoding/json"
+	"log/syslog"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+// Connect to the local syslog daemon, or to addr over UDP when given
+func newSyslogSink(addr string) (Sink, error) {
+	network := ""
+	if addr != "" {
+		network = "udp"
+	}
+	writer, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, "jsdumper")
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+func (s *syslogSink) Send(finding jsdumper.Finding) error {
+	data, err := json.Marshal(finding)
+	if err != nil {
+		return err
+	}
+	switch finding.Severity {
+	case "CRITICAL", "HIGH":
+		return s.writer.Warning(string(data))
+	default:
+		return s.writer.Info(string(data))
+	}
+}
+
+func (s *syslogSink) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	urlpkg "net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// Matches both //# sourceMappingURL=... (JS) and /*# sourceMappingURL=... */ (CSS)
+var sourceMappingURLPattern = regexp.MustCompile(`(?://|/\*)[#@]\s*sourceMappingURL\s*=\s*([^\s*]+)`)
+
+type sourceMap struct {
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent"`
+}
+
+// Find the last sourceMappingURL comment of a script
+func findSourceMapURL(content string) string {
+	matches := sourceMappingURLPattern.FindAllStringSubmatch(content, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1][1]
+}
+
+// Resolve a sourceMappingURL against the URL of the script that references it
+func resolveSourceMapURL(scriptURL, mapURL string) (string, error) {
+	base, err := urlpkg.Parse(scriptURL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := urlpkg.Parse(mapURL)
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// Decode an inline data:application/json;base64,... source map
+func decodeInlineSourceMap(dataURL string) ([]byte, error) {
+	comma := strings.Index(dataURL, ",")
+	if comma == -1 {
+		return nil, fmt.Errorf("malformed data URL")
+	}
+	meta, payload := dataURL[:comma], dataURL[comma+1:]
+	if strings.HasSuffix(meta, ";base64") {
+		return base64.StdEncoding.DecodeString(payload)
+	}
+	decoded, err := urlpkg.PathUnescape(payload)
+	return []byte(decoded), err
+}
+
+// sourceMapResults fetches the source map referenced by a downloaded script
+// and extracts from every original source it embeds (sourcesContent)
+func (c *CLI) sourceMapResults(ctx context.Context, scriptURL, content, localPath string) []*jsdumper.Results {
+	if c.config.NoSourceMaps {
+		return nil
+	}
+	mapURL := findSourceMapURL(content)
+	if mapURL == "" || c.skipSourceMaps() {
+		return nil
+	}
+
+	var data []byte
+	var err error
+	if strings.HasPrefix(mapURL, "data:") {
+		data, err = decodeInlineSourceMap(mapURL)
+	} else {
+		var resolved string
+		resolved, err = resolveSourceMapURL(scriptURL, mapURL)
+		if err == nil {
+			c.log(fmt.Sprintf("Downloading source map: %s", resolved), colorDim)
+			mapPath := localPath + ".map"
+			if err = c.downloader.DownloadContext(ctx, resolved, mapPath); err == nil {
+				data, err = os.ReadFile(mapPath)
+			}
+		}
+	}
+	if err != nil {
+		c.log(fmt.Sprintf("Error fetching source map for %s: %v", scriptURL, err), colorYellow)
+		return nil
+	}
+
+	var sm sourceMap
+	if err := json.Unmarshal(data, &sm); err != nil {
+		c.log(fmt.Sprintf("Error parsing source map for %s: %v", scriptURL, err), colorYellow)
+		return nil
+	}
+
+	var results []*jsdumper.Results
+	for i, source := range sm.SourcesContent {
+		if source == nil || *source == "" {
+			continue
+		}
+		name := fmt.Sprintf("source_%d", i)
+		if i < len(sm.Sources) && sm.Sources[i] != "" {
+			name = sm.Sources[i]
+		}
+		// Third-party code is noise for secret and endpoint hunting
+		if strings.Contains(name, "node_modules/") {
+			continue
+		}
+		c.recordSource(name, scriptURL)
+		results = append(results, c.extractContext(ctx, *source, name))
+	}
+
+	c.log(fmt.Sprintf("Extracted %d original source(s) from source map", len(results)), colorGreen)
+	return results
+}
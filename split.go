@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// writeSplitFile writes lines like writeFile, but once they exceed
+// Config.SplitSize bytes into numbered parts (urls.part1.txt, ...) listed in
+// an index file (urls.index.txt) instead of a single urls.txt
+func (c *CLI) writeSplitFile(filePath string, lines []string) error {
+	ext := filepath.Ext(filePath)
+	base := strings.TrimSuffix(filePath, ext)
+	indexPath := base + ".index" + ext
+
+	// Parts of a previous, larger run would be mistaken for current results
+	stale, _ := filepath.Glob(base + ".part*" + ext)
+	for _, path := range append(stale, indexPath) {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+
+	newlineSize := int64(1)
+	if c.config.CRLF {
+		newlineSize = 2
+	}
+
+	// Every part starts with its own byte order mark
+	var headerSize int64
+	if c.config.BOM {
+		headerSize = int64(len("\uFEFF"))
+	}
+
+	var parts [][]string
+	var size int64
+	for _, line := range lines {
+		lineSize := int64(len(line)) + newlineSize
+		// A line longer than the limit gets a part of its own
+		if len(parts) == 0 || (size+lineSize > c.config.SplitSize && size > headerSize) {
+			parts = append(parts, nil)
+			size = headerSize
+		}
+		parts[len(parts)-1] = append(parts[len(parts)-1], line)
+		size += lineSize
+	}
+
+	if len(parts) <= 1 {
+		return c.writeFile(filePath, lines, false)
+	}
+	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", filePath, err)
+	}
+
+	var index []string
+	for i, part := range parts {
+		partPath := base + ".part" + strconv.Itoa(i+1) + ext
+		if err := c.writeFile(partPath, part, false); err != nil {
+			return err
+		}
+		index = append(index, filepath.Base(partPath))
+	}
+	if err := c.writeFile(indexPath, index, false); err != nil {
+		return err
+	}
+
+	c.log(fmt.Sprintf("%s split into %d parts, listed in %s", filepath.Base(filePath), len(parts), indexPath), colorGreen)
+	return nil
+}
+
+// parseSize parses a size such as "10MB", "512KB" or "1048576" (binary units)
+func parseSize(value string) (int64, error) {
+	units := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	}
+
+	number := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(number, unit.suffix) {
+			number = strings.TrimSpace(strings.TrimSuffix(number, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q (expected e.g. 10MB)", value)
+	}
+	return int64(n * float64(multiplier)), nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import "fmt"
+
+// ScriptIntegrity describes one external <script src> of an HTML page
+type ScriptIntegrity struct {
+	Src       string
+	Integrity string
+}
+
+// Find the external scripts of an HTML page and their integrity attributes
+func analyzeSRI(html string) []ScriptIntegrity {
+	var scripts []ScriptIntegrity
+	for _, tag := range parseScriptTags(html) {
+		if tag.Src == "" {
+			continue // Inline script
+		}
+		scripts = append(scripts, ScriptIntegrity{Src: tag.Src, Integrity: tag.Integrity})
+	}
+	return scripts
+}
+
+func formatSRI(scripts []ScriptIntegrity) []string {
+	var lines []string
+	for _, script := range scripts {
+		if script.Integrity != "" {
+			lines = append(lines, fmt.Sprintf("PROTECTED | %s | %s", script.Src, script.Integrity))
+		} else {
+			lines = append(lines, fmt.Sprintf("MISSING | %s", script.Src))
+		}
+	}
+	return lines
+}
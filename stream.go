@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// jsonlStream writes findings.jsonl while a run is in progress (-format
+// jsonl): the findings of each input are appended as soon as it is scanned,
+// one JSON object per line, so long list scans can be followed with tail -f.
+// Inputs are scanned in parallel, and a finding already streamed from another
+// file is written as an occurrence of it rather than a second time. Sightings
+// in a file it was already seen in, such as the overlap of two chunks of a
+// large file, are not written at all.
+type jsonlStream struct {
+	mu       sync.Mutex
+	file     *os.File
+	streamed map[*jsdumper.Results]bool
+	seen     map[string]int  // Sightings of the streamed findings, by fingerprint
+	seenIn   map[string]bool // Fingerprints and the files they were seen in
+
+	// Findings of the -baseline, left out like in the other outputs
+	knownSecrets   map[string]bool
+	knownEndpoints map[string]bool
+	knownURLs      map[string]bool
+}
+
+func newJSONLStream(filePath string, appendMode bool, baseline *Baseline) (*jsonlStream, error) {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendMode {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(filePath, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JSON Lines file: %w", err)
+	}
+
+	s := &jsonlStream{file: file, streamed: make(map[*jsdumper.Results]bool), seen: make(map[string]int), seenIn: make(map[string]bool)}
+	if baseline != nil {
+		s.knownSecrets = toSet(baseline.Secrets)
+		s.knownEndpoints = toSet(baseline.Endpoints)
+		s.knownURLs = toSet(baseline.URLs)
+	}
+	return s, nil
+}
+
+// write appends the findings of results that were not streamed yet
+func (s *jsonlStream) write(results []*jsdumper.Results) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, result := range results {
+		if result == nil || s.streamed[result] {
+			continue
+		}
+		s.streamed[result] = true
+
+		var lines []byte
+		for _, finding := range result.Findings() {
+			if s.known(finding) {
+				continue
+			}
+			fingerprint := findingFingerprint(finding)
+			key := fingerprint + "\x00" + finding.File
+			if s.seenIn[key] {
+				continue
+			}
+			s.seenIn[key] = true
+			s.seen[fingerprint]++
+			var event interface{} = streamedFinding{Finding: finding, Fingerprint: fingerprint}
+			if sighting := s.seen[fingerprint]; sighting > 1 {
+				event = streamedOccurrence{Category: "occurrence", Fingerprint: fingerprint, File: finding.File, Sighting: sighting}
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				return fmt.Errorf("failed to encode finding: %w", err)
+			}
+			lines = append(append(lines, data...), '\n')
+		}
+		// One write per input keeps its lines together for readers tailing the file
+		if _, err := s.file.Write(lines); err != nil {
+			return fmt.Errorf("failed to write JSON Lines file: %w", err)
+		}
+	}
+	return nil
+}
+
+// mark records results as streamed, or forgets them so spilled results
+// aren't kept in memory by the stream
+func (s *jsonlStream) mark(results []*jsdumper.Results, streamed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, result := range results {
+		if streamed {
+			s.streamed[result] = true
+		} else {
+			delete(s.streamed, result)
+		}
+	}
+}
+
+// streamedFinding is the first sighting of a finding
+type streamedFinding struct {
+	jsdumper.Finding
+	Fingerprint string `json:"fingerprint"`
+}
+
+// streamedOccurrence is a later sighting of a streamed finding, in another
+// input or file
+type streamedOccurrence struct {
+	Category    string `json:"category"` // Always occurrence
+	Fingerprint string `json:"fingerprint"`
+	File        string `json:"file,omitempty"`
+	Sighting    int    `json:"sighting"` // 2 for the second sighting, and so on
+}
+
+// findingFingerprint identifies a finding across inputs by its category,
+// type and value, wherever it was found
+func findingFingerprint(finding jsdumper.Finding) string {
+	sum := sha256.Sum256([]byte(finding.Category + "\x00" + finding.Type + "\x00" + finding.Value))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (s *jsonlStream) known(finding jsdumper.Finding) bool {
+	switch finding.Category {
+	case "secret":
+		return s.knownSecrets[secretHash(jsdumper.Secret{Type: finding.Type, Value: finding.Value})]
+	case "endpoint":
+		return s.knownEndpoints[finding.Value]
+	case "url":
+		return s.knownURLs[finding.Value]
+	}
+	return false
+}
+
+func (s *jsonlStream) Close() error {
+	return s.file.Close()
+}
+
+// streamResults hands scanned results to the JSON Lines stream, if enabled
+func (c *CLI) streamResults(results []*jsdumper.Results) {
+	if c.stream == nil {
+		return
+	}
+	if err := c.stream.write(results); err != nil {
+		c.log(fmt.Sprintf("Error streaming findings: %v", err), colorRed)
+	}
+}
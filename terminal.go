@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Terminal writes status lines to stdout, deciding once whether ANSI colors
+// and non-ASCII characters can be used on the attached console
+type Terminal struct {
+	mu    sync.Mutex
+	out   io.Writer
+	color bool
+	ascii bool
+	quiet bool
+}
+
+func NewTerminal(noColor, ascii, quiet bool) *Terminal {
+	return &Terminal{
+		out:   os.Stdout,
+		color: !noColor && colorsSupported(os.Stdout),
+		ascii: ascii,
+		quiet: quiet,
+	}
+}
+
+func (t *Terminal) Println(message string, color string) {
+	if t.quiet {
+		return
+	}
+	if t.ascii {
+		message = toASCII(message)
+	}
+
+	// Workers log concurrently; keep lines whole
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.color || color == "" {
+		fmt.Fprintln(t.out, message)
+	} else {
+		fmt.Fprintf(t.out, "%s%s%s\n", color, message, colorReset)
+	}
+}
+
+// Check whether ANSI escapes should be written to f
+func colorsSupported(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false // Redirected to a file or pipe
+	}
+	return enableVirtualTerminal(f)
+}
+
+// Replace non-ASCII characters for constrained terminals
+func toASCII(str string) string {
+	return strings.Map(func(r rune) rune {
+		if r > 0x7E || (r < 0x20 && r != '\t') {
+			return '?'
+		}
+		return r
+	}, str)
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package main
+
+import "os"
+
+// ANSI escapes work on every non-Windows terminal we support
+func enableVirtualTerminal(f *os.File) bool {
+	return true
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// updatePublicKey is the base64 ed25519 key release checksums are signed with.
+// When set (via -ldflags), updates refuse releases without a valid signature.
+var updatePublicKey = ""
+
+const releaseAPI = "https://api.github.com/repos/d0xng/jsdumper/releases/latest"
+
+type release struct {
+	TagName string         `json:"tag_name"`
+	HTMLURL string         `json:"html_url"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+func (r *release) asset(name string) *releaseAsset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// runVersion handles "jsdumper version [-check]"
+func runVersion(args []string) error {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	check := fs.Bool("check", false, "Check GitHub for a newer release")
+	fs.Parse(args)
+
+	fmt.Print(jsdumper.CurrentBuildInfo().String())
+	if !*check {
+		return nil
+	}
+
+	latest, err := fetchLatestRelease()
+	if err != nil {
+		return err
+	}
+	if compareVersions(latest.TagName, jsdumper.Version()) > 0 {
+		fmt.Printf("A newer release is available: %s (%s)\n", latest.TagName, latest.HTMLURL)
+		fmt.Println("Run 'jsdumper update' to install it")
+	} else {
+		fmt.Println("You are running the latest release")
+	}
+	return nil
+}
+
+// runUpdate handles "jsdumper update [-force]": downloads the latest release
+// binary for this platform, verifies it, and replaces the running executable
+func runUpdate(args []string) error {
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	force := fs.Bool("force", false, "Reinstall even if already on the latest release")
+	fs.Parse(args)
+
+	latest, err := fetchLatestRelease()
+	if err != nil {
+		return err
+	}
+	if !*force && compareVersions(latest.TagName, jsdumper.Version()) <= 0 {
+		fmt.Printf("jsdumper %s is already the latest release\n", jsdumper.Version())
+		return nil
+	}
+
+	assetName := fmt.Sprintf("jsdumper_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		assetName += ".exe"
+	}
+	binAsset := latest.asset(assetName)
+	if binAsset == nil {
+		return fmt.Errorf("release %s has no binary for %s/%s", latest.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	sumAsset := latest.asset("checksums.txt")
+	if sumAsset == nil {
+		return fmt.Errorf("release %s has no checksums.txt, refusing to update", latest.TagName)
+	}
+
+	fmt.Printf("Downloading %s %s...\n", assetName, latest.TagName)
+	checksums, err := fetchBytes(sumAsset.URL)
+	if err != nil {
+		return fmt.Errorf("failed to download checksums: %w", err)
+	}
+	if err := verifyChecksumsSignature(latest, checksums); err != nil {
+		return err
+	}
+
+	binary, err := fetchBytes(binAsset.URL)
+	if err != nil {
+		return fmt.Errorf("failed to download binary: %w", err)
+	}
+	if err := verifyChecksum(checksums, assetName, binary); err != nil {
+		return err
+	}
+
+	if err := replaceExecutable(binary); err != nil {
+		return err
+	}
+	fmt.Printf("Updated jsdumper %s -> %s\n", jsdumper.Version(), latest.TagName)
+	return nil
+}
+
+func fetchLatestRelease() (*release, error) {
+	data, err := fetchBytes(releaseAPI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest release: %w", err)
+	}
+	var latest release
+	if err := json.Unmarshal(data, &latest); err != nil {
+		return nil, fmt.Errorf("failed to parse release info: %w", err)
+	}
+	if latest.TagName == "" {
+		return nil, fmt.Errorf("no published release found")
+	}
+	return &latest, nil
+}
+
+func fetchBytes(url string) ([]byte, error) {
+	client := &http.Client{Timeout: 60 * time.Second}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "jsdumper/"+jsdumper.Version())
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// verifyChecksumsSignature checks checksums.txt.sig against updatePublicKey.
+// Builds without an embedded key rely on the checksum alone.
+func verifyChecksumsSignature(latest *release, checksums []byte) error {
+	if updatePublicKey == "" {
+		return nil
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(updatePublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid embedded update public key")
+	}
+
+	sigAsset := latest.asset("checksums.txt.sig")
+	if sigAsset == nil {
+		return fmt.Errorf("release %s has no checksums.txt.sig, refusing to update", latest.TagName)
+	}
+	sigData, err := fetchBytes(sigAsset.URL)
+	if err != nil {
+		return fmt.Errorf("failed to download signature: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigData)))
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+	if !ed25519.Verify(publicKey, checksums, signature) {
+		return fmt.Errorf("checksums.txt signature verification failed")
+	}
+	return nil
+}
+
+// verifyChecksum looks up assetName in a sha256sum-style checksums file
+func verifyChecksum(checksums []byte, assetName string, data []byte) error {
+	sum := sha256.Sum256(data)
+	actual := hex.EncodeToString(sum[:])
+
+	for _, line := range strings.Split(string(checksums), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != assetName {
+			continue
+		}
+		if !strings.EqualFold(fields[0], actual) {
+			return fmt.Errorf("checksum mismatch for %s", assetName)
+		}
+		return nil
+	}
+	return fmt.Errorf("no checksum listed for %s", assetName)
+}
+
+// replaceExecutable swaps the running binary for data, resolving symlinks
+// such as the /usr/bin/jsdumper link created by install.sh
+func replaceExecutable(data []byte) error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+
+	tmpPath := exePath + ".new"
+	if err := os.WriteFile(tmpPath, data, 0755); err != nil {
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+
+	// Windows cannot overwrite a running executable, but it can rename it
+	oldPath := exePath + ".old"
+	os.Remove(oldPath)
+	if err := os.Rename(exePath, oldPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move current binary: %w", err)
+	}
+	if err := os.Rename(tmpPath, exePath); err != nil {
+		os.Rename(oldPath, exePath)
+		return fmt.Errorf("failed to install new binary: %w", err)
+	}
+	os.Remove(oldPath)
+	return nil
+}
+
+// compareVersions compares dotted versions like "v1.4.2", returning -1, 0 or 1.
+// Non-release builds ("dev") always compare lower than a tagged release.
+func compareVersions(a, b string) int {
+	parse := func(v string) []int {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+		if i := strings.IndexAny(v, "-+"); i != -1 {
+			v = v[:i]
+		}
+		var parts []int
+		for _, p := range strings.Split(v, ".") {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				return nil
+			}
+			parts = append(parts, n)
+		}
+		return parts
+	}
+
+	pa, pb := parse(a), parse(b)
+	if pa == nil || pb == nil {
+		switch {
+		case pa == nil && pb == nil:
+			return 0
+		case pa == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
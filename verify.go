@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// runPatterns handles "jsdumper patterns verify [-rules file] [-v]": runs the
+// built-in detector corpus and the examples of user rules
+func runPatterns(args []string) error {
+	if len(args) == 0 || args[0] != "verify" {
+		return fmt.Errorf("usage: jsdumper patterns verify [-rules file] [-v]")
+	}
+
+	fs := flag.NewFlagSet("patterns verify", flag.ExitOnError)
+	rulesFile := fs.String("rules", "", "YAML rules file whose examples are verified too")
+	verbose := fs.Bool("v", false, "List passing cases as well")
+	fs.Parse(args[1:])
+
+	cases := builtinCorpus
+	extractor := jsdumper.NewExtractor()
+	if *rulesFile != "" {
+		rules, err := jsdumper.LoadRules(*rulesFile)
+		if err != nil {
+			return err
+		}
+		extractor.SetRules(rules)
+		cases = append(append([]corpusCase{}, cases...), ruleCorpus(rules)...)
+	}
+
+	failed := 0
+	for _, c := range cases {
+		ok, err := c.verify(extractor)
+		if err != nil {
+			return err
+		}
+		if ok && !*verbose {
+			continue
+		}
+
+		status := "PASS"
+		if !ok {
+			status = "FAIL"
+			failed++
+		}
+		expect := "match"
+		if !c.Match {
+			expect = "no match"
+		}
+		fmt.Printf("%s  %-30s %-8s %s\n", status, c.name(), expect, c.Snippet)
+	}
+
+	fmt.Printf("%d/%d cases passed\n", len(cases)-failed, len(cases))
+	if failed > 0 {
+		return fmt.Errorf("%d pattern case(s) failed", failed)
+	}
+	return nil
+}
+
+// Turn the examples of user rules into corpus cases
+func ruleCorpus(rules []jsdumper.Rule) []corpusCase {
+	var cases []corpusCase
+	for _, rule := range rules {
+		for _, snippet := range rule.Examples.Positive {
+			cases = append(cases, corpusCase{Detector: jsdumper.DetectorSecrets, Type: rule.Name, Snippet: snippet, Match: true})
+		}
+		for _, snippet := range rule.Examples.Negative {
+			cases = append(cases, corpusCase{Detector: jsdumper.DetectorSecrets, Type: rule.Name, Snippet: snippet, Match: false})
+		}
+	}
+	return cases
+}
+
+func (c corpusCase) name() string {
+	if c.Type != "" {
+		return c.Type
+	}
+	return strings.ToUpper(c.Detector) + " " + c.Value
+}
+
+// Run the case's snippet through the extractor and compare with its expectation
+func (c corpusCase) verify(extractor *jsdumper.Extractor) (bool, error) {
+	opts := jsdumper.DefaultOptions()
+	opts.Detectors = []string{c.Detector}
+	results, err := extractor.ExtractAll(context.Background(), c.Snippet, "corpus", opts)
+	if err != nil {
+		return false, fmt.Errorf("failed to run case %s: %w", c.name(), err)
+	}
+
+	found := false
+	switch c.Detector {
+	case jsdumper.DetectorSecrets:
+		for _, secret := range results.Secrets {
+			if secret.Type == c.Type && (c.Value == "" || secret.Value == c.Value) {
+				found = true
+			}
+		}
+	case jsdumper.DetectorEndpoints:
+		found = slices.Contains(results.Endpoints, c.Value)
+	case jsdumper.DetectorURLs:
+		found = slices.Contains(results.URLs, c.Value)
+	case jsdumper.DetectorInteresting:
+		for _, hit := range results.Interesting {
+			if hit.Keyword+":"+hit.Context == c.Value {
+				found = true
+			}
+		}
+	case jsdumper.DetectorIntegrations:
+		for _, integration := range results.Integrations {
+			if integration.Value == c.Value {
+				found = true
+			}
+		}
+	case jsdumper.DetectorConfig:
+		for _, exposure := range results.ConfigExposures {
+			if exposure.Value == c.Value {
+				found = true
+			}
+		}
+	case jsdumper.DetectorBindings:
+		for _, binding := range results.Bindings {
+			if binding.Kind+":"+binding.Name == c.Value {
+				found = true
+			}
+		}
+	case jsdumper.DetectorWebSockets:
+		found = slices.Contains(results.WebSockets, c.Value)
+	case jsdumper.DetectorHTTPClients:
+		for _, client := range results.HTTPClients {
+			if client.Name+":"+client.BaseURL == c.Value {
+				found = true
+			}
+		}
+	case jsdumper.DetectorDOMSinks:
+		for _, sink := range results.DOMSinks {
+			if sink.Sink == c.Value {
+				found = true
+			}
+		}
+	case jsdumper.DetectorGraphQL:
+		for _, operation := range results.GraphQL {
+			if operation.Type+":"+operation.Name+":"+strings.Join(operation.Fields, ",") == c.Value {
+				found = true
+			}
+		}
+	case jsdumper.DetectorPersisted:
+		for _, query := range results.PersistedQueries {
+			if query.Hash+":"+query.Name == c.Value {
+				found = true
+			}
+		}
+	case jsdumper.DetectorIPs:
+		for _, ip := range results.IPs {
+			if ip.Scope+":"+ip.Value == c.Value {
+				found = true
+			}
+		}
+	case jsdumper.DetectorChunks:
+		for _, chunk := range results.ChunkNames {
+			if chunk.Name+":"+chunk.Route == c.Value {
+				found = true
+			}
+		}
+	case jsdumper.DetectorInfra:
+		for _, ref := range results.InfraReferences {
+			if ref.Kind+":"+ref.Value == c.Value {
+				found = true
+			}
+		}
+	case jsdumper.DetectorBuckets:
+		for _, bucket := range results.Buckets {
+			if bucket.Provider+":"+bucket.Name == c.Value {
+				found = true
+			}
+		}
+	}
+	return found == c.Match, nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	urlpkg "net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// Wayback Machine endpoints: the CDX index of captures, and the archive,
+// where an id_ timestamp serves a capture as it was received, without the
+// replay toolbar and rewritten links
+const (
+	waybackCDX     = "https://web.archive.org/cdx/search/cdx"
+	waybackArchive = "https://web.archive.org/web/"
+)
+
+// waybackCapture is an archived version of a script
+type waybackCapture struct {
+	Timestamp string // yyyyMMddhhmmss
+	Original  string // URL the script was captured from
+	MimeType  string
+	Digest    string // Hash of the content
+}
+
+// ArchiveURL is the URL of the capture as it was served
+func (w waybackCapture) ArchiveURL() string {
+	return waybackArchive + w.Timestamp + "id_/" + w.Original
+}
+
+// waybackDomain returns the domain to look up for a -wayback argument, a
+// domain or a URL on it
+func waybackDomain(input string) (string, error) {
+	domain := strings.TrimSpace(input)
+	if strings.Contains(domain, "://") {
+		u, err := urlpkg.Parse(domain)
+		if err != nil {
+			return "", fmt.Errorf("invalid -wayback URL: %w", err)
+		}
+		domain = u.Hostname()
+	}
+	domain = strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(domain, "*."), "/"))
+	if domain == "" || strings.ContainsAny(domain, "/?# ") {
+		return "", fmt.Errorf("invalid -wayback domain: %q", input)
+	}
+	return domain, nil
+}
+
+// waybackCDXURL is the CDX query for the successful captures of scripts on
+// domain and its subdomains, one per content digest, at most limit (0 = all).
+// The index is sorted by SURT URL key, then timestamp, so the limit keeps
+// the first URLs in that order rather than the oldest captures.
+func waybackCDXURL(domain string, limit int) string {
+	query := urlpkg.Values{
+		"url":       {domain},
+		"matchType": {"domain"},
+		"output":    {"json"},
+		"fl":        {"timestamp,original,mimetype,digest"},
+		"filter":    {"statuscode:200", `original:.*\.(js|mjs|cjs)(\?.*)?`},
+		"collapse":  {"digest"},
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	return waybackCDX + "?" + query.Encode()
+}
+
+// readWaybackCaptures parses a CDX JSON response, a header row followed by
+// one row per capture, keeping the scripts once per content digest
+func readWaybackCaptures(cdxPath string) ([]waybackCapture, error) {
+	data, err := os.ReadFile(cdxPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Wayback Machine index: %w", err)
+	}
+	var rows [][]string
+	if len(strings.TrimSpace(string(data))) > 0 {
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, fmt.Errorf("failed to parse Wayback Machine index: %w", err)
+		}
+	}
+
+	var captures []waybackCapture
+	seen := make(map[string]bool)
+	for i, row := range rows {
+		if i == 0 || len(row) < 4 {
+			continue // Header
+		}
+		capture := waybackCapture{Timestamp: row[0], Original: row[1], MimeType: row[2], Digest: row[3]}
+		if !isJavaScriptResponse(capture.Original, capture.MimeType) || seen[capture.Digest] {
+			continue
+		}
+		captures = append(captures, capture)
+		seen[capture.Digest] = true
+	}
+	return captures, nil
+}
+
+// ProcessWayback looks up the scripts the Wayback Machine archived for a
+// domain and extracts from every archived version. Old bundles often still
+// hold keys and endpoints since removed from the live site.
+func (c *CLI) ProcessWayback(input string) error {
+	domain, err := waybackDomain(input)
+	if err != nil {
+		return err
+	}
+	c.log(fmt.Sprintf("Querying the Wayback Machine for %s", domain), colorCyan)
+
+	tempDir, err := c.downloadDir()
+	if err != nil {
+		return err
+	}
+	cdxPath := filepath.Join(tempDir, "wayback_"+downloadFileName(domain, "cdx")+".json")
+	if err := c.downloader.Download(waybackCDXURL(domain, c.config.WaybackLimit), cdxPath); err != nil {
+		return fmt.Errorf("failed to query the Wayback Machine: %w", err)
+	}
+	captures, err := readWaybackCaptures(cdxPath)
+	if err != nil {
+		return err
+	}
+	if len(captures) == 0 {
+		c.log(fmt.Sprintf("No archived scripts found for %s", domain), colorYellow)
+		return nil
+	}
+	c.log(fmt.Sprintf("Downloading %d archived script version(s)...", len(captures)), colorCyan)
+
+	allResults := c.runPool(len(captures), func(i int) []*jsdumper.Results {
+		capture := captures[i]
+		// Versions of a script are told apart by their capture time
+		fileName := capture.Timestamp + "_" + downloadFileName(capture.Original, fmt.Sprintf("wayback_%d.js", i+1))
+		return c.scanRemote(capture.ArchiveURL(), fileName, filepath.Join(tempDir, fmt.Sprintf("%d_%s", i+1, fileName)))
+	})
+
+	c.log(fmt.Sprintf("Downloaded %d file(s)", len(allResults)), colorGreen)
+	return c.writeResults(allResults)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	urlpkg "net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/d0xng/jsdumper/pkg/jsdumper"
+)
+
+// chunkURL resolves a chunk file of the webpack runtime in scriptURL against
+// the runtime's public path. When the runtime computes the public path, the
+// chunk files are taken to share their directory with the script: chunk
+// static/js/42.3f2a1b9c.chunk.js of /static/js/main.js is
+// /static/js/42.3f2a1b9c.chunk.js. Wayback Machine captures resolve against
+// the URL they were captured from.
+func chunkURL(scriptURL, publicPath, file string) (string, error) {
+	archive, original, archived := strings.Cut(scriptURL, "id_/")
+	if !archived || !strings.HasPrefix(archive, waybackArchive) {
+		archive, original = "", scriptURL
+	}
+
+	base, err := urlpkg.Parse(original)
+	if err != nil {
+		return "", err
+	}
+	if publicPath != "" {
+		ref, err := urlpkg.Parse(publicPath)
+		if err != nil {
+			return "", err
+		}
+		base = base.ResolveReference(ref)
+	} else {
+		dir := path.Dir(base.Path) + "/"
+		if fileDir := path.Dir(file) + "/"; fileDir != "./" && strings.HasSuffix(dir, "/"+fileDir) {
+			dir = strings.TrimSuffix(dir, fileDir)
+		}
+		base = base.ResolveReference(&urlpkg.URL{Path: dir})
+	}
+	ref, err := urlpkg.Parse(file)
+	if err != nil {
+		return "", err
+	}
+	resolved := base.ResolveReference(ref).String()
+	if archive != "" {
+		resolved = archive + "id_/" + resolved
+	}
+	return resolved, nil
+}
+
+// claimChunk reports whether url is a chunk not downloaded yet in this run,
+// so chunks shared by several bundles are scanned once
+func (c *CLI) claimChunk(url string) bool {
+	c.chunksMu.Lock()
+	defer c.chunksMu.Unlock()
+	if c.chunks == nil {
+		c.chunks = make(map[string]bool)
+	}
+	if c.chunks[url] {
+		return false
+	}
+	c.chunks[url] = true
+	return true
+}
+
+// chunkResults downloads and extracts from the chunks a downloaded webpack
+// bundle loads on demand (-no-chunks turns it off). Only the entry bundle is
+// requested by the page; the chunks often hold the admin and settings code.
+func (c *CLI) chunkResults(scriptURL, content, localPath string) []*jsdumper.Results {
+	if c.config.NoChunks {
+		return nil
+	}
+	publicPath, files := jsdumper.WebpackChunkFiles(content)
+
+	var urls []string
+	for _, file := range files {
+		url, err := chunkURL(scriptURL, publicPath, file)
+		if err == nil && isURL(url) && c.claimChunk(url) {
+			urls = append(urls, url)
+		}
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+	c.log(fmt.Sprintf("Found %d webpack chunk(s) in %s", len(urls), scriptURL), colorCyan)
+
+	var results []*jsdumper.Results
+	for i, url := range urls {
+		fileName := downloadFileName(url, fmt.Sprintf("chunk_%d.js", i+1))
+		chunkPath := filepath.Join(filepath.Dir(localPath), fmt.Sprintf("%s_chunk%d_%s", filepath.Base(localPath), i+1, fileName))
+		results = append(results, c.scanRemote(url, fileName, chunkPath)...)
+	}
+	return results
+}